@@ -5,21 +5,36 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
+	"flag"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/drycc/workflow-cli/internal/commands"
+	"github.com/drycc/workflow-cli/internal/completion"
 	"github.com/drycc/workflow-cli/internal/parser"
 	"github.com/drycc/workflow-cli/internal/plugins"
 	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/drycc/workflow-cli/pkg/printer"
 	"github.com/drycc/workflow-cli/pkg/settings"
+	"github.com/drycc/workflow-cli/pkg/transport"
 	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
 )
 
 // NewDryccCommand creates the root command for the Drycc CLI.
 func NewDryccCommand() *cobra.Command {
 	var flags struct {
 		config  string
+		context string
+		output  string
+		timeout time.Duration
+		retries int
+		level   int
+		debug   bool
+		har     string
+		cached  bool
 		version bool
 		help    bool
 	}
@@ -29,8 +44,48 @@ func NewDryccCommand() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "drycc",
 		Short: i18n.T("The Drycc command-line client issues API calls to a Drycc controller"),
-		PersistentPreRun: func(_ *cobra.Command, _ []string) {
-			cmdr = commands.DryccCmd{ConfigFile: flags.config, WOut: os.Stdout, WErr: os.Stderr, WIn: os.Stdin, Location: time.Local}
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if flags.context != "" {
+				if err := os.Setenv(settings.ContextEnv, flags.context); err != nil {
+					return err
+				}
+			}
+			if flags.timeout < 0 || flags.retries < 0 {
+				return errors.New("--request-timeout and --retries must not be negative")
+			}
+			if cmd.Flags().Changed("request-timeout") {
+				if err := os.Setenv(settings.RequestTimeoutEnv, flags.timeout.String()); err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("retries") {
+				if err := os.Setenv(settings.RetriesEnv, strconv.Itoa(flags.retries)); err != nil {
+					return err
+				}
+			}
+			if flags.debug {
+				flags.level = max(flags.level, int(transport.TraceBodies))
+			}
+			if err := setVerbosity(flags.level); err != nil {
+				return err
+			}
+			if flags.cached {
+				if err := os.Setenv(settings.CachedEnv, "true"); err != nil {
+					return err
+				}
+			}
+			if flags.har != "" {
+				if err := os.Setenv(settings.HAREnv, flags.har); err != nil {
+					return err
+				}
+			}
+			if flags.output != "" {
+				if _, err := printer.New(flags.output); err != nil {
+					return err
+				}
+			}
+			cmdr = commands.DryccCmd{ConfigFile: flags.config, Output: flags.output, WOut: os.Stdout, WErr: os.Stderr, WIn: os.Stdin, Location: time.Local}
+			return nil
 		},
 	}
 	config := "~/.drycc/client.json"
@@ -38,8 +93,20 @@ func NewDryccCommand() *cobra.Command {
 		config = v
 	}
 	rootCmd.PersistentFlags().StringVarP(&flags.config, "config", "c", config, i18n.T("Path to configuration file"))
+	rootCmd.PersistentFlags().StringVar(&flags.context, "context", "", i18n.T("Name of the configuration context to use, overrides the current context"))
+	rootCmd.PersistentFlags().StringVarP(&flags.output, "output", "o", "", i18n.T("Output format. One of: json|yaml|jsonpath=...|go-template=...|go-template-file=..."))
+	rootCmd.PersistentFlags().DurationVar(&flags.timeout, "request-timeout", transport.DefaultTimeout, i18n.T("How long to wait for a response of the controller before retrying, 0 waiting forever"))
+	rootCmd.PersistentFlags().IntVar(&flags.retries, "retries", transport.DefaultRetries, i18n.T("The number of times the requests failing with transient errors are retried"))
+	rootCmd.PersistentFlags().BoolVar(&flags.cached, "cached", false, i18n.T("Cache the read-only requests but the config locally, and serve them from the cache while it is fresh or the controller can not be reached"))
+	// -v is the shorthand of --version in several commands
+	rootCmd.PersistentFlags().IntVar(&flags.level, "v", 0, i18n.T("Number for the log level verbosity, 6 traces the requests, 7 their headers and 8 their bodies"))
+	rootCmd.PersistentFlags().BoolVar(&flags.debug, "debug", false, i18n.T("Trace the requests with their headers and bodies, as --v=8"))
+	rootCmd.PersistentFlags().StringVar(&flags.har, "har", "", i18n.T("Record the requests to a HTTP Archive file, with their secrets redacted"))
 	rootCmd.PersistentFlags().BoolVarP(&flags.help, "help", "h", false, i18n.T("Display help information"))
+	rootCmd.RegisterFlagCompletionFunc("context", (&completion.ContextCompletion{ArgsLen: -1, ConfigFile: &flags.config}).CompletionFunc)
+	rootCmd.RegisterFlagCompletionFunc("output", (&completion.OutputFormatCompletion{}).CompletionFunc)
 
+	rootCmd.AddCommand(parser.NewApplyCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewAppsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewAuthCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewAutodeployCommand(&cmdr))
@@ -48,6 +115,7 @@ func NewDryccCommand() *cobra.Command {
 	rootCmd.AddCommand(parser.NewBuildsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewCertsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewConfigCommand(&cmdr))
+	rootCmd.AddCommand(parser.NewContextsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewDomainsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewGatewaysCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewGitCommand(&cmdr))
@@ -56,6 +124,7 @@ func NewDryccCommand() *cobra.Command {
 	rootCmd.AddCommand(parser.NewKeysCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewLabelsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewLimitsCommand(&cmdr))
+	rootCmd.AddCommand(parser.NewLogsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewWorkspacesCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewPsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewPtsCommand(&cmdr))
@@ -69,6 +138,7 @@ func NewDryccCommand() *cobra.Command {
 	rootCmd.AddCommand(parser.NewTimeoutsCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewTLSCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewTokensCommand(&cmdr))
+	rootCmd.AddCommand(parser.NewUICommand(&cmdr))
 	rootCmd.AddCommand(parser.NewUpdateCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewVolumesCommand(&cmdr))
 	rootCmd.AddCommand(parser.NewVersionCommand(&cmdr))
@@ -85,6 +155,16 @@ func NewDryccCommand() *cobra.Command {
 	return rootCmd
 }
 
+// setVerbosity sets the verbosity of klog, which traces the requests.
+func setVerbosity(level int) error {
+	if level < 0 {
+		return errors.New("--v must not be negative")
+	}
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	return fs.Set("v", strconv.Itoa(level))
+}
+
 // ExecuteWithPlugins runs the root command with plugin dispatch support
 func ExecuteWithPlugins(rootCmd *cobra.Command, config string) error {
 	// Try to find the command first
@@ -4,9 +4,12 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/drycc/workflow-cli/cmd"
+	"github.com/drycc/workflow-cli/internal/commands"
+	"github.com/drycc/workflow-cli/pkg/settings"
 )
 
 func main() {
@@ -18,7 +21,14 @@ func main() {
 		config = v
 	}
 
-	if err := cmd.ExecuteWithPlugins(rootCmd, config); err != nil {
+	err := cmd.ExecuteWithPlugins(rootCmd, config)
+	settings.FlushHAR()
+	if err != nil {
+		// exit with the code of the remote command, as ps exec does
+		var exitErr *commands.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,596 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/apps"
+	"github.com/drycc/controller-sdk-go/certs"
+	"github.com/drycc/controller-sdk-go/config"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/coder"
+	"github.com/drycc/workflow-cli/pkg/settings"
+)
+
+const (
+	planCreate = "create"
+	planUpdate = "update"
+	planDelete = "delete"
+)
+
+// singletonKinds are the kinds an app has exactly one of, their manifests
+// match whatever their name.
+var singletonKinds = []string{"App", "Config", "Limits", "Timeouts", "Tags", "Build"}
+
+// keyedKinds are the kinds whose spec is a map of process types, their keys
+// missing from the desired manifest are unset by prune.
+var keyedKinds = []string{"Limits", "Timeouts", "Tags"}
+
+// planChange is a change of a resource computed by apply.
+type planChange struct {
+	Action string        `json:"action"`
+	Kind   string        `json:"kind"`
+	Name   string        `json:"name"`
+	Fields []fieldChange `json:"fields,omitempty"`
+
+	manifest coder.Manifest
+}
+
+// fieldChange is a change of a single field of a resource, Old is nil for
+// added fields and New is nil for removed fields.
+type fieldChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// Apply converges an app to the manifests found in files, which may be
+// files, directories searched for *.yaml, *.yml and *.json files, or "-" for
+// stdin. Only resources that differ from the live state are applied, and
+// with prune resources missing from the manifests are destroyed. The config
+// values are hidden from the plan unless showValues is set.
+func (d *DryccCmd) Apply(appID string, files []string, dryRun, prune, showValues bool, confirm string) error {
+	manifests, err := readManifestFiles(files, d.WIn)
+	if err != nil {
+		return err
+	}
+	if err := sortManifests(manifests); err != nil {
+		return err
+	}
+	if appID == "" {
+		var names []string
+		for _, m := range manifests {
+			if m.Kind == "App" && !slices.Contains(names, m.Metadata.Name) {
+				names = append(names, m.Metadata.Name)
+			}
+		}
+		if len(names) > 1 {
+			return fmt.Errorf("manifests describe several apps (%s), apply them separately or use --app", strings.Join(names, ", "))
+		} else if len(names) == 1 {
+			appID = names[0]
+		}
+	}
+
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+	live, exists, err := d.liveManifests(s, appID)
+	if err != nil {
+		return err
+	}
+	changes, err := planManifests(manifests, live, prune)
+	if err != nil {
+		return err
+	}
+
+	d.printPlan(appID, changes, showValues)
+	if dryRun || len(changes) == 0 {
+		return nil
+	}
+
+	if slices.ContainsFunc(changes, func(c planChange) bool { return c.Action == planDelete }) {
+		if confirm == "" {
+			d.Printf(` !    WARNING: Potentially Destructive Action
+ !    This command will destroy the resources of %s listed above
+ !    To proceed, type "%s" or re-run this command with --confirm=%s
+
+> `, appID, appID, appID)
+
+			fmt.Scanln(&confirm)
+		}
+		if confirm != appID {
+			return fmt.Errorf("app %s does not match confirm %s, aborting", appID, confirm)
+		}
+	}
+
+	d.Println()
+	return d.applyPlan(s, appID, changes, exists)
+}
+
+// applyPlan applies the changes of a plan to an app, which is created first
+// unless it exists.
+func (d *DryccCmd) applyPlan(s *settings.Settings, appID string, changes []planChange, exists bool) error {
+	if !exists {
+		if err := d.ensureApp(s, appID); err != nil {
+			return err
+		}
+	}
+	for _, change := range changes {
+		var err error
+		if change.Action == planDelete {
+			err = d.deleteManifest(s, appID, change.manifest)
+		} else {
+			err = d.applyManifest(s, appID, change.manifest)
+			if err == nil {
+				err = d.pruneConfig(s, appID, change)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readManifestFiles decodes the manifests of files, directories are walked
+// for *.yaml, *.yml and *.json files in lexical order.
+func readManifestFiles(files []string, stdin io.Reader) ([]coder.Manifest, error) {
+	var manifests []coder.Manifest
+	read := func(name string, data []byte) error {
+		decoded, err := coder.DecodeManifests(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		manifests = append(manifests, decoded...)
+		return nil
+	}
+
+	for _, file := range files {
+		if file == "-" {
+			data, err := io.ReadAll(stdin)
+			if err != nil {
+				return nil, err
+			}
+			if err := read("stdin", data); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err := filepath.WalkDir(file, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if path != file && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if path != file && !slices.Contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(path)) {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return read(path, data)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no manifests found in %s", strings.Join(files, ", "))
+	}
+	return manifests, nil
+}
+
+// liveManifests returns the manifests of the live resources of an app, and
+// whether the app exists.
+func (d *DryccCmd) liveManifests(s *settings.Settings, appID string) ([]coder.Manifest, bool, error) {
+	_, err := apps.Get(s.Client, appID)
+	if err = d.checkAPICompatibility(s.Client, err); isNotFound(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	manifests, err := d.exportManifests(s, appID)
+	if err != nil {
+		return nil, true, err
+	}
+	appCerts, _, err := certs.List(s.Client, appID, defaultLimit)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, true, err
+	}
+	for _, cert := range appCerts {
+		manifests = append(manifests, (&coder.CertCoder{Info: cert}).Manifest())
+	}
+	return manifests, true, nil
+}
+
+// manifestKey identifies the resource described by a manifest.
+func manifestKey(m coder.Manifest) string {
+	kind := manifestKind(m)
+	if slices.Contains(singletonKinds, kind) {
+		return kind
+	}
+	return kind + "/" + m.Metadata.Name
+}
+
+// planManifests computes the changes turning the live manifests into the
+// desired ones. Fields missing from a desired manifest are left as they are,
+// with prune live resources, config values and keys of keyedKinds missing
+// from the desired manifests are destroyed.
+func planManifests(desired, live []coder.Manifest, prune bool) ([]planChange, error) {
+	liveByKey := make(map[string]coder.Manifest, len(live))
+	for _, m := range live {
+		liveByKey[manifestKey(m)] = m
+	}
+
+	var changes []planChange
+	seen := make(map[string]bool, len(desired))
+	for _, m := range desired {
+		key := manifestKey(m)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate manifest for %s %s", m.Kind, m.Metadata.Name)
+		}
+		seen[key] = true
+
+		spec, err := comparableSpec(m)
+		if err != nil {
+			return nil, err
+		}
+		change := planChange{Kind: m.Kind, Name: m.Metadata.Name, manifest: m}
+		if current, ok := liveByKey[key]; ok {
+			currentSpec, err := comparableSpec(current)
+			if err != nil {
+				return nil, err
+			}
+			change.Action = planUpdate
+			change.Fields = diffSpec("", spec, currentSpec, prune)
+			if prune && slices.Contains(keyedKinds, manifestKind(m)) {
+				change.Fields = append(change.Fields, removedKeys("", spec, currentSpec)...)
+			}
+		} else {
+			change.Action = planCreate
+			change.Fields = diffSpec("", spec, map[string]any{}, false)
+		}
+		if change.Action == planCreate || len(change.Fields) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	if prune {
+		// destroy in reverse order, routes before the services they refer to
+		for _, m := range slices.Backward(live) {
+			if seen[manifestKey(m)] || slices.Contains(singletonKinds, manifestKind(m)) {
+				continue
+			}
+			changes = append(changes, planChange{Action: planDelete, Kind: m.Kind, Name: m.Metadata.Name, manifest: m})
+		}
+	}
+	return changes, nil
+}
+
+// comparableSpec returns the spec of a manifest as plain values. The
+// certificate and key of a cert are replaced by the fingerprint of the
+// certificate, the only thing the controller tells about them.
+func comparableSpec(m coder.Manifest) (map[string]any, error) {
+	spec := make(map[string]any)
+	data, err := json.Marshal(m.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		spec = make(map[string]any)
+	}
+
+	if manifestKind(m) == "Cert" {
+		fingerprint, _ := m.Status["fingerprint"].(string)
+		if _, ok := spec["certificate"]; ok {
+			data, err := m.JSON()
+			if err != nil {
+				return nil, err
+			}
+			c := &coder.CertCoder{}
+			if err := c.Decode(data); err != nil {
+				return nil, err
+			}
+			if fingerprint, err = c.Fingerprint(); err != nil {
+				return nil, err
+			}
+		}
+		delete(spec, "certificate")
+		delete(spec, "key")
+		spec["fingerprint"] = normalizeFingerprint(fingerprint)
+	}
+	return spec, nil
+}
+
+// diffSpec compares the desired value of a field with its live value. Maps
+// are compared key by key and only for the keys of the desired map, lists of
+// named objects such as config values are compared by name and other lists
+// as sets. Scalars are compared by their string form, so 80 matches "80".
+func diffSpec(path string, desired, live any, prune bool) []fieldChange {
+	if live == nil {
+		if desired == nil {
+			return nil
+		}
+		return []fieldChange{{Path: path, New: desired}}
+	}
+
+	var changes []fieldChange
+	switch want := desired.(type) {
+	case map[string]any:
+		have, ok := live.(map[string]any)
+		if !ok {
+			return []fieldChange{{Path: path, Old: live, New: desired}}
+		}
+		for _, key := range slices.Sorted(maps.Keys(want)) {
+			changes = append(changes, diffSpec(joinPath(path, key), want[key], have[key], prune)...)
+		}
+	case []any:
+		have, ok := live.([]any)
+		if !ok {
+			return []fieldChange{{Path: path, Old: live, New: desired}}
+		}
+		wantByName, named := namedItems(want)
+		haveByName, haveNamed := namedItems(have)
+		if named && haveNamed {
+			for _, name := range slices.Sorted(maps.Keys(wantByName)) {
+				changes = append(changes, diffSpec(fmt.Sprintf("%s[%s]", path, name), wantByName[name], haveByName[name], prune)...)
+			}
+			if prune {
+				for _, name := range slices.Sorted(maps.Keys(haveByName)) {
+					if _, ok := wantByName[name]; !ok {
+						changes = append(changes, fieldChange{Path: fmt.Sprintf("%s[%s]", path, name), Old: haveByName[name]})
+					}
+				}
+			}
+		} else if !sameItems(want, have) {
+			changes = append(changes, fieldChange{Path: path, Old: live, New: desired})
+		}
+	default:
+		if desired != nil && fmt.Sprint(desired) != fmt.Sprint(live) {
+			changes = append(changes, fieldChange{Path: path, Old: live, New: desired})
+		}
+	}
+	return changes
+}
+
+// removedKeys returns the removal of the keys of the live map missing from
+// the desired one, nested maps included.
+func removedKeys(path string, desired, live map[string]any) []fieldChange {
+	var changes []fieldChange
+	for _, key := range slices.Sorted(maps.Keys(live)) {
+		want, ok := desired[key]
+		if !ok {
+			changes = append(changes, fieldChange{Path: joinPath(path, key), Old: live[key]})
+			continue
+		}
+		wantMap, ok := want.(map[string]any)
+		haveMap, haveOk := live[key].(map[string]any)
+		if ok && haveOk {
+			changes = append(changes, removedKeys(joinPath(path, key), wantMap, haveMap)...)
+		}
+	}
+	return changes
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// namedItems indexes a list of objects with a name, such as config values,
+// by ptype or group and name. It reports false for other lists.
+func namedItems(items []any) (map[string]any, bool) {
+	named := make(map[string]any, len(items))
+	for _, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, _ := fields["name"].(string)
+		if name == "" {
+			return nil, false
+		}
+		for _, scope := range []string{"group", "ptype"} {
+			if value, _ := fields[scope].(string); value != "" {
+				name = value + "/" + name
+			}
+		}
+		named[name] = item
+	}
+	return named, len(named) > 0
+}
+
+// sameItems reports whether each desired item matches a live item.
+func sameItems(desired, live []any) bool {
+	if len(desired) != len(live) {
+		return false
+	}
+	for _, want := range desired {
+		if !slices.ContainsFunc(live, func(have any) bool { return len(diffSpec("", want, have, false)) == 0 }) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToUpper(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// pruneConfig unsets the config values and the keys of keyedKinds a change
+// removes.
+func (d *DryccCmd) pruneConfig(s *settings.Settings, appID string, change planChange) error {
+	cfg, count := api.Config{}, 0
+	for _, field := range change.Fields {
+		if field.New != nil || field.Old == nil {
+			continue
+		}
+		switch change.Kind {
+		case "Config":
+			item, ok := field.Old.(map[string]any)
+			if !ok || !strings.HasPrefix(field.Path, "values[") {
+				continue
+			}
+			value := api.ConfigValue{}
+			value.Name, _ = item["name"].(string)
+			value.Ptype, _ = item["ptype"].(string)
+			value.Group, _ = item["group"].(string)
+			cfg.Values = append(cfg.Values, value)
+		case "Limits":
+			if cfg.Limits == nil {
+				cfg.Limits = make(map[string]any)
+			}
+			cfg.Limits[field.Path] = nil
+		case "Timeouts":
+			if cfg.Timeout == nil {
+				cfg.Timeout = make(map[string]any)
+			}
+			cfg.Timeout[field.Path] = nil
+		case "Tags":
+			if cfg.Tags == nil {
+				cfg.Tags = make(map[string]api.ConfigTags)
+			}
+			ptype, tag, found := strings.Cut(field.Path, ".")
+			if cfg.Tags[ptype] == nil {
+				cfg.Tags[ptype] = make(api.ConfigTags)
+			}
+			if found {
+				cfg.Tags[ptype][tag] = nil
+			} else if tags, ok := field.Old.(map[string]any); ok {
+				for tag := range tags {
+					cfg.Tags[ptype][tag] = nil
+				}
+			}
+		default:
+			continue
+		}
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+
+	if change.Kind == "Config" {
+		d.Printf("Removing %d config values... ", count)
+	} else {
+		d.Printf("Removing %d %s keys... ", count, strings.ToLower(change.Kind))
+	}
+	quit := progress(d.WOut)
+	_, err := config.Set(s.Client, appID, cfg, true)
+	quit <- true
+	<-quit
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+	d.Println("done")
+	return nil
+}
+
+// printPlan prints the changes in the style of a terraform plan, with the
+// config values hidden unless showValues is set.
+func (d *DryccCmd) printPlan(appID string, changes []planChange, showValues bool) {
+	if len(changes) == 0 {
+		d.Printf("No changes, %s matches the manifests.\n", appID)
+		return
+	}
+
+	counts := make(map[string]int)
+	d.Printf("Drycc will perform the following actions on %s:\n\n", appID)
+	for _, change := range changes {
+		counts[change.Action]++
+		d.Printf("  %s %s %s\n", planSymbol(change.Action), change.Kind, change.Name)
+		for _, field := range change.Fields {
+			if change.Kind == "Config" && !showValues {
+				field.Old, field.New = hideConfigValues(field.Path, field.Old), hideConfigValues(field.Path, field.New)
+			}
+			switch {
+			case field.Old == nil:
+				d.Printf("      + %s = %s\n", field.Path, formatPlanValue(field.New))
+			case field.New == nil:
+				d.Printf("      - %s = %s\n", field.Path, formatPlanValue(field.Old))
+			default:
+				d.Printf("      ~ %s = %s -> %s\n", field.Path, formatPlanValue(field.Old), formatPlanValue(field.New))
+			}
+		}
+		d.Println()
+	}
+	d.Printf("Plan: %d to add, %d to change, %d to destroy.\n",
+		counts[planCreate], counts[planUpdate], counts[planDelete])
+}
+
+func planSymbol(action string) string {
+	switch action {
+	case planCreate:
+		return "+"
+	case planDelete:
+		return "-"
+	}
+	return "~"
+}
+
+// sensitive replaces a config value hidden from the plan.
+type sensitive struct{}
+
+func (sensitive) MarshalJSON() ([]byte, error) {
+	return []byte(`"(sensitive)"`), nil
+}
+
+// hideConfigValues replaces the config values of a field of a Config, a
+// value itself or the values of the items of the values list.
+func hideConfigValues(path string, v any) any {
+	if v == nil {
+		return nil
+	}
+	if strings.HasSuffix(path, ".value") {
+		return sensitive{}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		if _, ok := v["value"]; ok {
+			v = maps.Clone(v)
+			v["value"] = sensitive{}
+		}
+		return v
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = hideConfigValues("", item)
+		}
+		return items
+	}
+	return v
+}
+
+func formatPlanValue(v any) string {
+	if _, ok := v.(sensitive); ok {
+		return "(sensitive)"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,279 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/drycc/workflow-cli/pkg/coder"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanManifests(t *testing.T) {
+	t.Parallel()
+
+	live, err := coder.DecodeManifests([]byte(`kind: Config
+metadata:
+  name: foo
+spec:
+  values:
+    - {name: FOO, value: bar, group: global}
+    - {name: OLD, value: "1", group: global}
+    - {name: PORT, value: "8000", ptype: web}
+---
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+    - {name: "", port: 80, protocol: TCP, targetPort: 8000}
+---
+kind: Domain
+metadata:
+  name: old.example.com
+spec:
+  ptype: web
+`))
+	assert.NoError(t, err)
+	desired, err := coder.DecodeManifests([]byte(`kind: Config
+metadata:
+  name: bar
+spec:
+  values:
+    - {name: FOO, value: baz, group: global}
+    - {name: PORT, value: 8000, ptype: web}
+---
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+    - {port: 80, protocol: TCP, targetPort: 8000}
+---
+kind: Domain
+metadata:
+  name: example.com
+spec:
+  ptype: web
+`))
+	assert.NoError(t, err)
+
+	changes, err := planManifests(desired, live, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []planChange{
+		{Action: planUpdate, Kind: "Config", Name: "bar", manifest: desired[0], Fields: []fieldChange{
+			{Path: "values[global/FOO].value", Old: "bar", New: "baz"},
+		}},
+		{Action: planCreate, Kind: "Domain", Name: "example.com", manifest: desired[2], Fields: []fieldChange{
+			{Path: "ptype", New: "web"},
+		}},
+	}, changes)
+
+	changes, err = planManifests(desired, live, true)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, fieldChange{
+		Path: "values[global/OLD]",
+		Old:  map[string]any{"name": "OLD", "value": "1", "group": "global"},
+	}, changes[0].Fields[1])
+	assert.Equal(t, planDelete, changes[2].Action)
+	assert.Equal(t, "old.example.com", changes[2].Name)
+
+	_, err = planManifests(append(desired, desired[2]), live, false)
+	assert.Error(t, err)
+}
+
+func TestHideConfigValues(t *testing.T) {
+	t.Parallel()
+
+	item := map[string]any{"name": "OLD", "value": "1", "group": "global"}
+	assert.Equal(t, formatPlanValue(hideConfigValues("values[global/OLD]", item)),
+		`{"group":"global","name":"OLD","value":"(sensitive)"}`, "item")
+	assert.Equal(t, item["value"], "1", "the item is left unchanged")
+	assert.Equal(t, formatPlanValue(hideConfigValues("values", []any{item})),
+		`[{"group":"global","name":"OLD","value":"(sensitive)"}]`, "list")
+	assert.Equal(t, formatPlanValue(hideConfigValues("values[global/OLD].value", "1")), "(sensitive)", "value")
+	assert.Equal(t, formatPlanValue(hideConfigValues("values[web/PORT].ptype", "web")), `"web"`, "ptype")
+	assert.Nil(t, hideConfigValues("values[global/OLD].value", nil))
+}
+
+func TestReadManifestFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "network"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	files := map[string]string{
+		"app.yaml":             "kind: App\nmetadata:\n  name: foo\n",
+		"network/domains.yml":  "kind: Domain\nmetadata:\n  name: a.com\n---\nkind: Domain\nmetadata:\n  name: b.com\n",
+		"network/service.json": `{"kind": "Service", "metadata": {"name": "web"}}`,
+		"README.md":            "# not a manifest",
+		".git/config.yaml":     "not: a manifest",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	manifests, err := readManifestFiles([]string{dir}, nil)
+	assert.NoError(t, err)
+	var names []string
+	for _, m := range manifests {
+		names = append(names, m.Kind+"/"+m.Metadata.Name)
+	}
+	assert.Equal(t, []string{"App/foo", "Domain/a.com", "Domain/b.com", "Service/web"}, names)
+
+	manifests, err = readManifestFiles([]string{"-"}, strings.NewReader("kind: Tags\nmetadata:\n  name: foo\n"))
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 1)
+
+	_, err = readManifestFiles([]string{filepath.Join(dir, "README.md")}, nil)
+	assert.Error(t, err)
+}
+
+// handleLiveApp serves the resources of app foo and records the requests
+// changing them.
+func handleLiveApp(server *testutil.TestServer) func() []string {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	record := func(r *http.Request) {
+		if r.Method != "GET" {
+			mu.Lock()
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+		}
+	}
+	handle := func(path, body string) {
+		server.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			testutil.SetHeaders(w)
+			record(r)
+			if r.Method == "DELETE" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			if r.Method != "GET" {
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{}`)
+				return
+			}
+			fmt.Fprint(w, body)
+		})
+	}
+	handle("/v2/apps/foo/", `{"id": "foo", "workspace": "test-workspace"}`)
+	handle("/v2/apps/foo/settings/", `{"app": "foo", "routable": true}`)
+	handle("/v2/apps/foo/config/", `{"app": "foo", "values": [{"name": "FOO", "value": "bar", "group": "global"}]}`)
+	handle("/v2/apps/foo/volumes/", `{"count": 0, "results": []}`)
+	handle("/v2/apps/foo/services/", `{"services": []}`)
+	handle("/v2/apps/foo/domains/", `{"count": 1, "results": [{"app": "foo", "domain": "old.example.com", "ptype": "web"}]}`)
+	handle("/v2/apps/foo/domains/old.example.com", ``)
+	handle("/v2/apps/foo/gateways/", `{"count": 0, "results": []}`)
+	handle("/v2/apps/foo/routes/", `{"count": 0, "results": []}`)
+	handle("/v2/apps/foo/certs/", `{"count": 0, "results": []}`)
+	server.Mux.HandleFunc("/v2/apps/foo/build/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"detail": "Not found."}`)
+	})
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+const applyManifests = `kind: App
+metadata:
+  name: foo
+spec:
+  routable: true
+---
+kind: Config
+metadata:
+  name: foo
+spec:
+  values:
+    - {name: FOO, value: baz, group: global}
+---
+kind: Domain
+metadata:
+  name: example.com
+spec:
+  ptype: web
+`
+
+func TestApplyDryRun(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	requests := handleLiveApp(server)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WIn: strings.NewReader(applyManifests), ConfigFile: cf}
+
+	err = cmdr.Apply("", []string{"-"}, true, true, false, "")
+	assert.NoError(t, err)
+	assert.Equal(t, `Drycc will perform the following actions on foo:
+
+  ~ Config foo
+      ~ values[global/FOO].value = (sensitive) -> (sensitive)
+
+  + Domain example.com
+      + ptype = "web"
+
+  - Domain old.example.com
+
+Plan: 1 to add, 1 to change, 1 to destroy.
+`, b.String())
+	assert.Empty(t, requests())
+
+	b.Reset()
+	cmdr.WIn = strings.NewReader(applyManifests)
+	err = cmdr.Apply("", []string{"-"}, true, false, true, "")
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), `      ~ values[global/FOO].value = "bar" -> "baz"
+`)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	requests := handleLiveApp(server)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WIn: strings.NewReader(applyManifests), ConfigFile: cf}
+
+	err = cmdr.Apply("", []string{"-"}, false, false, false, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"POST /v2/apps/foo/config/",
+		"POST /v2/apps/foo/domains/",
+	}, requests())
+	assert.Contains(t, testutil.StripProgress(b.String()), `Plan: 1 to add, 1 to change, 0 to destroy.
+
+Applying Config foo... done
+Applying Domain example.com... done
+`)
+
+	cmdr.WIn = strings.NewReader(applyManifests)
+	err = cmdr.Apply("", []string{"-"}, false, true, false, "bar")
+	assert.Error(t, err)
+
+	cmdr.WIn = strings.NewReader(applyManifests)
+	err = cmdr.Apply("", []string{"-"}, false, true, false, "foo")
+	assert.NoError(t, err)
+	assert.Contains(t, requests(), "DELETE /v2/apps/foo/domains/old.example.com")
+}
@@ -3,7 +3,9 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/drycc/controller-sdk-go/domains"
 	"github.com/drycc/controller-sdk-go/ps"
 	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/coder"
 	"github.com/drycc/workflow-cli/pkg/git"
 	"github.com/drycc/workflow-cli/pkg/settings"
 	"github.com/drycc/workflow-cli/pkg/webbrowser"
@@ -73,6 +76,11 @@ func (d *DryccCmd) AppsList(results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(apps)
+	}
+
 	if count > 0 {
 		table := d.getDefaultFormatTable([]string{"ID", "WORKSPACE", "CREATED", "UPDATED"})
 		for _, app := range apps {
@@ -90,6 +98,15 @@ func (d *DryccCmd) AppsList(results int) error {
 	return nil
 }
 
+// appInfo is the machine-readable form of the apps:info output.
+type appInfo struct {
+	api.App
+	URL       string       `json:"url"`
+	Processes api.PodsList `json:"processes"`
+	Domains   api.Domains  `json:"domains"`
+	Labels    api.Labels   `json:"labels"`
+}
+
 // AppInfo prints info about app.
 func (d *DryccCmd) AppInfo(appID string) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
@@ -107,6 +124,31 @@ func (d *DryccCmd) AppInfo(appID string) error {
 		return err
 	}
 
+	processes, _, err := ps.List(s.Client, appID, defaultLimit)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+
+	domains, _, err := domains.List(s.Client, appID, defaultLimit)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+
+	appSettings, err := appsettings.List(s.Client, appID)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+
+	if d.Output != "" {
+		return d.printObject(appInfo{
+			App:       app,
+			URL:       url,
+			Processes: processes,
+			Domains:   domains,
+			Labels:    appSettings.Label,
+		})
+	}
+
 	table := d.getDefaultFormatTable([]string{})
 	table.Append([]string{"App:", app.ID})
 	table.Append([]string{"URL:", url})
@@ -119,11 +161,6 @@ func (d *DryccCmd) AppInfo(appID string) error {
 	table.Append([]string{"Updated:", d.formatTime(app.Updated)})
 
 	// print the app processes
-	processes, _, err := ps.List(s.Client, appID, defaultLimit)
-	if d.checkAPICompatibility(s.Client, err) != nil {
-		return err
-	}
-
 	if len(processes) > 0 {
 		table.Append([]string{"Processes:"})
 		for index, process := range processes {
@@ -140,10 +177,6 @@ func (d *DryccCmd) AppInfo(appID string) error {
 		table.Append([]string{"Processes:", safeGetString("")})
 	}
 
-	domains, _, err := domains.List(s.Client, appID, defaultLimit)
-	if d.checkAPICompatibility(s.Client, err) != nil {
-		return err
-	}
 	if len(domains) > 0 {
 		table.Append([]string{"Domains:"})
 		for index, domain := range domains {
@@ -158,10 +191,6 @@ func (d *DryccCmd) AppInfo(appID string) error {
 		table.Append([]string{"Domains:", safeGetString("")})
 	}
 
-	appSettings, err := appsettings.List(s.Client, appID)
-	if d.checkAPICompatibility(s.Client, err) != nil {
-		return err
-	}
 	if len(appSettings.Label) > 0 {
 		table.Append([]string{"Labels:"})
 		for index, label := range *sortKeys(appSettings.Label) {
@@ -201,8 +230,10 @@ func (d *DryccCmd) AppOpen(appID string) error {
 	return webbrowser.Webbrowser(u)
 }
 
-// AppRun runs a one time command in the app.
-func (d *DryccCmd) AppRun(appID, command string, volumeVars []string, timeout, expires uint32) error {
+// AppRun runs a one time command in the app, mirroring its output until it
+// ends and returning an ExitError when it fails. With detach, it returns once the
+// command started, see RunList and RunKill.
+func (d *DryccCmd) AppRun(appID, command string, volumeVars []string, timeout, expires uint32, detach bool) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -214,10 +245,22 @@ func (d *DryccCmd) AppRun(appID, command string, volumeVars []string, timeout, e
 		return err
 	}
 
+	known, err := runPods(s.Client, appID)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
 	if err := apps.Run(s.Client, appID, command, volumeMap, timeout, expires); d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
-	return nil
+	podID, err := waitRunPod(s.Client, appID, command, known)
+	if err != nil {
+		return err
+	}
+	if detach {
+		d.Printf("Started %s, see its logs with drycc run logs %s -a %s\n", podID, podID, appID)
+		return nil
+	}
+	return d.attachRun(s.Client, appID, podID, s.Retries)
 }
 
 func parseMount(volumeVars []string) (map[string]any, error) {
@@ -302,6 +345,78 @@ func (d *DryccCmd) AppTransfer(appID, workspace string) error {
 	return nil
 }
 
+// AppExport writes the manifests of an app to a file, or to stdout when
+// filePath is empty or "-".
+func (d *DryccCmd) AppExport(appID, filePath string) error {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := d.exportManifests(s, appID)
+	if err != nil {
+		return err
+	}
+	data, err := coder.EncodeManifests(manifests)
+	if err != nil {
+		return err
+	}
+
+	if filePath == "" || filePath == "-" {
+		d.Print(string(data))
+		return nil
+	}
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return err
+	}
+	d.Printf("Exported %d resources of %s to %s\n", len(manifests), appID, filePath)
+	return nil
+}
+
+// AppImport creates or updates an app from the manifests written by
+// AppExport. The app is created when it does not exist, and only the
+// resources differing from the manifests are applied, as by Apply with
+// prune: config values and keys missing from the manifests are unset, but
+// resources not in the manifests are left untouched.
+func (d *DryccCmd) AppImport(appID, filePath string) error {
+	if filePath == "" {
+		filePath = "-"
+	}
+	manifests, err := readManifestFiles([]string{filePath}, d.WIn)
+	if err != nil {
+		return err
+	}
+	if err := sortManifests(manifests); err != nil {
+		return err
+	}
+	if appID == "" {
+		for _, m := range manifests {
+			if m.Kind == "App" {
+				appID = m.Metadata.Name
+			}
+		}
+	}
+
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+	live, exists, err := d.liveManifests(s, appID)
+	if err != nil {
+		return err
+	}
+	changes, err := planManifests(manifests, live, true)
+	if err != nil {
+		return err
+	}
+	changes = slices.DeleteFunc(changes, func(c planChange) bool { return c.Action == planDelete })
+	if len(changes) == 0 {
+		d.Printf("No changes, %s matches the manifests.\n", appID)
+		return nil
+	}
+	return d.applyPlan(s, appID, changes, exists)
+}
+
 const noDomainAssignedMsg = "no domain assigned to %s"
 
 // appURL grabs the first domain an app has and returns this.
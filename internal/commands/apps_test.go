@@ -2,11 +2,15 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/drycc/controller-sdk-go/api"
 	"github.com/drycc/workflow-cli/pkg/git"
 	"github.com/drycc/workflow-cli/pkg/settings"
 	"github.com/drycc/workflow-cli/pkg/testutil"
@@ -68,6 +72,68 @@ consectetur    adipiscing        2016-08-22T17:40:16Z    2016-08-22T17:40:16Z
 `)
 }
 
+func TestAppsListOutput(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	s, _ := settings.Load(cf)
+	s.Workspace = "dolar-sit-amet"
+	cf, _ = s.Save(cf)
+
+	server.Mux.HandleFunc("/v2/apps/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"count": 2,
+			"next": null,
+			"previous": null,
+			"results": [
+				{
+					"uuid": "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3",
+					"id": "lorem-ipsum",
+					"workspace": "dolar-sit-amet",
+					"created": "2016-08-22T17:40:16Z",
+					"updated": "2016-08-22T17:40:16Z"
+				},
+				{
+					"uuid": "c4aed81c-d1ca-4ff1-ab89-d2151264e1a3",
+					"id": "consectetur",
+					"workspace": "adipiscing",
+					"created": "2016-08-22T17:40:16Z",
+					"updated": "2016-08-22T17:40:16Z"
+				}
+			]
+		}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf, Output: "jsonpath={range .[*]}{.id}{\"\\n\"}{end}"}
+	err = cmdr.AppsList(-1)
+	assert.NoError(t, err)
+	assert.Equal(t, "lorem-ipsum\nconsectetur\n", b.String())
+
+	b.Reset()
+	cmdr.Output = "yaml"
+	err = cmdr.AppsList(-1)
+	assert.NoError(t, err)
+	assert.Equal(t, `- created: "2016-08-22T17:40:16Z"
+  id: lorem-ipsum
+  uid: 0
+  updated: "2016-08-22T17:40:16Z"
+  uuid: c4aed81c-d1ca-4ff1-ab89-d2151264e1a3
+  workspace: dolar-sit-amet
+- created: "2016-08-22T17:40:16Z"
+  id: consectetur
+  uid: 0
+  updated: "2016-08-22T17:40:16Z"
+  uuid: c4aed81c-d1ca-4ff1-ab89-d2151264e1a3
+  workspace: adipiscing
+`, b.String())
+}
+
 func TestAppsListNoWorkspace(t *testing.T) {
 	t.Parallel()
 	cf, _, err := testutil.NewTestServerAndClient()
@@ -346,3 +412,342 @@ func TestRemoteExists(t *testing.T) {
 	assert.Contains(t, err.Error(), "drycc",
 		"error message should contain the remote name 'drycc', got: %s", err.Error())
 }
+
+func TestAppExport(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/settings/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "foo", "owner": "jkirk", "label": {"team": "core"}, "routable": true,
+			"autoscale": {"web": {"min": 1, "max": 3, "cpu_percent": 50}}}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "foo", "owner": "jkirk",
+			"values": [{"name": "FOO", "value": "bar", "group": "global"}],
+			"limits": {"web": "std1.large.c1m1"}}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/volumes/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 1, "results": [{"name": "myvolume", "size": "2G", "type": "csi", "path": {"web": "/data"}}]}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/build/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"detail": "Not found."}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/services/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"services": [{"ptype": "web", "ports": [{"port": 80, "protocol": "TCP", "targetPort": 8000}]}]}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/domains/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 1, "results": [{"app": "foo", "domain": "example.com", "ptype": "web"}]}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/gateways/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 0, "results": []}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/routes/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 0, "results": []}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.AppExport("foo", "")
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: controller.drycc.cc/v2
+kind: App
+metadata:
+  name: foo
+spec:
+  label:
+    team: core
+  routable: true
+---
+apiVersion: controller.drycc.cc/v2
+kind: Autoscale
+metadata:
+  name: web
+spec:
+  cpu_percent: 50
+  max: 3
+  min: 1
+---
+apiVersion: controller.drycc.cc/v2
+kind: Config
+metadata:
+  name: foo
+spec:
+  values:
+    - group: global
+      name: FOO
+      value: bar
+---
+apiVersion: controller.drycc.cc/v2
+kind: Limits
+metadata:
+  name: foo
+spec:
+  web: std1.large.c1m1
+---
+apiVersion: controller.drycc.cc/v2
+kind: Volume
+metadata:
+  name: myvolume
+spec:
+  path:
+    web: /data
+  size: 2G
+  type: csi
+---
+apiVersion: controller.drycc.cc/v2
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+    - name: ""
+      port: 80
+      protocol: TCP
+      targetPort: 8000
+---
+apiVersion: controller.drycc.cc/v2
+kind: Domain
+metadata:
+  name: example.com
+spec:
+  ptype: web
+`, b.String())
+}
+
+func TestAppImport(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	s, _ := settings.Load(cf)
+	s.Workspace = "lorem"
+	cf, _ = s.Save(cf)
+
+	var requests []string
+	server.Mux.HandleFunc("/v2/apps/bar/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"detail": "Not found."}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		testutil.AssertBody(t, map[string]string{"id": "bar", "workspace": "lorem"}, r)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": "bar", "workspace": "lorem"}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/settings/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"app": "bar"}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("merge"))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"app": "bar"}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/bar/domains/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" {
+			testutil.AssertBody(t, map[string]string{"domain": "example.com", "ptype": "web"}, r)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{}`)
+			return
+		}
+		fmt.Fprintf(w, `{"count": 0, "results": []}`)
+	})
+
+	// documents are applied by kind whatever their order in the file
+	manifests := `apiVersion: controller.drycc.cc/v2
+kind: Domain
+metadata:
+  name: example.com
+spec:
+  ptype: web
+---
+apiVersion: controller.drycc.cc/v2
+kind: App
+metadata:
+  name: foo
+spec:
+  routable: true
+---
+apiVersion: controller.drycc.cc/v2
+kind: Limits
+metadata:
+  name: foo
+spec:
+  web: std1.large.c1m1
+`
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WIn: strings.NewReader(manifests), ConfigFile: cf}
+
+	err = cmdr.AppImport("bar", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"POST /v2/apps/",
+		"POST /v2/apps/bar/settings/",
+		"POST /v2/apps/bar/config/",
+		"GET /v2/apps/bar/domains/",
+		"POST /v2/apps/bar/domains/",
+	}, requests)
+	assert.Equal(t, `Creating app bar... done
+Applying App foo... done
+Applying Limits foo... done
+Applying Domain example.com... done
+`, testutil.StripProgress(b.String()))
+
+	cmdr.WIn = strings.NewReader("kind: Unknown\nmetadata:\n  name: foo\n")
+	err = cmdr.AppImport("bar", "-")
+	assert.Error(t, err)
+}
+
+func TestAppImportConverges(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// the live state of foo, changed by the requests of the import
+	cfg := api.Config{
+		Values: []api.ConfigValue{
+			{Group: "global", ConfigVar: api.ConfigVar{Name: "FOO", Value: "old"}},
+			{Group: "global", ConfigVar: api.ConfigVar{Name: "STALE", Value: "1"}},
+		},
+		Limits: map[string]any{"web": "std1.large.c1m1", "worker": "std1.large.c1m1"},
+	}
+	var build *api.Build
+	var requests []string
+	handle := func(path, body string) {
+		server.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			testutil.SetHeaders(w)
+			if r.Method != "GET" {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				w.WriteHeader(http.StatusCreated)
+			}
+			fmt.Fprint(w, body)
+		})
+	}
+	handle("/v2/apps/foo/", `{"id": "foo"}`)
+	handle("/v2/apps/foo/settings/", `{"app": "foo", "routable": true}`)
+	for _, path := range []string{"volumes", "domains", "gateways", "routes", "certs"} {
+		handle("/v2/apps/foo/"+path+"/", `{"count": 0, "results": []}`)
+	}
+	handle("/v2/apps/foo/services/", `{"services": []}`)
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			var set api.Config
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&set))
+			for _, value := range set.Values {
+				cfg.Values = slices.DeleteFunc(cfg.Values, func(v api.ConfigValue) bool { return v.Name == value.Name })
+				if value.Value != nil {
+					cfg.Values = append(cfg.Values, value)
+				}
+			}
+			for ptype, limit := range set.Limits {
+				if limit == nil {
+					delete(cfg.Limits, ptype)
+				} else {
+					cfg.Limits[ptype] = limit
+				}
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(cfg)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/build/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			var create api.CreateBuildRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&create))
+			build = &api.Build{App: "foo", Image: create.Image, Procfile: create.Procfile}
+			w.WriteHeader(http.StatusCreated)
+		} else if build == nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"detail": "Not found."}`)
+			return
+		}
+		json.NewEncoder(w).Encode(build)
+	})
+
+	manifests := `kind: App
+metadata:
+  name: foo
+spec:
+  routable: true
+---
+kind: Config
+metadata:
+  name: foo
+spec:
+  values:
+    - {name: FOO, value: bar, group: global}
+---
+kind: Limits
+metadata:
+  name: foo
+spec:
+  web: std1.large.c1m1
+---
+kind: Build
+metadata:
+  name: foo
+spec:
+  image: registry.example.com/foo:v1
+  procfile:
+    web: ./server
+`
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WIn: strings.NewReader(manifests), ConfigFile: cf}
+	err = cmdr.AppImport("foo", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"POST /v2/apps/foo/config/",
+		"POST /v2/apps/foo/config/",
+		"POST /v2/apps/foo/config/",
+		"POST /v2/apps/foo/config/",
+		"POST /v2/apps/foo/build/",
+	}, requests)
+	assert.Equal(t, `Applying Config foo... done
+Removing 1 config values... done
+Applying Limits foo... done
+Removing 1 limits keys... done
+Applying Build foo... done
+`, testutil.StripProgress(b.String()))
+	assert.Equal(t, map[string]any{"web": "std1.large.c1m1"}, cfg.Limits)
+
+	// the second import finds nothing to change, no build nor release
+	requests = nil
+	b.Reset()
+	cmdr.WIn = strings.NewReader(manifests)
+	err = cmdr.AppImport("foo", "-")
+	assert.NoError(t, err)
+	assert.Empty(t, requests)
+	assert.Equal(t, "No changes, foo matches the manifests.\n", b.String())
+}
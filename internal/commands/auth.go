@@ -1,13 +1,16 @@
 package commands
 
 import (
+	"path/filepath"
+
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/auth"
 	"github.com/drycc/workflow-cli/pkg/settings"
 )
 
-// Login to a Drycc controller.
-func (d *DryccCmd) Login(controller string, sslVerify bool, username, password string) error {
+// Login to a Drycc controller. The token is kept in credentialStore, or in the
+// store already used by the client configuration when it is empty.
+func (d *DryccCmd) Login(controller string, sslVerify bool, username, password, credentialStore string) error {
 	c, err := drycc.New(sslVerify, controller, "")
 	if err != nil {
 		return err
@@ -15,6 +18,11 @@ func (d *DryccCmd) Login(controller string, sslVerify bool, username, password s
 
 	// Set user agent for temporary client.
 	c.UserAgent = settings.UserAgent
+	timeout, retries, err := settings.RequestPolicy()
+	if err != nil {
+		return err
+	}
+	c.HTTPClient.Transport = settings.NewTransport(c.HTTPClient.Transport, timeout, retries)
 
 	if err = c.CheckConnection(); d.checkAPICompatibility(c, err) != nil {
 		return err
@@ -25,15 +33,21 @@ func (d *DryccCmd) Login(controller string, sslVerify bool, username, password s
 		return err
 	}
 	// save settings
-	s := settings.Settings{Client: c}
+	s := settings.Settings{Client: c, CredentialStore: credentialStore}
 	s.Client.Token = token.Token
 	s.Username = token.Username
 	filename, err := s.Save(d.ConfigFile)
 	if err != nil {
 		return err
 	}
+	if err := settings.UseContext(d.ConfigFile, s.Context); err != nil {
+		return err
+	}
 	d.Printf("Logged in as %s\n", token.Username)
 	d.Printf("Configuration file written to %s\n", filename)
+	if settings.UnprotectedCredentialStore(s.CredentialStore, filepath.Dir(filename)) {
+		d.PrintErrln(settings.UnprotectedWarning)
+	}
 	return nil
 }
 
@@ -60,6 +74,9 @@ func (d *DryccCmd) Whoami(all bool) error {
 		if err != nil {
 			return err
 		}
+		if d.Output != "" {
+			return d.printObject(user)
+		}
 		d.Println(user)
 	} else {
 		if s.Workspace != "" {
@@ -54,7 +54,7 @@ func TestLogin(t *testing.T) {
 		w.Write([]byte(`{"username":"test-user","token":"eaf2d1d85f6b410b81d94bfec159019b"}`))
 		w.Write(nil)
 	})
-	err = cmdr.Login(server.Server.URL, false, "", "")
+	err = cmdr.Login(server.Server.URL, false, "", "", "")
 	assert.NoError(t, err)
 }
 
@@ -18,6 +18,10 @@ func (d *DryccCmd) AutodeployInfo(appID string) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(appSettings)
+	}
+
 	if appSettings.Autodeploy == nil || *appSettings.Autodeploy {
 		d.Println("Autodeploy is enabled.")
 	} else {
@@ -18,6 +18,10 @@ func (d *DryccCmd) AutorollbackInfo(appID string) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(appSettings)
+	}
+
 	if appSettings.Autorollback == nil || *appSettings.Autorollback {
 		d.Println("Autorollback is enabled.")
 	} else {
@@ -19,6 +19,11 @@ func (d *DryccCmd) AutoscaleList(appID string) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(appSettings.Autoscale)
+	}
+
 	if appSettings.Autoscale == nil {
 		d.Println("No autoscale rules found.")
 	} else {
@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/drycc/workflow-cli/pkg/webdav"
+)
+
+// The files of a volume are stored under backupDataDir in a backup archive,
+// followed by the manifest.
+const (
+	backupDataDir  = "data/"
+	backupManifest = "manifest.json"
+)
+
+// volumeBackup is the manifest of a backup archive, listing all the files of
+// the volume when it was backed up.
+type volumeBackup struct {
+	App     string    `json:"app"`
+	Volume  string    `json:"volume"`
+	Created time.Time `json:"created"`
+	// Base is the creation time of the backup an incremental backup is based on.
+	Base  *time.Time   `json:"base,omitempty"`
+	Files []backupFile `json:"files"`
+}
+
+// backupFile is a file or a directory of a backup. The unchanged files of an
+// incremental backup are stored in the backups it is based on.
+type backupFile struct {
+	Path      string    `json:"path"`
+	Dir       bool      `json:"dir,omitempty"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+	SHA256    string    `json:"sha256,omitempty"`
+	Unchanged bool      `json:"unchanged,omitempty"`
+}
+
+// VolumesBackup writes the files of a volume to a gzipped tar archive with
+// a manifest of their checksums. With base, a previous backup of the volume,
+// only the files changed since are stored.
+func (d *DryccCmd) VolumesBackup(appID, name, file, base string) error {
+	manifest := volumeBackup{App: appID, Volume: name}
+	var previous map[string]backupFile
+	if base != "" {
+		baseManifest, err := readVolumeBackup(base)
+		if err != nil {
+			return err
+		}
+		manifest.Base = &baseManifest.Created
+		previous = make(map[string]backupFile, len(baseManifest.Files))
+		for _, file := range baseManifest.Files {
+			previous[file.Path] = file
+		}
+	}
+	client, stop, err := d.volumeClient(appID, name)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	d.Printf("Backing up volume %s to %s... ", name, file)
+	quit := progress(d.WOut)
+	manifest.Created = time.Now().UTC()
+	stats, unchanged, err := writeVolumeBackup(client, file, &manifest, previous)
+	quit <- true
+	<-quit
+	if err != nil {
+		os.Remove(file)
+		return err
+	}
+	files := "files"
+	if stats.Files == 1 {
+		files = "file"
+	}
+	d.Printf("done, %d %s, %s", stats.Files, files, formatBytes(stats.Bytes))
+	if base != "" {
+		d.Printf(", %d unchanged", unchanged)
+	}
+	d.Println()
+	return nil
+}
+
+func writeVolumeBackup(client *webdav.Client, file string, manifest *volumeBackup, previous map[string]backupFile) (copyStats, int, error) {
+	var stats copyStats
+	unchanged := 0
+	f, err := os.Create(file)
+	if err != nil {
+		return stats, 0, err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = client.Walk("/", func(info webdav.FileInfo) error {
+		if info.Path == "/" {
+			return nil
+		}
+		entry := backupFile{Path: strings.TrimPrefix(info.Path, "/"), Dir: info.IsDir, ModTime: info.ModTime.UTC()}
+		if info.IsDir {
+			manifest.Files = append(manifest.Files, entry)
+			return tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir, Name: backupDataDir + entry.Path + "/", Mode: 0o755, ModTime: info.ModTime,
+			})
+		}
+		entry.Size = info.Size
+		if prev, ok := previous[entry.Path]; ok && !prev.Dir && prev.Size == entry.Size && prev.ModTime.Equal(entry.ModTime) {
+			entry.SHA256 = prev.SHA256
+			entry.Unchanged = true
+			manifest.Files = append(manifest.Files, entry)
+			unchanged++
+			return nil
+		}
+		sum, err := backupVolumeFile(client, tw, info)
+		if err != nil {
+			return err
+		}
+		entry.SHA256 = sum
+		manifest.Files = append(manifest.Files, entry)
+		stats.Files++
+		stats.Bytes += info.Size
+		return nil
+	})
+	if err != nil {
+		return stats, unchanged, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return stats, unchanged, err
+	}
+	header := &tar.Header{Typeflag: tar.TypeReg, Name: backupManifest, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.Created}
+	if err := tw.WriteHeader(header); err != nil {
+		return stats, unchanged, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return stats, unchanged, err
+	}
+	if err := tw.Close(); err != nil {
+		return stats, unchanged, err
+	}
+	if err := gz.Close(); err != nil {
+		return stats, unchanged, err
+	}
+	return stats, unchanged, f.Close()
+}
+
+// backupVolumeFile writes a file of a volume to an archive and returns its
+// checksum.
+func backupVolumeFile(client *webdav.Client, tw *tar.Writer, info webdav.FileInfo) (string, error) {
+	body, err := client.Open(info.Path)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	header := &tar.Header{
+		Typeflag: tar.TypeReg, Name: backupDataDir + strings.TrimPrefix(info.Path, "/"),
+		Mode: 0o644, Size: info.Size, ModTime: info.ModTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tw, hash), io.LimitReader(body, info.Size+1))
+	if errors.Is(err, tar.ErrWriteTooLong) || err == nil && n != info.Size {
+		return "", fmt.Errorf("%s changed during the backup", info.Path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// readVolumeBackup reads the manifest of a backup archive and checks the
+// checksums of the files stored in it.
+func readVolumeBackup(file string) (volumeBackup, error) {
+	var manifest volumeBackup
+	sums := make(map[string]string)
+	err := walkVolumeBackup(file, func(header *tar.Header, r io.Reader) error {
+		if header.Name == backupManifest {
+			return json.NewDecoder(r).Decode(&manifest)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil
+		}
+		hash := sha256.New()
+		if _, err := io.Copy(hash, r); err != nil {
+			return err
+		}
+		sums[strings.TrimPrefix(header.Name, backupDataDir)] = hex.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return manifest, fmt.Errorf("invalid backup %s: %w", file, err)
+	}
+	if manifest.Created.IsZero() {
+		return manifest, fmt.Errorf("invalid backup %s: no manifest", file)
+	}
+	for _, entry := range manifest.Files {
+		if entry.Dir || entry.Unchanged {
+			continue
+		}
+		sum, ok := sums[entry.Path]
+		if !ok {
+			return manifest, fmt.Errorf("invalid backup %s: %s is missing", file, entry.Path)
+		}
+		if sum != entry.SHA256 {
+			return manifest, fmt.Errorf("invalid backup %s: the checksum of %s does not match", file, entry.Path)
+		}
+	}
+	return manifest, nil
+}
+
+// walkVolumeBackup calls fn for each entry of a backup archive, the entries
+// of files are under backupDataDir and have safe relative paths.
+func walkVolumeBackup(file string, fn func(*tar.Header, io.Reader) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Name != backupManifest {
+			name := strings.TrimPrefix(header.Name, backupDataDir)
+			if name == header.Name || path.IsAbs(name) || !isCleanRelPath(strings.TrimSuffix(name, "/")) {
+				return fmt.Errorf("unexpected entry %s", header.Name)
+			}
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func isCleanRelPath(name string) bool {
+	return name != "" && path.Clean(name) == name && name != ".." && !strings.HasPrefix(name, "../")
+}
+
+// VolumesRestore writes the files of backups to a volume, the backup to
+// restore and the backups it is based on when it is incremental. The backups
+// are all checked before the volume is written.
+func (d *DryccCmd) VolumesRestore(appID, name string, files []string) error {
+	if len(files) == 0 {
+		return errors.New("no backup to restore, use --file")
+	}
+	manifests := make([]volumeBackup, len(files))
+	for i, file := range files {
+		manifest, err := readVolumeBackup(file)
+		if err != nil {
+			return err
+		}
+		manifests[i] = manifest
+	}
+	// the archive storing each file to restore
+	sources := make(map[string]int)
+	for _, entry := range manifests[0].Files {
+		if entry.Dir {
+			continue
+		}
+		source := sourceVolumeBackup(manifests, entry)
+		if source < 0 {
+			return fmt.Errorf("%s is stored in a backup before %s, give it with another --file", entry.Path, files[0])
+		}
+		sources[entry.Path] = source
+	}
+
+	client, stop, err := d.volumeClient(appID, name)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	d.Printf("Restoring volume %s from %s... ", name, strings.Join(files, ", "))
+	quit := progress(d.WOut)
+	stats, err := restoreVolumeBackup(client, files, manifests[0], sources)
+	quit <- true
+	<-quit
+	if err != nil {
+		return err
+	}
+	count := "files"
+	if stats.Files == 1 {
+		count = "file"
+	}
+	d.Printf("done, %d %s, %s\n", stats.Files, count, formatBytes(stats.Bytes))
+	return nil
+}
+
+// sourceVolumeBackup returns the index of the manifest storing a file with
+// the checksum of entry, -1 when none does.
+func sourceVolumeBackup(manifests []volumeBackup, entry backupFile) int {
+	for i, manifest := range manifests {
+		for _, file := range manifest.Files {
+			if file.Path == entry.Path && !file.Unchanged && file.SHA256 == entry.SHA256 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func restoreVolumeBackup(client *webdav.Client, files []string, manifest volumeBackup, sources map[string]int) (copyStats, error) {
+	var stats copyStats
+	for _, entry := range manifest.Files {
+		if entry.Dir {
+			if err := client.MkdirAll(entry.Path); err != nil {
+				return stats, err
+			}
+		}
+	}
+	for i, file := range files {
+		err := walkVolumeBackup(file, func(header *tar.Header, r io.Reader) error {
+			name := strings.TrimPrefix(header.Name, backupDataDir)
+			if header.Typeflag != tar.TypeReg || header.Name == backupManifest {
+				return nil
+			}
+			if source, ok := sources[name]; !ok || source != i {
+				return nil
+			}
+			// the same path may be stored in several backups
+			delete(sources, name)
+			if err := client.MkdirAll(path.Dir("/" + name)); err != nil {
+				return err
+			}
+			if err := client.Write(name, r, header.Size); err != nil {
+				return err
+			}
+			stats.Files++
+			stats.Bytes += header.Size
+			return nil
+		})
+		if err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolumesBackupRestore(t *testing.T) {
+	t.Parallel()
+	cmdr, b := newFilerTestServer(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "empty"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "logs"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "db.sqlite"), []byte("tables"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "logs", "app.log"), []byte("hello\n"), 0o644))
+	err := cmdr.VolumesClient("foo", "myvolume", "sync", []string{src, ":/"}, false, false, false, false)
+	assert.NoError(t, err)
+
+	full := filepath.Join(dir, "full.tar.gz")
+	b.Reset()
+	err = cmdr.VolumesBackup("foo", "myvolume", full, "")
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.StripProgress(b.String()), "Backing up volume myvolume to "+full+"... done, 2 files, 12 B\n", "output")
+	manifest, err := readVolumeBackup(full)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Volume, "myvolume")
+	assert.Nil(t, manifest.Base)
+	assert.Len(t, manifest.Files, 4)
+
+	// only the log changed
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "logs", "app.log"), []byte("hello\nworld\n"), 0o644))
+	err = cmdr.VolumesClient("foo", "myvolume", "cp", []string{filepath.Join(src, "logs", "app.log"), ":/logs/app.log"}, false, false, false, false)
+	assert.NoError(t, err)
+	incremental := filepath.Join(dir, "incremental.tar.gz")
+	b.Reset()
+	err = cmdr.VolumesBackup("foo", "myvolume", incremental, full)
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.StripProgress(b.String()), "Backing up volume myvolume to "+incremental+"... done, 1 file, 12 B, 1 unchanged\n", "output")
+	manifest, err = readVolumeBackup(incremental)
+	assert.NoError(t, err)
+	assert.NotNil(t, manifest.Base)
+
+	err = cmdr.VolumesClient("foo", "myvolume", "rm", []string{"/db.sqlite", "/logs", "/empty"}, true, false, false, false)
+	assert.NoError(t, err)
+	err = cmdr.VolumesRestore("foo", "myvolume", []string{incremental})
+	assert.EqualError(t, err, "db.sqlite is stored in a backup before "+incremental+", give it with another --file")
+
+	b.Reset()
+	err = cmdr.VolumesRestore("foo", "myvolume", []string{incremental, full})
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.StripProgress(b.String()), "Restoring volume myvolume from "+incremental+", "+full+"... done, 2 files, 18 B\n", "output")
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "cat", []string{"/db.sqlite", "/logs/app.log"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "tableshello\nworld\n", "output")
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "ls", []string{"/empty"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "No files found in /empty.\n", "output")
+}
+
+func writeTestArchive(t *testing.T, file string, entries map[string]string) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"data/a.txt", "data/../b.txt", backupManifest} {
+		content, ok := entries[name]
+		if !ok {
+			continue
+		}
+		tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: int64(len(content))})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadVolumeBackup(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "backup.tar.gz")
+	manifest := `{"created": "2024-01-02T15:04:05Z", "files": [{"path": "a.txt", "size": 5, "sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}]}`
+
+	writeTestArchive(t, file, map[string]string{"data/a.txt": "hello", backupManifest: manifest})
+	_, err := readVolumeBackup(file)
+	assert.NoError(t, err)
+
+	writeTestArchive(t, file, map[string]string{"data/a.txt": "HELLO", backupManifest: manifest})
+	_, err = readVolumeBackup(file)
+	assert.EqualError(t, err, "invalid backup "+file+": the checksum of a.txt does not match")
+
+	writeTestArchive(t, file, map[string]string{backupManifest: manifest})
+	_, err = readVolumeBackup(file)
+	assert.EqualError(t, err, "invalid backup "+file+": a.txt is missing")
+
+	writeTestArchive(t, file, map[string]string{"data/a.txt": "hello"})
+	_, err = readVolumeBackup(file)
+	assert.EqualError(t, err, "invalid backup "+file+": no manifest")
+
+	writeTestArchive(t, file, map[string]string{"data/../b.txt": "escape", backupManifest: manifest})
+	_, err = readVolumeBackup(file)
+	assert.EqualError(t, err, "invalid backup "+file+": unexpected entry data/../b.txt")
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/drycc/controller-sdk-go/apps"
+	"github.com/drycc/controller-sdk-go/appsettings"
+	"github.com/drycc/workflow-cli/internal/loader"
+)
+
+// DefaultParallel is how many apps a batch runs a command on at once.
+const DefaultParallel = 4
+
+// Batch selects the apps a command runs on, instead of a single app.
+type Batch struct {
+	// Selector are the label=value pairs of the apps selected.
+	Selector []string
+	// All selects all the apps of the workspace.
+	All bool
+	// Parallel is how many apps the command runs on at once.
+	Parallel int
+	// Confirm skips the confirmation when it is "yes".
+	Confirm string
+}
+
+// Enabled tells whether the command runs on the apps of the batch.
+func (b Batch) Enabled() bool {
+	return b.All || len(b.Selector) > 0
+}
+
+// batchOutput collects the output of a command on an app, progress drawing
+// no spinner on it as the output is printed once the command is done.
+type batchOutput struct {
+	bytes.Buffer
+}
+
+// batchResult is the outcome of a command on an app.
+type batchResult struct {
+	app    string
+	output string
+	err    error
+}
+
+// BatchApps runs fn on each app selected by batch, at most batch.Parallel at
+// once. Each run writes to its own DryccCmd, whose output is printed when the
+// app is done, followed by a summary of the apps. action describes what fn
+// does, for the confirmation.
+func (d *DryccCmd) BatchApps(appID string, batch Batch, action string, fn func(d *DryccCmd, appID string) error) error {
+	if appID != "" {
+		return errors.New("--app can not be used with --selector or --all-apps")
+	}
+	selected, err := d.selectApps(batch)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no app matches the selector %s", strings.Join(batch.Selector, ","))
+	}
+
+	if batch.Confirm != "yes" {
+		d.Printf(` !    WARNING: Potentially Batch Action
+ !    This command will %s on %d apps: %s
+ !    To proceed, type "yes" !
+
+> `, action, len(selected), strings.Join(selected, ", "))
+		confirm := ""
+		fmt.Fscanln(d.WIn, &confirm)
+		if confirm != "yes" {
+			return fmt.Errorf("cancel the batch action")
+		}
+	}
+
+	results := make(chan batchResult)
+	go func() {
+		runBatch(selected, batch.Parallel, func(app string) {
+			var out batchOutput
+			cmdr := DryccCmd{ConfigFile: d.ConfigFile, Output: d.Output, WOut: &out, WErr: &out, WIn: d.WIn, Location: d.Location}
+			err := fn(&cmdr, app)
+			results <- batchResult{app: app, output: out.String(), err: err}
+		})
+		close(results)
+	}()
+
+	failed := make(map[string]error)
+	for result := range results {
+		d.Printf("=== %s\n%s", result.app, result.output)
+		if result.output != "" && !strings.HasSuffix(result.output, "\n") {
+			d.Println()
+		}
+		if result.err != nil {
+			failed[result.app] = result.err
+			d.PrintErrf("Error: %v\n", result.err)
+		}
+	}
+
+	d.Println()
+	table := d.getDefaultFormatTable([]string{"APP", "RESULT", "ERROR"})
+	for _, app := range selected {
+		if err, ok := failed[app]; ok {
+			table.Append([]string{app, "failed", strings.ReplaceAll(err.Error(), "\n", " ")})
+		} else {
+			table.Append([]string{app, "done", ""})
+		}
+	}
+	table.Render()
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d apps failed", len(failed), len(selected))
+	}
+	return nil
+}
+
+// selectApps returns the sorted names of the apps of the workspace selected
+// by batch, matching all the labels of its selector.
+func (d *DryccCmd) selectApps(batch Batch) ([]string, error) {
+	selector, err := parseLabels(batch.Selector)
+	if err != nil {
+		return nil, err
+	}
+	workspace, s, err := loader.LoadWorkspace(d.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	list, _, err := apps.List(s.Client, workspace, -1)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list))
+	for _, app := range list {
+		names = append(names, app.ID)
+	}
+	if batch.All && len(selector) == 0 {
+		slices.Sort(names)
+		return names, nil
+	}
+
+	var mu sync.Mutex
+	var selected []string
+	var errs []error
+	runBatch(names, batch.Parallel, func(app string) {
+		// a client records the versions of the controller, it can not be shared
+		client := *s.Client
+		settings, err := appsettings.List(&client, app)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("labels of %s: %w", app, err))
+			return
+		}
+		for key, value := range selector {
+			if label, ok := settings.Label[key]; !ok || fmt.Sprint(label) != fmt.Sprint(value) {
+				return
+			}
+		}
+		selected = append(selected, app)
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	slices.Sort(selected)
+	return selected, nil
+}
+
+// runBatch calls fn for each app, at most parallel at once.
+func runBatch(names []string, parallel int, fn func(app string)) {
+	if parallel < 1 {
+		parallel = DefaultParallel
+	}
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for _, app := range names {
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			fn(app)
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBatchTestServer(t *testing.T) (*DryccCmd, *bytes.Buffer) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WErr: &b, ConfigFile: cf}
+
+	server.Mux.HandleFunc("/v2/apps/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.URL.Path != "/v2/apps/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"count": 3, "next": null, "previous": null, "results": [
+			{"id": "gondor", "owner": "test"}, {"id": "rohan", "owner": "test"}, {"id": "mordor", "owner": "test"}
+		]}`)
+	})
+	labels := map[string]string{"gondor": "men", "rohan": "men", "mordor": "orcs"}
+	for app, team := range labels {
+		server.Mux.HandleFunc("/v2/apps/"+app+"/settings/", func(w http.ResponseWriter, _ *http.Request) {
+			testutil.SetHeaders(w)
+			fmt.Fprintf(w, `{"app": "%s", "label": {"team": "%s"}}`, app, team)
+		})
+		server.Mux.HandleFunc("/v2/apps/"+app+"/tls/", func(w http.ResponseWriter, _ *http.Request) {
+			testutil.SetHeaders(w)
+			if app == "rohan" {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"detail": "tls is broken"}`)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"app": "%s", "https_enforced": true}`, app)
+		})
+	}
+	return &cmdr, &b
+}
+
+func tlsForceBatch(d *DryccCmd, appID string) error {
+	return d.TLSForceEnable(appID)
+}
+
+func TestBatchAppsSelector(t *testing.T) {
+	t.Parallel()
+	cmdr, b := newBatchTestServer(t)
+
+	batch := Batch{Selector: []string{"team=men"}, Parallel: 1, Confirm: "yes"}
+	err := cmdr.BatchApps("", batch, "enable https-only requests", tlsForceBatch)
+	assert.EqualError(t, err, "1 of 2 apps failed")
+	assert.Equal(t, b.String(), `=== gondor
+Enabling https-only requests for gondor... done
+=== rohan
+Enabling https-only requests for rohan... 
+Error: internal server error
+
+APP       RESULT    ERROR                 
+gondor    done                               
+rohan     failed    internal server error    
+`, "output")
+}
+
+func TestBatchAppsAll(t *testing.T) {
+	t.Parallel()
+	cmdr, b := newBatchTestServer(t)
+
+	cmdr.WIn = strings.NewReader("yes\n")
+	var apps []string
+	err := cmdr.BatchApps("", Batch{All: true, Parallel: 1}, "list the apps", func(_ *DryccCmd, appID string) error {
+		apps = append(apps, appID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.HasPrefix(b.String(), ` !    WARNING: Potentially Batch Action
+ !    This command will list the apps on 3 apps: gondor, mordor, rohan
+ !    To proceed, type "yes" !
+
+> === `), true, b.String())
+	assert.Equal(t, apps, []string{"gondor", "mordor", "rohan"})
+
+	b.Reset()
+	cmdr.WIn = strings.NewReader("no\n")
+	err = cmdr.BatchApps("", Batch{All: true}, "list the apps", func(*DryccCmd, string) error { return nil })
+	assert.EqualError(t, err, "cancel the batch action")
+
+	err = cmdr.BatchApps("", Batch{Selector: []string{"team=elves"}, Confirm: "yes"}, "list the apps", func(*DryccCmd, string) error { return nil })
+	assert.EqualError(t, err, "no app matches the selector team=elves")
+
+	err = cmdr.BatchApps("gondor", Batch{All: true}, "list the apps", func(*DryccCmd, string) error { return nil })
+	assert.EqualError(t, err, "--app can not be used with --selector or --all-apps")
+}
+
+func TestProgressBatchOutput(t *testing.T) {
+	t.Parallel()
+	var out batchOutput
+	out.WriteString("Creating config... ")
+	quit := progress(&out)
+	quit <- true
+	<-quit
+	out.WriteString("done\n")
+	assert.Equal(t, out.String(), "Creating config... done\n")
+}
+
+func TestBatchConfigSet(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ref+exec test requires a unix shell")
+	}
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `{"count": 2, "next": null, "previous": null, "results": [{"id": "gondor"}, {"id": "rohan"}]}`)
+	})
+	for _, app := range []string{"gondor", "rohan"} {
+		server.Mux.HandleFunc("/v2/apps/"+app+"/config/", func(w http.ResponseWriter, r *http.Request) {
+			testutil.SetHeaders(w)
+			testutil.AssertBody(t, api.Config{Values: []api.ConfigValue{
+				{Group: "global", ConfigVar: api.ConfigVar{Name: "PASSWORD", Value: "s3cr3t"}},
+				{Group: "global", ConfigVar: api.ConfigVar{Name: "TOKEN", Value: "t0ken"}},
+			}}, r)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"app": "%s", "values": []}`, app)
+		})
+	}
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WErr: &b, WIn: strings.NewReader("s3cr3t"), ConfigFile: cf}
+	err = cmdr.BatchConfigSet("", Batch{All: true, Confirm: "yes"}, "", "", []string{
+		"PASSWORD=-", "TOKEN=ref+exec://echo >> " + calls + "; echo t0ken",
+	}, true)
+	assert.NoError(t, err)
+	assert.Contains(t, testutil.StripProgress(b.String()), "=== gondor\nCreating config... done\n", "output")
+	assert.Contains(t, testutil.StripProgress(b.String()), "=== rohan\nCreating config... done\n", "output")
+	data, err := os.ReadFile(calls)
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), "\n", "the command of the reference runs once")
+}
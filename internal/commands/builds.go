@@ -9,6 +9,7 @@ import (
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/builds"
 	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/dotenv"
 	"github.com/drycc/workflow-cli/pkg/i18n"
 	yaml "gopkg.in/yaml.v3"
 )
@@ -24,6 +25,11 @@ func (d *DryccCmd) BuildsInfo(appID string, version int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(build)
+	}
+
 	table := d.getDefaultFormatTable([]string{})
 	table.Append([]string{"App:", build.App})
 	table.Append([]string{"Sha:", build.Sha})
@@ -156,11 +162,12 @@ func writeDryccfileToPath(d *DryccCmd, dryccpath string, dryccfile map[string]an
 
 		for key, values := range config {
 			envFilePath := filepath.Join(configDir, key)
-			var content string
-			for k, v := range values.(map[string]any) {
-				// Append each key-value pair with newline
-				content += fmt.Sprintf("%s=%v\n", k, v)
+			env := values.(map[string]any)
+			var vars []dotenv.Var
+			for _, k := range *sortKeys(env) {
+				vars = append(vars, dotenv.Var{Name: k, Value: fmt.Sprintf("%v", env[k])})
 			}
+			content := dotenv.Format(vars)
 			// Write accumulated content once
 			if save {
 				err := os.WriteFile(envFilePath, []byte(content), 0o664)
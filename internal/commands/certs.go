@@ -29,6 +29,10 @@ func (d *DryccCmd) CertsList(appID string, results int) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(certList)
+	}
+
 	if len(certList) == 0 {
 		d.Println("No certs")
 	} else {
@@ -121,6 +125,10 @@ func (d *DryccCmd) CertInfo(appID string, name string) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(cert)
+	}
+
 	table := d.getDefaultFormatTable([]string{})
 	table.Append([]string{"Name:", safeGetString(cert.Name)})
 	table.Append([]string{"Common Name(s):", safeGetString(cert.CommonName)})
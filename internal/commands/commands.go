@@ -7,6 +7,7 @@ import (
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/pkg/logging"
 )
 
 // Commander is the interface that defines all available commands for the Drycc CLI.
@@ -17,10 +18,16 @@ type Commander interface {
 	AppsList(int) error
 	AppInfo(string) error
 	AppOpen(string) error
-	AppLogs(string, int, bool, int) error
-	AppRun(string, string, []string, uint32, uint32) error
+	AppLogs(string, int, bool, []string, string, logging.Options) error
+	AppRun(string, string, []string, uint32, uint32, bool) error
+	RunList(string) error
+	RunKill(string, []string) error
 	AppDestroy(string, string) error
 	AppTransfer(string, string) error
+	AppExport(string, string) error
+	AppImport(string, string) error
+	AppsDiff(string, string) error
+	Apply(string, []string, bool, bool, bool, string) error
 	AutodeployInfo(string) error
 	AutodeployEnable(string) error
 	AutodeployDisable(string) error
@@ -30,7 +37,8 @@ type Commander interface {
 	AutoscaleList(string) error
 	AutoscaleSet(string, string, int, int, int) error
 	AutoscaleUnset(string, string) error
-	Login(string, bool, string, string) error
+	BatchApps(string, Batch, string, func(*DryccCmd, string) error) error
+	Login(string, bool, string, string, string) error
 	Logout() error
 	Whoami(bool) error
 	TokensList(int) error
@@ -47,11 +55,17 @@ type Commander interface {
 	CertDetach(string, string, string) error
 	ConfigInfo(string, string, string, int) error
 	ConfigSet(string, string, string, []string, bool, string) error
+	BatchConfigSet(string, Batch, string, string, []string, bool) error
 	ConfigUnset(string, string, string, []string, string) error
-	ConfigPull(string, string, string, string, bool, bool) error
-	ConfigPush(string, string, string, string, bool, string) error
+	ConfigPull(string, string, string, string, bool, bool, bool, Encryption) error
+	ConfigPush(string, string, string, string, bool, string, Encryption, bool) error
+	ConfigEdit(string, string, string, string, Encryption, string, bool) error
 	ConfigAttach(string, string, string) error
 	ConfigDetach(string, string, string) error
+	ContextsList() error
+	ContextsUse(string) error
+	ContextsRename(string, string) error
+	ContextsDelete(string, string) error
 	DomainsList(string, int) error
 	DomainsAdd(string, string, string) error
 	DomainsRemove(string, string) error
@@ -93,23 +107,26 @@ type Commander interface {
 	WorkspacesRemove(string, string) error
 	WorkspacesUpdate(string, string, string, *bool) error
 	WorkspacesSwitch(string) error
-	PsList(string, int) error
-	PsLogs(string, string, int, bool, string, bool) error
+	PsList(string, int, time.Duration) error
+	PsLogs(string, string, int, bool, string, bool, logging.Options) error
+	PsPortForward(string, string, []string, string) error
 	PsExec(string, string, bool, bool, []string) error
-	PsDescribe(string, string) error
+	PsCopy(string, string, string) error
+	PsDescribe(string, string, time.Duration) error
 	PsDelete(string, []string) error
-	PtsList(string, int) error
-	PtsDescribe(string, string) error
-	PtsScale(string, []string) error
-	PtsRestart(string, []string, string) error
+	PtsList(string, int, time.Duration) error
+	PtsDescribe(string, string, time.Duration) error
+	PtsScale(string, []string, time.Duration) error
+	PtsRestart(string, []string, string, time.Duration) error
 	PtsClean(string, []string) error
 	RegistryList(string, string, int) error
 	RegistrySet(string, string, string, string) error
 	RegistryUnset(string, string) error
-	ReleasesList(string, []string, int) error
+	ReleasesList(string, []string, int, time.Duration) error
 	ReleasesInfo(string, int) error
-	ReleasesDeploy(string, []string, bool, string) error
-	ReleasesRollback(string, []string, int) error
+	ReleasesDeploy(string, []string, bool, string, time.Duration) error
+	ReleasesRollback(string, []string, int, time.Duration) error
+	ReleasesDiff(string, int, int) error
 	RoutingInfo(string) error
 	RoutingEnable(string) error
 	RoutingDisable(string) error
@@ -123,6 +140,7 @@ type Commander interface {
 	TLSAutoEnable(string) error
 	TLSAutoDisable(string) error
 	TLSAutoIssuer(string, string, string, string, string) error
+	UI(string, time.Duration) error
 	Update(bool) error
 	Println(...any) (int, error)
 	Print(...any) (int, error)
@@ -136,7 +154,9 @@ type Commander interface {
 	VolumesDelete(string, string) error
 	VolumesList(string, int) error
 	VolumesInfo(string, string) error
-	VolumesClient(string, string, ...string) error
+	VolumesClient(string, string, string, []string, bool, bool, bool, bool) error
+	VolumesBackup(string, string, string, string) error
+	VolumesRestore(string, string, []string) error
 	VolumesMount(string, string, []string) error
 	VolumesUnmount(string, string, []string) error
 }
@@ -145,6 +165,7 @@ type Commander interface {
 // methods for executing Drycc CLI commands.
 type DryccCmd struct {
 	ConfigFile string
+	Output     string
 	Warned     bool
 	WOut       io.Writer
 	WErr       io.Writer
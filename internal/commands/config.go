@@ -4,9 +4,11 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 
 	drycc "github.com/drycc/controller-sdk-go"
@@ -14,6 +16,9 @@ import (
 	"github.com/drycc/controller-sdk-go/appsettings"
 	"github.com/drycc/controller-sdk-go/config"
 	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/internal/plugins"
+	"github.com/drycc/workflow-cli/pkg/dotenv"
+	"github.com/drycc/workflow-cli/pkg/secret"
 )
 
 // ConfigInfo for an app
@@ -70,6 +75,10 @@ func (d *DryccCmd) ConfigInfo(appID string, ptype string, group string, version
 		}
 	}
 
+	if d.Output != "" {
+		return d.printObject(cv)
+	}
+
 	if len(cv.Ptype) == 0 && len(cv.Group) == 0 {
 		d.Println()
 		return nil
@@ -82,7 +91,7 @@ func (d *DryccCmd) ConfigInfo(appID string, ptype string, group string, version
 		d.Println("---\n# Group:", group)
 		var content string
 		for _, configVar := range configVars {
-			content += fmt.Sprintf("%s=%v\n", configVar.Name, configVar.Value)
+			content += fmt.Sprintf("%s=%s\n", configVar.Name, dotenv.Quote(fmt.Sprintf("%v", configVar.Value)))
 		}
 		d.Println(content)
 	}
@@ -94,7 +103,7 @@ func (d *DryccCmd) ConfigInfo(appID string, ptype string, group string, version
 			d.Println("## env")
 			var content string
 			for _, configVar := range ptypeValue.Env {
-				content += fmt.Sprintf("%s=%v\n", configVar.Name, configVar.Value)
+				content += fmt.Sprintf("%s=%s\n", configVar.Name, dotenv.Quote(fmt.Sprintf("%v", configVar.Value)))
 			}
 			d.Println(content)
 		}
@@ -129,19 +138,43 @@ func (d *DryccCmd) ConfigSet(appID string, ptype string, group string, configVar
 		return err
 	}
 
-	configMap, err := parseConfig(ptype, group, configVars)
+	configMap, err := parseConfig(ptype, group, configVars, d.WIn)
+	if err != nil {
+		return err
+	}
+	return d.setConfig(s.Client, appID, configMap, merge)
+}
+
+// BatchConfigSet sets config variables of the apps selected by batch. The
+// values are read once for all the apps, so that stdin and the secret
+// references, such as the commands of ref+exec, are not read by app.
+func (d *DryccCmd) BatchConfigSet(appID string, batch Batch, ptype string, group string, configVars []string, merge bool) error {
+	if ptype == "" && group == "" {
+		group = "global"
+	}
+	configMap, err := parseConfig(ptype, group, configVars, d.WIn)
 	if err != nil {
 		return err
 	}
+	return d.BatchApps(appID, batch, "set the config", func(d *DryccCmd, appID string) error {
+		appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+		if err != nil {
+			return err
+		}
+		return d.setConfig(s.Client, appID, configMap, merge)
+	})
+}
 
+// setConfig sets config values of an app.
+func (d *DryccCmd) setConfig(c *drycc.Client, appID string, values []api.ConfigValue, merge bool) error {
 	d.Print("Creating config... ")
 
 	quit := progress(d.WOut)
-	configObj := api.Config{Values: configMap}
-	_, err = config.Set(s.Client, appID, configObj, merge)
+	configObj := api.Config{Values: values}
+	_, err := config.Set(c, appID, configObj, merge)
 	quit <- true
 	<-quit
-	if d.checkAPICompatibility(s.Client, err) != nil {
+	if d.checkAPICompatibility(c, err) != nil {
 		return err
 	}
 	d.Print("done\n\n")
@@ -192,8 +225,11 @@ func (d *DryccCmd) ConfigUnset(appID string, ptype string, group string, configV
 	return nil
 }
 
-// ConfigPull pulls an app's config to a file.
-func (d *DryccCmd) ConfigPull(appID, ptype, group, fileName string, interactive bool, overwrite bool) error {
+// ConfigPull pulls an app's config to a file, with its values encrypted
+// when encryption.Encrypt is set, the values unchanged keeping their
+// encrypted text of the file. With keepRefs, the variables that are secret
+// references in the file keep their references rather than their values.
+func (d *DryccCmd) ConfigPull(appID, ptype, group, fileName string, interactive, overwrite, keepRefs bool, encryption Encryption) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -215,55 +251,86 @@ func (d *DryccCmd) ConfigPull(appID, ptype, group, fileName string, interactive
 	if err != nil {
 		return err
 	}
-	configValues := []api.ConfigValue{}
-
-	for _, value := range configVars.Values {
-		if (ptype != "" && value.Ptype == ptype) ||
-			(group != "" && value.Group == group) {
-			configValues = append(configValues, value)
+	vars := envVars(selectConfigValues(configVars.Values, ptype, group))
+	piped := (stat.Mode() & os.ModeCharDevice) == 0
+
+	// file holds the variables of the file as written, decrypted the same
+	// variables decrypted, and local those merged with the app's config
+	var file, decrypted, local []dotenv.Var
+	encrypted := false
+	if (interactive && !piped) || keepRefs {
+		contents, err := os.ReadFile(fileName)
+		switch {
+		case err == nil:
+			if file, err = dotenv.Parse(contents); err != nil {
+				return fmt.Errorf("%s: %w", fileName, err)
+			}
+			encrypted = dotenv.AnyEncrypted(file)
+			decrypted = slices.Clone(file)
+			if err := encryption.decrypt(decrypted); err != nil {
+				return err
+			}
+			local = slices.Clone(decrypted)
+		case !os.IsNotExist(err) || (interactive && !piped):
+			return err
 		}
 	}
+	if keepRefs {
+		keepSecretRefs(vars, local)
+	}
 
-	if (stat.Mode() & os.ModeCharDevice) == 0 {
-		d.Print(formatConfig(configValues))
+	if piped {
+		if encryption.Encrypt {
+			if err := encryption.encrypt(vars); err != nil {
+				return err
+			}
+		}
+		d.Print(dotenv.Format(vars))
 		return nil
 	}
 
 	if !overwrite {
 		if _, err := os.Stat(fileName); err == nil {
-			return fmt.Errorf("%s already exists, pass -o to overwrite", fileName)
+			return fmt.Errorf("%s already exists, pass --overwrite to overwrite it", fileName)
 		}
 	}
 
 	if interactive {
-		configMap, err := drycc.ParseEnv(fileName)
-		if err != nil {
-			return err
-		}
-		for _, value := range configValues {
-			localValue, ok := configMap[value.Name]
-			if ok {
-				if value.Value != localValue {
-					var confirm string
-					d.Printf("%s: overwrite %s with %s? (y/N) ", value.Name, localValue, value)
+		// a file encrypted stays encrypted
+		encryption.Encrypt = encryption.Encrypt || encrypted
+		for _, remote := range vars {
+			i := slices.IndexFunc(local, func(v dotenv.Var) bool { return v.Name == remote.Name })
+			if i < 0 {
+				local = append(local, remote)
+				continue
+			}
+			if local[i].File == "" && local[i].Value == remote.Value {
+				continue
+			}
+			var confirm string
+			d.Printf("%s: overwrite %s with %s? (y/N) ", remote.Name, localValue(local[i]), remote.Value)
 
-					fmt.Scanln(&confirm)
+			fmt.Scanln(&confirm)
 
-					if strings.ToLower(confirm) == "y" {
-						configMap[value.Name] = value.Value
-					}
-				}
-			} else {
-				configMap[value.Name] = value.Value
+			if strings.ToLower(confirm) == "y" {
+				local[i] = remote
 			}
 		}
-		return os.WriteFile(fileName, []byte(formatEnv(configMap)), 0o664)
+		vars = local
 	}
-	return os.WriteFile(fileName, []byte(formatConfig(configValues)), 0o664)
+	if encryption.Encrypt {
+		keepEncrypted(vars, file, decrypted)
+		if err := encryption.encrypt(vars); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(fileName, []byte(dotenv.Format(vars)), 0o664)
 }
 
-// ConfigPush pushes an app's config from a file.
-func (d *DryccCmd) ConfigPush(appID, ptype string, group string, fileName string, merge bool, confirm string) error {
+// ConfigPush pushes an app's config from a file, decrypting its values
+// encrypted with the identity of encryption. The secret references running
+// programs, such as ref+exec, are resolved only with allowExec.
+func (d *DryccCmd) ConfigPush(appID, ptype string, group string, fileName string, merge bool, confirm string, encryption Encryption, allowExec bool) error {
 	stat, err := os.Stdin.Stat()
 	if err != nil {
 		return err
@@ -276,8 +343,11 @@ func (d *DryccCmd) ConfigPush(appID, ptype string, group string, fileName string
 		group = "global"
 	}
 	var contents []byte
+	// the values of the file may be read from stdin, unless it is the file
+	source, stdin := fileName, d.WIn
 
 	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		source, stdin = "stdin", nil
 
 		err = configConfirmActionStdin(s.Client, appID, ptype, group, confirm)
 		if err != nil {
@@ -298,18 +368,87 @@ func (d *DryccCmd) ConfigPush(appID, ptype string, group string, fileName string
 		}
 	}
 
-	file := strings.Split(string(contents), "\n")
-	config := []string{}
+	vars, err := dotenv.Parse(contents)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	if err := encryption.decrypt(vars); err != nil {
+		return err
+	}
+	configMap, err := configValues(ptype, group, vars, stdin, allowExec)
+	if err != nil {
+		return err
+	}
+	return d.setConfig(s.Client, appID, configMap, merge)
+}
+
+// ConfigEdit edits an app's config in an editor and pushes it, replacing the
+// config of the ptype or group. With encryption.Encrypt, the config edited is
+// the file fileName, decrypted for the editor and encrypted again once
+// pushed, or the app's config when the file does not exist yet. The secret
+// references running programs are resolved only with allowExec.
+func (d *DryccCmd) ConfigEdit(appID, ptype, group, fileName string, encryption Encryption, confirm string, allowExec bool) error {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+	if ptype == "" && group == "" {
+		group = "global"
+	}
+	if ptype != "" && group != "" {
+		d.Println("Only one of ptype and group can be selected.")
+		return nil
+	}
 
-	for _, configVar := range file {
-		// If file has CRLF encoding, the default on windows, strip the CR
-		configVar = strings.Trim(configVar, "\r")
-		if len(configVar) > 0 {
-			config = append(config, configVar)
+	var vars []dotenv.Var
+	contents, err := os.ReadFile(fileName)
+	if encryption.Encrypt && err == nil {
+		if vars, err = dotenv.Parse(contents); err != nil {
+			return fmt.Errorf("%s: %w", fileName, err)
 		}
+		if err := encryption.decrypt(vars); err != nil {
+			return err
+		}
+	} else {
+		configVars, err := config.List(s.Client, appID, -1)
+		if d.checkAPICompatibility(s.Client, err) != nil {
+			return err
+		}
+		vars = envVars(selectConfigValues(configVars.Values, ptype, group))
 	}
 
-	return d.ConfigSet(appID, ptype, group, config, merge, "yes")
+	original := dotenv.Format(vars)
+	edited, err := editText(filepath.Base(fileName), original)
+	if err != nil {
+		return err
+	}
+	vars, err = dotenv.Parse([]byte(edited))
+	if err != nil {
+		return fmt.Errorf("the config edited: %w", err)
+	}
+	if dotenv.Format(vars) == original {
+		d.Println("No changes")
+		return nil
+	}
+
+	err = configConfirmAction(s.Client, appID, ptype, group, confirm)
+	if err != nil {
+		return err
+	}
+	configMap, err := configValues(ptype, group, vars, d.WIn, allowExec)
+	if err != nil {
+		return err
+	}
+	if err := d.setConfig(s.Client, appID, configMap, false); err != nil {
+		return err
+	}
+	if !encryption.Encrypt {
+		return nil
+	}
+	if err := encryption.encrypt(vars); err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, []byte(dotenv.Format(vars)), 0o664)
 }
 
 // ConfigAttach attaches config groups to a process type.
@@ -366,56 +505,141 @@ func (d *DryccCmd) ConfigDetach(appID string, ptype string, groups string) error
 	return nil
 }
 
-func parseConfig(ptype, group string, configVars []string) ([]api.ConfigValue, error) {
-	configMap := []api.ConfigValue{}
-
-	regex := regexp.MustCompile(`^([A-z0-9_\-\.]+)=([\s\S]*)$`)
+func parseConfig(ptype, group string, configVars []string, stdin io.Reader) ([]api.ConfigValue, error) {
+	vars := make([]dotenv.Var, 0, len(configVars))
 	for _, config := range configVars {
 		// Skip config that starts with an comment
-		if config[0] == '#' {
+		if strings.HasPrefix(config, "#") {
 			continue
 		}
-
-		if regex.MatchString(config) {
-			captures := regex.FindStringSubmatch(config)
-			value := api.ConfigValue{
-				Ptype: ptype,
-				Group: group,
-				ConfigVar: api.ConfigVar{
-					Name:  captures[1],
-					Value: captures[2],
-				},
-			}
-			configMap = append(configMap, value)
-
-		} else {
-			return nil, fmt.Errorf("'%s' does not match the pattern 'key=var', ex: MODE=test", config)
+		v, err := dotenv.ParseArg(config)
+		if err != nil {
+			return nil, err
 		}
+		vars = append(vars, v)
 	}
+	// the arguments are typed by the user, their commands may run
+	return configValues(ptype, group, vars, stdin, true)
+}
 
+// localRefSchemes are the schemes of the secret references that read local
+// secrets without running any program.
+var localRefSchemes = []string{"file", "env"}
+
+// configValues returns the config values of vars, read from their files and
+// their secret references, vars being kept as they are. Unless allowExec, the
+// references running programs, ref+exec and those of the plugins, are
+// refused, as the files holding them may be written by anyone committing to
+// the repository.
+func configValues(ptype, group string, vars []dotenv.Var, stdin io.Reader, allowExec bool) ([]api.ConfigValue, error) {
+	vars = slices.Clone(vars)
+	resolver := secretResolver()
+	for i, v := range vars {
+		if v.File != "" {
+			continue
+		}
+		if ref, ok := secret.ParseRef(v.Value); ok && !allowExec && !slices.Contains(localRefSchemes, ref.Scheme) {
+			return nil, fmt.Errorf("%s: %s runs a program, use --allow-exec to resolve it", v.Name, v.Value)
+		}
+		value, err := resolver.Resolve(v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.Name, err)
+		}
+		vars[i].Value = value
+	}
+	if err := dotenv.Resolve(vars, stdin); err != nil {
+		return nil, err
+	}
+	configMap := []api.ConfigValue{}
+	for _, v := range vars {
+		configMap = append(configMap, api.ConfigValue{
+			Ptype: ptype,
+			Group: group,
+			ConfigVar: api.ConfigVar{
+				Name:  v.Name,
+				Value: v.Value,
+			},
+		})
+	}
 	return configMap, nil
 }
 
-func formatEnv(configVars map[string]any) string {
-	var formattedConfig string
+// secretResolver returns the resolver of the secret references, whose schemes
+// other than file, env and exec are resolved by the plugins
+// drycc-secret-<scheme>.
+func secretResolver() *secret.Resolver {
+	return secret.NewResolver(func(scheme string) (secret.Provider, bool) {
+		path, ok := plugins.LookupPlugin("secret-" + scheme)
+		if !ok {
+			return nil, false
+		}
+		return secret.Plugin(path), true
+	})
+}
 
-	keys := *sortKeys(configVars)
-	for _, key := range keys {
-		formattedConfig += fmt.Sprintf("%s=%v\n", key, configVars[key])
+// keepEncrypted sets the values of vars equal to the decrypted values of
+// file back to their encrypted text, which encrypt keeps, so that the values
+// unchanged are not encrypted again and do not change in the file. decrypted
+// holds the variables of file once decrypted.
+func keepEncrypted(vars, file, decrypted []dotenv.Var) {
+	for i, v := range file {
+		if v.File != "" || !dotenv.Encrypted(v.Value) {
+			continue
+		}
+		j := slices.IndexFunc(vars, func(remote dotenv.Var) bool { return remote.Name == v.Name })
+		if j >= 0 && vars[j].File == "" && vars[j].Value == decrypted[i].Value {
+			vars[j].Value = v.Value
+		}
 	}
+}
 
-	return formattedConfig
+// keepSecretRefs sets the variables of vars that are secret references in local
+// back to their references.
+func keepSecretRefs(vars, local []dotenv.Var) {
+	for _, v := range local {
+		if v.File != "" || !secret.IsRef(v.Value) {
+			continue
+		}
+		if i := slices.IndexFunc(vars, func(remote dotenv.Var) bool { return remote.Name == v.Name }); i >= 0 {
+			vars[i] = v
+		}
+	}
 }
 
-func formatConfig(configVars []api.ConfigValue) string {
-	var formattedConfig string
+// localValue returns the value of a variable of a local file, as written.
+func localValue(v dotenv.Var) string {
+	switch v.File {
+	case "":
+		return v.Value
+	case dotenv.Stdin:
+		return dotenv.Stdin
+	}
+	return "@" + v.File
+}
 
-	values := sortConfigValues(configVars)
+// selectConfigValues returns the values of a ptype or a group.
+func selectConfigValues(values []api.ConfigValue, ptype, group string) []api.ConfigValue {
+	selected := []api.ConfigValue{}
 	for _, value := range values {
-		formattedConfig += fmt.Sprintf("%s=%v\n", value.Name, value.Value)
+		if (ptype != "" && value.Ptype == ptype) ||
+			(group != "" && value.Group == group) {
+			selected = append(selected, value)
+		}
 	}
+	return selected
+}
 
-	return formattedConfig
+// envVars returns the variables of config values, sorted.
+func envVars(configVars []api.ConfigValue) []dotenv.Var {
+	var vars []dotenv.Var
+	for _, value := range sortConfigValues(configVars) {
+		vars = append(vars, dotenv.Var{Name: value.Name, Value: fmt.Sprintf("%v", value.Value)})
+	}
+	return vars
+}
+
+func formatConfig(configVars []api.ConfigValue) string {
+	return dotenv.Format(envVars(configVars))
 }
 
 func configConfirmAction(s *drycc.Client, appID string, ptype string, group string, confirm string) error {
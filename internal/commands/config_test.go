@@ -2,11 +2,19 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 
+	"filippo.io/age"
 	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/pkg/dotenv"
 	"github.com/drycc/workflow-cli/pkg/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,16 +22,33 @@ import (
 func TestParseConfig(t *testing.T) {
 	t.Parallel()
 
-	_, err := parseConfig("", "", []string{"FOO=bar", "CAR star"})
+	_, err := parseConfig("", "", []string{"FOO=bar", "CAR star"}, nil)
 	assert.NotEqual(t, err, nil, "config")
 
-	actual, err := parseConfig("", "", []string{"FOO=bar"})
+	actual, err := parseConfig("", "", []string{"FOO=bar"}, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, actual, []api.ConfigValue{{ConfigVar: api.ConfigVar{Name: "FOO", Value: "bar"}}}, "map")
 
-	actual, err = parseConfig("", "", []string{"FOO="})
+	// the arguments commented out are skipped
+	actual, err = parseConfig("", "", []string{"#note", "FOO=bar", "# BAR=car"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, actual, []api.ConfigValue{{ConfigVar: api.ConfigVar{Name: "FOO", Value: "bar"}}}, "map")
+
+	actual, err = parseConfig("", "", []string{"FOO="}, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, actual, []api.ConfigValue{{ConfigVar: api.ConfigVar{Name: "FOO", Value: ""}}}, "map")
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	assert.NoError(t, os.WriteFile(path, []byte("-----BEGIN KEY-----\nabc\n-----END KEY-----\n"), 0o600))
+	actual, err = parseConfig("web", "", []string{"KEY=@" + path, "INPUT=-"}, strings.NewReader("from stdin"))
+	assert.NoError(t, err)
+	assert.Equal(t, actual, []api.ConfigValue{
+		{Ptype: "web", ConfigVar: api.ConfigVar{Name: "KEY", Value: "-----BEGIN KEY-----\nabc\n-----END KEY-----\n"}},
+		{Ptype: "web", ConfigVar: api.ConfigVar{Name: "INPUT", Value: "from stdin"}},
+	}, "map")
+
+	_, err = parseConfig("", "", []string{"INPUT=-"}, nil)
+	assert.EqualError(t, err, "INPUT: the standard input is not available")
 }
 
 func TestFormatConfig(t *testing.T) {
@@ -58,11 +83,19 @@ func TestFormatConfig(t *testing.T) {
 				Name:  "FLOAT",
 				Value: 12.34,
 			},
+		}, {
+			Ptype: "web",
+			Group: "",
+			ConfigVar: api.ConfigVar{
+				Name:  "MOTD",
+				Value: "hello \"world\"\nbye",
+			},
 		},
 	}
 
 	testOut := formatConfig(testMap)
 	assert.Equal(t, testOut, `FLOAT=12.34
+MOTD="hello \"world\"\nbye"
 NCC=1701
 TEST=testing
 TRUE=false
@@ -147,6 +180,289 @@ func TestConfigSet(t *testing.T) {
 `, "output")
 }
 
+func TestConfigPush(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			testutil.AssertBody(t, api.Config{
+				Values: []api.ConfigValue{
+					{Ptype: "web", ConfigVar: api.ConfigVar{Name: "MODE", Value: "production"}},
+					{Ptype: "web", ConfigVar: api.ConfigVar{Name: "KEY", Value: "-----BEGIN KEY-----\nabc\n-----END KEY-----"}},
+					{Ptype: "web", ConfigVar: api.ConfigVar{Name: "PASSWORD", Value: "p#ss word"}},
+				},
+			}, r)
+		}
+		fmt.Fprintf(w, `{"owner": "jkirk", "app": "foo", "values": []}`)
+	})
+
+	path := filepath.Join(t.TempDir(), ".env")
+	err = os.WriteFile(path, []byte(`# production
+export MODE=production
+KEY="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+PASSWORD='p#ss word' # quoted
+`), 0o600)
+	assert.NoError(t, err)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.ConfigPush("foo", "web", "", path, true, "yes", Encryption{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.StripProgress(b.String()), `Creating config... done
+
+`, "output")
+}
+
+// ageKeys writes the recipients and the identity files of a new age key.
+func ageKeys(t *testing.T) (string, string, *age.X25519Identity) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	recipients := filepath.Join(dir, "recipients")
+	if err := os.WriteFile(recipients, []byte(identity.Recipient().String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	key := filepath.Join(dir, "age.key")
+	if err := os.WriteFile(key, []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return recipients, key, identity
+}
+
+func TestConfigPullEncrypt(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"owner": "jkirk", "app": "foo", "values": [
+			{"group": "global", "name": "PASSWORD", "value": "s3cr3t"},
+			{"group": "global", "name": "MODE", "value": "production"},
+			{"ptype": "web", "name": "PORT", "value": "8000"}
+		]}`)
+	})
+	recipients, key, identity := ageKeys(t)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	// the config is printed as the output of the tests is not a terminal
+	err = cmdr.ConfigPull("foo", "", "", "", false, false, false, Encryption{Encrypt: true, Recipients: recipients})
+	assert.NoError(t, err)
+	assert.NotContains(t, b.String(), "s3cr3t")
+	assert.NotContains(t, b.String(), "production")
+
+	vars, err := dotenv.Parse(b.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, vars[0].Name, "MODE", "name")
+	assert.Equal(t, vars[1].Name, "PASSWORD", "name")
+	assert.NoError(t, dotenv.Decrypt(vars, identity))
+	assert.Equal(t, vars, []dotenv.Var{{Name: "MODE", Value: "production"}, {Name: "PASSWORD", Value: "s3cr3t"}}, "vars")
+
+	// the values are encrypted back with the identity
+	path := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(path, b.Bytes(), 0o600))
+	server.Mux.HandleFunc("/v2/apps/bar/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		testutil.AssertBody(t, api.Config{
+			Values: []api.ConfigValue{
+				{Group: "global", ConfigVar: api.ConfigVar{Name: "MODE", Value: "production"}},
+				{Group: "global", ConfigVar: api.ConfigVar{Name: "PASSWORD", Value: "s3cr3t"}},
+			},
+		}, r)
+		fmt.Fprintf(w, `{"owner": "jkirk", "app": "bar", "values": []}`)
+	})
+	b.Reset()
+	err = cmdr.ConfigPush("bar", "", "global", path, false, "yes", Encryption{Identity: key}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.StripProgress(b.String()), "Creating config... done\n\n", "output")
+
+	err = cmdr.ConfigPush("bar", "", "global", path, false, "yes", Encryption{Identity: filepath.Join(t.TempDir(), "missing")}, false)
+	assert.ErrorContains(t, err, "identity: open ")
+}
+
+func TestKeepEncrypted(t *testing.T) {
+	t.Parallel()
+	_, _, identity := ageKeys(t)
+	file := []dotenv.Var{{Name: "MODE", Value: "production"}, {Name: "PASSWORD", Value: "s3cr3t"}}
+	assert.NoError(t, dotenv.Encrypt(file, identity.Recipient()))
+	decrypted := slices.Clone(file)
+	assert.NoError(t, dotenv.Decrypt(decrypted, identity))
+
+	vars := []dotenv.Var{{Name: "MODE", Value: "production"}, {Name: "PASSWORD", Value: "changed"}, {Name: "PORT", Value: "8000"}}
+	keepEncrypted(vars, file, decrypted)
+	assert.NoError(t, dotenv.Encrypt(vars, identity.Recipient()))
+	assert.Equal(t, file[0].Value, vars[0].Value, "the value unchanged keeps its encrypted text")
+	assert.NotEqual(t, file[1].Value, vars[1].Value, "the value changed is encrypted again")
+	assert.NoError(t, dotenv.Decrypt(vars, identity))
+	assert.Equal(t, []dotenv.Var{{Name: "MODE", Value: "production"}, {Name: "PASSWORD", Value: "changed"}, {Name: "PORT", Value: "8000"}}, vars)
+}
+
+func TestConfigEdit(t *testing.T) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var pushed atomic.Pointer[api.Config]
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.Method == "POST" {
+			var body api.Config
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			pushed.Store(&body)
+		}
+		fmt.Fprintf(w, `{"owner": "jkirk", "app": "foo", "values": [
+			{"group": "global", "name": "MODE", "value": "production"},
+			{"group": "global", "name": "DEBUG", "value": "false"}
+		]}`)
+	})
+	recipients, key, _ := ageKeys(t)
+
+	// the editor sets MODE=staging and removes DEBUG
+	dir := t.TempDir()
+	editor := filepath.Join(dir, "editor.sh")
+	script := "#!/bin/sh\nprintf 'MODE=staging\\nPASSWORD=\"p#ss word\"\\n' > \"$1\"\n"
+	assert.NoError(t, os.WriteFile(editor, []byte(script), 0o700))
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	path := filepath.Join(dir, ".env")
+	encryption := Encryption{Encrypt: true, Recipients: recipients, Identity: key}
+	err = cmdr.ConfigEdit("foo", "", "", path, encryption, "yes", false)
+	assert.NoError(t, err)
+	assert.Equal(t, pushed.Load().Values, []api.ConfigValue{
+		{Group: "global", ConfigVar: api.ConfigVar{Name: "MODE", Value: "staging"}},
+		{Group: "global", ConfigVar: api.ConfigVar{Name: "PASSWORD", Value: "p#ss word"}},
+	}, "values")
+
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(contents), "staging")
+	vars, err := dotenv.Parse(contents)
+	assert.NoError(t, err)
+	assert.NoError(t, encryption.decrypt(vars))
+	assert.Equal(t, vars, []dotenv.Var{{Name: "MODE", Value: "staging"}, {Name: "PASSWORD", Value: "p#ss word"}}, "file")
+
+	// the file is edited again, without changes
+	pushed.Store(nil)
+	b.Reset()
+	err = cmdr.ConfigEdit("foo", "", "", path, encryption, "yes", false)
+	assert.NoError(t, err)
+	assert.Nil(t, pushed.Load())
+	assert.Equal(t, b.String(), "No changes\n", "output")
+}
+
+func TestConfigPushSecretRefs(t *testing.T) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		testutil.AssertBody(t, api.Config{
+			Values: []api.ConfigValue{
+				{Group: "global", ConfigVar: api.ConfigVar{Name: "MODE", Value: "production"}},
+				{Group: "global", ConfigVar: api.ConfigVar{Name: "PASSWORD", Value: "s3cr3t"}},
+				{Group: "global", ConfigVar: api.ConfigVar{Name: "DATABASE_URL", Value: "postgres://db:5432/app"}},
+				{Group: "global", ConfigVar: api.ConfigVar{Name: "TOKEN", Value: "from vault"}},
+			},
+		}, r)
+		fmt.Fprintf(w, `{"owner": "jkirk", "app": "foo", "values": []}`)
+	})
+
+	dir := t.TempDir()
+	secrets := filepath.Join(dir, "db.json")
+	assert.NoError(t, os.WriteFile(secrets, []byte(`{"password": "s3cr3t"}`), 0o600))
+	plugin := filepath.Join(dir, "drycc-secret-vault")
+	assert.NoError(t, os.WriteFile(plugin, []byte("#!/bin/sh\ntest \"$1\" = ref+vault://secret/app#token && echo from vault\n"), 0o700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("TEST_DATABASE_URL", "postgres://db:5432/app")
+
+	path := filepath.Join(dir, ".env")
+	err = os.WriteFile(path, []byte(`MODE=production
+PASSWORD=ref+file://`+secrets+`#password
+DATABASE_URL=ref+env://TEST_DATABASE_URL
+TOKEN=ref+vault://secret/app#token
+`), 0o600)
+	assert.NoError(t, err)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	err = cmdr.ConfigPush("foo", "", "global", path, false, "yes", Encryption{}, false)
+	assert.EqualError(t, err, "TOKEN: ref+vault://secret/app#token runs a program, use --allow-exec to resolve it")
+	err = cmdr.ConfigPush("foo", "", "global", path, false, "yes", Encryption{}, true)
+	assert.NoError(t, err)
+
+	// the commands of a file only run with --allow-exec
+	marker := filepath.Join(dir, "marker")
+	assert.NoError(t, os.WriteFile(path, []byte("TOKEN=ref+exec://touch "+marker+"\n"), 0o600))
+	err = cmdr.ConfigPush("foo", "", "global", path, false, "yes", Encryption{}, false)
+	assert.EqualError(t, err, "TOKEN: ref+exec://touch "+marker+" runs a program, use --allow-exec to resolve it")
+	assert.NoFileExists(t, marker)
+	assert.Equal(t, testutil.StripProgress(b.String()), "Creating config... done\n\n", "output")
+
+	err = cmdr.ConfigSet("foo", "", "global", []string{"TOKEN=ref+op://vault/item"}, true, "yes")
+	assert.EqualError(t, err, "TOKEN: ref+op://vault/item: no provider of the scheme op")
+}
+
+func TestConfigPullKeepRefs(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"owner": "jkirk", "app": "foo", "values": [
+			{"group": "global", "name": "PASSWORD", "value": "s3cr3t"},
+			{"group": "global", "name": "MODE", "value": "production"}
+		]}`)
+	})
+
+	path := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(path, []byte("MODE=staging\nPASSWORD=ref+file://secrets/db.txt#password\n"), 0o600))
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	// the config is printed as the output of the tests is not a terminal
+	err = cmdr.ConfigPull("foo", "", "", path, false, false, true, Encryption{})
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "MODE=production\nPASSWORD=ref+file://secrets/db.txt#password\n", "output")
+
+	b.Reset()
+	err = cmdr.ConfigPull("foo", "", "", path, false, false, false, Encryption{})
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "MODE=production\nPASSWORD=s3cr3t\n", "output")
+
+	// a missing file has no references to keep
+	b.Reset()
+	err = cmdr.ConfigPull("foo", "", "", filepath.Join(t.TempDir(), ".env"), false, false, true, Encryption{})
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "MODE=production\nPASSWORD=s3cr3t\n", "output")
+}
+
 func TestConfigUnset(t *testing.T) {
 	t.Parallel()
 	cf, server, err := testutil.NewTestServerAndClient()
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/drycc/workflow-cli/pkg/settings"
+)
+
+// ContextsList lists the contexts of the client configuration.
+func (d *DryccCmd) ContextsList() error {
+	contexts, err := settings.ListContexts(d.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	if d.Output != "" {
+		return d.printObject(contexts)
+	}
+
+	if len(contexts) == 0 {
+		d.Println("No contexts found.")
+		return nil
+	}
+	table := d.getDefaultFormatTable([]string{"CURRENT", "NAME", "CONTROLLER", "USERNAME", "WORKSPACE"})
+	for _, context := range contexts {
+		current := ""
+		if context.Current {
+			current = "*"
+		}
+		table.Append([]string{
+			current,
+			context.Name,
+			context.Controller,
+			context.Username,
+			safeGetString(context.Workspace),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+// ContextsUse switches the current context of the client configuration.
+func (d *DryccCmd) ContextsUse(name string) error {
+	if err := settings.UseContext(d.ConfigFile, name); err != nil {
+		return err
+	}
+
+	d.Printf("Switched to context %s\n", name)
+	return nil
+}
+
+// ContextsRename renames a context of the client configuration.
+func (d *DryccCmd) ContextsRename(oldName, newName string) error {
+	if err := settings.RenameContext(d.ConfigFile, oldName, newName); err != nil {
+		return err
+	}
+
+	d.Printf("Context %s renamed to %s\n", oldName, newName)
+	return nil
+}
+
+// ContextsDelete deletes a context from the client configuration.
+func (d *DryccCmd) ContextsDelete(name, confirm string) error {
+	if confirm == "" {
+		d.Printf(` !    WARNING: Potentially Destructive Action
+ !    This command will remove the context and its token: %s
+ !    To proceed, type "%s" or re-run this command with --confirm=%s
+
+> `, name, name, name)
+
+		fmt.Scanln(&confirm)
+	}
+
+	if confirm != name {
+		return fmt.Errorf("context %s does not match confirm %s, aborting", name, confirm)
+	}
+
+	if err := settings.DeleteContext(d.ConfigFile, name); err != nil {
+		return err
+	}
+
+	d.Printf("Context %s deleted\n", name)
+	return nil
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drycc/workflow-cli/pkg/settings"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func createContextsFile(t *testing.T) string {
+	cf := filepath.Join(t.TempDir(), "client.json")
+	err := os.WriteFile(cf, []byte(`{"current_context":"staging","contexts":{`+
+		`"staging":{"username":"s","controller":"http://staging.example.com","token":"a","workspace":"dev"},`+
+		`"production":{"username":"p","controller":"https://production.example.com","token":"b"}}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cf
+}
+
+func TestContextsList(t *testing.T) {
+	t.Parallel()
+	cf := createContextsFile(t)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err := cmdr.ContextsList()
+	assert.NoError(t, err)
+	testutil.AssertOutput(t, b.String(), `CURRENT    NAME          CONTROLLER                        USERNAME    WORKSPACE
+           production    https://production.example.com    p           <none>
+*          staging       http://staging.example.com        s           dev
+`)
+
+	b.Reset()
+	cmdr.Output = "jsonpath={range .[*]}{.name}={.current}{\"\\n\"}{end}"
+	err = cmdr.ContextsList()
+	assert.NoError(t, err)
+	assert.Equal(t, "production=false\nstaging=true\n", b.String())
+}
+
+func TestContextsUse(t *testing.T) {
+	t.Parallel()
+	cf := createContextsFile(t)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err := cmdr.ContextsUse("production")
+	assert.NoError(t, err)
+	assert.Equal(t, "Switched to context production\n", b.String())
+
+	s, err := settings.LoadContext(cf, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "p", s.Username)
+
+	assert.Error(t, cmdr.ContextsUse("missing"))
+}
+
+func TestContextsRename(t *testing.T) {
+	t.Parallel()
+	cf := createContextsFile(t)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err := cmdr.ContextsRename("staging", "stage")
+	assert.NoError(t, err)
+	assert.Equal(t, "Context staging renamed to stage\n", b.String())
+
+	s, err := settings.LoadContext(cf, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "stage", s.Context)
+}
+
+func TestContextsDelete(t *testing.T) {
+	t.Parallel()
+	cf := createContextsFile(t)
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err := cmdr.ContextsDelete("production", "staging")
+	assert.Error(t, err)
+
+	err = cmdr.ContextsDelete("production", "production")
+	assert.NoError(t, err)
+	assert.Equal(t, "Context production deleted\n", b.String())
+
+	contexts, err := settings.ListContexts(cf)
+	assert.NoError(t, err)
+	assert.Len(t, contexts, 1)
+	assert.Equal(t, "staging", contexts[0].Name)
+}
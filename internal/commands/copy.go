@@ -0,0 +1,352 @@
+package commands
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/ps"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"golang.org/x/net/websocket"
+)
+
+// copyStats counts what a copy transferred.
+type copyStats struct {
+	Files int
+	Bytes int64
+}
+
+// copyCounter counts what a ps copy transferred, it is read by copyProgress
+// while the copy runs.
+type copyCounter struct {
+	files atomic.Int64
+	bytes atomic.Int64
+	// sent counts the bytes sent of an archive of size bytes to a pod.
+	sent atomic.Int64
+	size atomic.Int64
+}
+
+func (c *copyCounter) String() string {
+	files := "files"
+	if c.files.Load() == 1 {
+		files = "file"
+	}
+	text := fmt.Sprintf("%d %s, %s", c.files.Load(), files, formatBytes(c.bytes.Load()))
+	if size := c.size.Load(); size > 0 && c.sent.Load() < size {
+		text += fmt.Sprintf(", %s of %s sent", formatBytes(c.sent.Load()), formatBytes(size))
+	}
+	return text
+}
+
+// countWriter counts the bytes written to it.
+type countWriter struct {
+	n *atomic.Int64
+}
+
+func (w countWriter) Write(p []byte) (int, error) {
+	w.n.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// copyProgress prints the counts of a copy in place until quit, as progress
+// prints its spinner.
+func copyProgress(wOut io.Writer, counter *copyCounter) chan bool {
+	tick := time.NewTicker(400 * time.Millisecond)
+	quit := make(chan bool)
+	go func() {
+		defer tick.Stop()
+		for {
+			text := counter.String()
+			fmt.Fprint(wOut, text)
+			backspaces := strings.Repeat("\b", len(text))
+			select {
+			case <-quit:
+				fmt.Fprint(wOut, backspaces)
+				close(quit)
+				return
+			case <-tick.C:
+				fmt.Fprint(wOut, backspaces)
+			}
+		}
+	}()
+	return quit
+}
+
+// PsCopy copies files and directories between the local filesystem and a
+// pod, one of src and dst is <pod>:<path>. The files are streamed as a tar
+// archive through exec, so the pod needs tar and sh.
+func (d *DryccCmd) PsCopy(appID, src, dst string) error {
+	srcPod, srcPath := parseCopyPath(src)
+	dstPod, dstPath := parseCopyPath(dst)
+	if (srcPod == "") == (dstPod == "") {
+		return errors.New("one of the source and the destination must be a pod path, ex: my-pod:/tmp/file")
+	}
+	if srcPath == "" || dstPath == "" {
+		return errors.New("the source and the destination paths must not be empty")
+	}
+
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+
+	d.Printf("Copying %s to %s... ", src, dst)
+	counter := &copyCounter{}
+	quit := copyProgress(d.WOut, counter)
+	if srcPod == "" {
+		err = copyToPod(s.Client, appID, dstPod, srcPath, dstPath, counter)
+	} else {
+		err = copyFromPod(s.Client, appID, srcPod, srcPath, dstPath, counter)
+	}
+	quit <- true
+	<-quit
+	if err != nil {
+		return err
+	}
+	d.Printf("done, %s\n", counter)
+	return nil
+}
+
+// parseCopyPath splits <pod>:<path>, local paths have no pod.
+func parseCopyPath(arg string) (string, string) {
+	pod, p, found := strings.Cut(arg, ":")
+	// a drive letter or a relative path with a colon is local
+	if !found || len(pod) <= 1 || strings.ContainsAny(pod, `/\.`) {
+		return "", arg
+	}
+	return pod, p
+}
+
+// copyToPod archives a local path and extracts it in a pod. The archive is
+// written to a temporary file first, so that its size tells the pod where
+// stdin ends.
+func copyToPod(c *drycc.Client, appID, podID, localPath, podPath string, counter *copyCounter) error {
+	dir, name := path.Split(podPath)
+	// pod:/dir/ keeps the local name under /dir
+	if name == "" {
+		name = filepath.Base(localPath)
+	}
+	dir = path.Clean(dir + ".")
+
+	archive, err := os.CreateTemp("", "drycc-cp-*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+	if err := writeTar(archive, localPath, name, counter); err != nil {
+		return err
+	}
+	size, err := archive.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	counter.size.Store(size)
+
+	script := fmt.Sprintf("mkdir -p %s && head -c %d | tar -xf - -C %s", shellQuote(dir), size, shellQuote(dir))
+	conn, err := ps.Exec(c, appID, podID, api.Command{Stdin: true, Command: []string{"sh", "-c", script}})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := archive.Read(buf)
+		if n > 0 {
+			if err := websocket.Message.Send(conn, append([]byte(stdinChannel), buf[:n]...)); err != nil {
+				return err
+			}
+			counter.sent.Add(int64(n))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return waitExec(conn, io.Discard)
+}
+
+// copyFromPod archives a path of a pod and extracts it locally.
+func copyFromPod(c *drycc.Client, appID, podID, podPath, localPath string, counter *copyCounter) error {
+	podPath = strings.TrimSuffix(podPath, "/")
+	if podPath == "" {
+		podPath = "/"
+	}
+	name := path.Base(podPath)
+	// an existing local directory receives the copy under the pod name
+	target := localPath
+	if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+		target = filepath.Join(localPath, name)
+	}
+
+	conn, err := ps.Exec(c, appID, podID, api.Command{Command: []string{"tar", "cf", "-", "-C", path.Dir(podPath), name}})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	pr, pw := io.Pipe()
+	result := make(chan error, 1)
+	go func() {
+		err := readTar(pr, name, target, counter)
+		// drain what is left so that the websocket is read until its end
+		io.Copy(io.Discard, pr)
+		result <- err
+	}()
+	err = waitExec(conn, pw)
+	pw.CloseWithError(err)
+	if tarErr := <-result; err == nil {
+		err = tarErr
+	}
+	return err
+}
+
+// writeTar archives a local file or directory with its modes, the entries
+// are named after name.
+func writeTar(w io.Writer, root, name string, counter *copyCounter) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(name, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		counter.files.Add(1)
+		_, err = io.Copy(io.MultiWriter(tw, countWriter{&counter.bytes}), f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// readTar extracts the entries under name of an archive to target, with
+// their modes. Entries outside of name are rejected.
+func readTar(r io.Reader, name, target string, counter *copyCounter) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		entry := path.Clean(header.Name)
+		rel, ok := strings.CutPrefix(entry, name)
+		if !ok || rel != "" && !strings.HasPrefix(rel, "/") {
+			return fmt.Errorf("invalid archive entry %s", header.Name)
+		}
+		p := filepath.Join(target, filepath.FromSlash(rel))
+		mode := header.FileInfo().Mode()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, mode.Perm()|0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+			if err != nil {
+				return err
+			}
+			counter.files.Add(1)
+			_, err = io.Copy(io.MultiWriter(f, countWriter{&counter.bytes}), tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			// the mode of an existing file is not changed by OpenFile
+			if err := os.Chmod(p, mode.Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// a link out of target could be written through by the next entries
+			link := filepath.Join(filepath.Dir(p), filepath.FromSlash(header.Linkname))
+			if filepath.IsAbs(header.Linkname) || !withinDir(target, link) {
+				return fmt.Errorf("invalid archive link %s -> %s", header.Name, header.Linkname)
+			}
+			os.Remove(p)
+			if err := os.Symlink(header.Linkname, p); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// withinDir reports whether p is dir or under it.
+func withinDir(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// shellQuote quotes a word for sh.
+func shellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// formatBytes formats a size with a binary unit, ex: 1.5 MiB.
+func formatBytes(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	for _, unit := range []string{"KiB", "MiB", "GiB"} {
+		value /= 1024
+		if value < 1024 || unit == "GiB" {
+			return fmt.Sprintf("%.1f %s", value, unit)
+		}
+	}
+	return ""
+}
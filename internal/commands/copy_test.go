@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestParseCopyPath(t *testing.T) {
+	t.Parallel()
+
+	for arg, expected := range map[string][2]string{
+		"my-pod:/tmp/file": {"my-pod", "/tmp/file"},
+		"my-pod:tmp":       {"my-pod", "tmp"},
+		"./file":           {"", "./file"},
+		"dir/a:b":          {"", "dir/a:b"},
+		`C:\Users\file`:    {"", `C:\Users\file`},
+		"file":             {"", "file"},
+	} {
+		pod, path := parseCopyPath(arg)
+		assert.Equal(t, expected, [2]string{pod, path}, arg)
+	}
+}
+
+func TestPsCopyToPod(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	local := filepath.Join(t.TempDir(), "hotfix")
+	assert.NoError(t, os.MkdirAll(filepath.Join(local, "bin"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(local, "bin", "run.sh"), []byte("#!/bin/sh\n"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(local, "app.conf"), []byte("debug=true\n"), 0o600))
+
+	var script string
+	var archive bytes.Buffer
+	server.Mux.Handle("/v2/apps/foo/pods/foo-web-111/exec/", websocket.Handler(func(conn *websocket.Conn) {
+		var command api.Command
+		websocket.JSON.Receive(conn, &command)
+		assert.True(t, command.Stdin)
+		script = command.Command[2]
+		size, _ := strconv.Atoi(regexp.MustCompile(`head -c (\d+)`).FindStringSubmatch(script)[1])
+		for archive.Len() < size {
+			var data []byte
+			if err := websocket.Message.Receive(conn, &data); err != nil {
+				return
+			}
+			assert.Equal(t, stdinChannel, string(data[:1]))
+			archive.Write(data[1:])
+		}
+		websocket.Message.Send(conn, errorChannel+`{"metadata":{},"status":"Success"}`)
+	}))
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	err = cmdr.PsCopy("foo", local, "foo-web-111:/app/")
+	assert.NoError(t, err)
+	assert.Equal(t, "Copying "+local+" to foo-web-111:/app/... done, 2 files, 21 B\n", testutil.StripProgress(b.String()))
+	assert.Regexp(t, `^mkdir -p '/app' && head -c \d+ \| tar -xf - -C '/app'$`, script)
+
+	modes := make(map[string]os.FileMode)
+	tr := tar.NewReader(&archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		modes[header.Name] = header.FileInfo().Mode()
+	}
+	assert.Equal(t, map[string]os.FileMode{
+		"hotfix/":           os.ModeDir | 0o755,
+		"hotfix/app.conf":   0o600,
+		"hotfix/bin/":       os.ModeDir | 0o755,
+		"hotfix/bin/run.sh": 0o755,
+	}, modes)
+}
+
+func TestPsCopyFromPod(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	remote := filepath.Join(t.TempDir(), "dumps")
+	assert.NoError(t, os.MkdirAll(remote, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(remote, "heap.hprof"), bytes.Repeat([]byte("x"), 2048), 0o640))
+	var archive bytes.Buffer
+	assert.NoError(t, writeTar(&archive, remote, "dumps", &copyCounter{}))
+
+	server.Mux.Handle("/v2/apps/foo/pods/foo-web-111/exec/", websocket.Handler(func(conn *websocket.Conn) {
+		var command api.Command
+		websocket.JSON.Receive(conn, &command)
+		if command.Command[len(command.Command)-1] == "missing" {
+			websocket.Message.Send(conn, stderrChannel+"tar: missing: No such file or directory\n")
+			websocket.Message.Send(conn, errorChannel+`{"status":"Failure","message":"command terminated with non-zero exit code",`+
+				`"reason":"NonZeroExitCode","details":{"causes":[{"reason":"ExitCode","message":"2"}]}}`)
+			return
+		}
+		assert.Equal(t, []string{"tar", "cf", "-", "-C", "/tmp", "dumps"}, command.Command)
+		data := archive.Bytes()
+		for len(data) > 0 {
+			n := min(len(data), 1000)
+			websocket.Message.Send(conn, append([]byte(stdoutChannel), data[:n]...))
+			data = data[n:]
+		}
+		websocket.Message.Send(conn, errorChannel+`{"metadata":{},"status":"Success"}`)
+	}))
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	local := t.TempDir()
+	err = cmdr.PsCopy("foo", "foo-web-111:/tmp/dumps", local)
+	assert.NoError(t, err)
+	assert.Equal(t, "Copying foo-web-111:/tmp/dumps to "+local+"... done, 1 file, 2.0 KiB\n", testutil.StripProgress(b.String()))
+	info, err := os.Stat(filepath.Join(local, "dumps", "heap.hprof"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode())
+	assert.Equal(t, int64(2048), info.Size())
+
+	err = cmdr.PsCopy("foo", "foo-web-111:/tmp/missing", local)
+	assert.EqualError(t, err, "command terminated with non-zero exit code: tar: missing: No such file or directory")
+	var exitErr *ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, exitErr.Code, 2, "exit code")
+
+	err = cmdr.PsCopy("foo", "a", "b")
+	assert.EqualError(t, err, "one of the source and the destination must be a pod path, ex: my-pod:/tmp/file")
+}
+
+func TestReadTarRejectsEscapes(t *testing.T) {
+	t.Parallel()
+
+	for _, header := range []tar.Header{
+		{Name: "dumps/../../etc/passwd", Typeflag: tar.TypeReg},
+		{Name: "other/file", Typeflag: tar.TypeReg},
+		{Name: "dumps/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc"},
+		{Name: "dumps/abs", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+	} {
+		var archive bytes.Buffer
+		tw := tar.NewWriter(&archive)
+		assert.NoError(t, tw.WriteHeader(&header))
+		assert.NoError(t, tw.Close())
+		err := readTar(&archive, "dumps", filepath.Join(t.TempDir(), "dumps"), &copyCounter{})
+		assert.Error(t, err, header.Name)
+	}
+}
+
+func TestCopyCounter(t *testing.T) {
+	t.Parallel()
+
+	counter := &copyCounter{}
+	counter.files.Add(1)
+	counter.bytes.Add(512)
+	assert.Equal(t, "1 file, 512 B", counter.String())
+	counter.files.Add(2)
+	counter.size.Store(3 << 20)
+	counter.sent.Add(1 << 20)
+	assert.Equal(t, "3 files, 512 B, 1.0 MiB of 3.0 MiB sent", counter.String())
+	counter.sent.Add(2 << 20)
+	assert.Equal(t, "3 files, 512 B", counter.String())
+}
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "3.0 MiB", formatBytes(3<<20))
+	assert.Equal(t, "2048.0 GiB", formatBytes(2<<40))
+}
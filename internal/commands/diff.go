@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/drycc/pkg/prettyprint"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/coder"
+	"github.com/drycc/workflow-cli/pkg/settings"
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/term"
+)
+
+// ReleasesDiff compares the config and the build of two releases of an app.
+func (d *DryccCmd) ReleasesDiff(appID string, from, to int) error {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+
+	a, err := d.releaseManifests(s, appID, from)
+	if err != nil {
+		return err
+	}
+	b, err := d.releaseManifests(s, appID, to)
+	if err != nil {
+		return err
+	}
+	return d.printDiff(fmt.Sprintf("%s v%d", appID, from), fmt.Sprintf("%s v%d", appID, to), a, b)
+}
+
+// AppsDiff compares the config and the build of the latest releases of two
+// apps.
+func (d *DryccCmd) AppsDiff(appA, appB string) error {
+	s, err := settings.Load(d.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	a, err := d.releaseManifests(s, appA, -1)
+	if err != nil {
+		return err
+	}
+	b, err := d.releaseManifests(s, appB, -1)
+	if err != nil {
+		return err
+	}
+	return d.printDiff(appA, appB, a, b)
+}
+
+// printDiff prints the differences between two sets of manifests as a
+// unified diff of their YAML, or as a list of changed fields when an output
+// format is set.
+func (d *DryccCmd) printDiff(nameA, nameB string, a, b []coder.Manifest) error {
+	// the app name is not part of the difference
+	for _, manifests := range [][]coder.Manifest{a, b} {
+		for i := range manifests {
+			if slices.Contains(singletonKinds, manifests[i].Kind) {
+				manifests[i].Metadata.Name = ""
+			}
+		}
+	}
+
+	if d.Output != "" {
+		changes := diffManifests(a, b)
+		if changes == nil {
+			changes = []fieldChange{}
+		}
+		return d.printObject(changes)
+	}
+
+	textA, err := coder.EncodeManifests(a)
+	if err != nil {
+		return err
+	}
+	textB, err := coder.EncodeManifests(b)
+	if err != nil {
+		return err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(textA)),
+		B:        splitLines(string(textB)),
+		FromFile: nameA,
+		ToFile:   nameB,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		d.Printf("No differences between %s and %s.\n", nameA, nameB)
+		return nil
+	}
+
+	color := d.colorEnabled()
+	for _, line := range splitLines(diff) {
+		d.Println(colorizeDiffLine(strings.TrimSuffix(line, "\n"), color))
+	}
+	return nil
+}
+
+// splitLines splits text after each newline.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func colorizeDiffLine(line string, color bool) string {
+	if !color {
+		return line
+	}
+	var code string
+	switch {
+	case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+		code = "\x1b[1m"
+	case strings.HasPrefix(line, "@@"):
+		code = prettyprint.Colors["Cyan"]
+	case strings.HasPrefix(line, "-"):
+		code = prettyprint.Colors["Red"]
+	case strings.HasPrefix(line, "+"):
+		code = prettyprint.Colors["Green"]
+	default:
+		return line
+	}
+	return code + line + prettyprint.Colors["Default"]
+}
+
+// colorEnabled reports whether the output is a terminal that accepts colors.
+func (d *DryccCmd) colorEnabled() bool {
+	return os.Getenv("NO_COLOR") == "" && d.isTerminal()
+}
+
+// isTerminal reports whether the output is a terminal.
+func (d *DryccCmd) isTerminal() bool {
+	f, ok := d.WOut.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// diffManifests compares the specs of two sets of manifests, the path of
+// each change starts with the kind and the name of the resource.
+func diffManifests(a, b []coder.Manifest) []fieldChange {
+	specsA := make(map[string]map[string]any)
+	specsB := make(map[string]map[string]any)
+	var keys []string
+	for _, side := range []struct {
+		manifests []coder.Manifest
+		specs     map[string]map[string]any
+	}{{a, specsA}, {b, specsB}} {
+		for _, m := range side.manifests {
+			key := manifestKey(m)
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+			side.specs[key] = plainSpec(m.Spec)
+		}
+	}
+
+	var changes []fieldChange
+	for _, key := range keys {
+		specA, okA := specsA[key]
+		specB, okB := specsB[key]
+		switch {
+		case !okA:
+			changes = append(changes, fieldChange{Path: key, New: specB})
+		case !okB:
+			changes = append(changes, fieldChange{Path: key, Old: specA})
+		default:
+			changes = append(changes, diffObjects(key, specA, specB)...)
+		}
+	}
+	return changes
+}
+
+// diffObjects compares two plain values field by field, lists of named
+// objects such as config values are compared by name and other lists as a
+// whole.
+func diffObjects(path string, old, new any) []fieldChange {
+	if reflect.DeepEqual(old, new) {
+		return nil
+	}
+	if old == nil || new == nil {
+		return []fieldChange{{Path: path, Old: old, New: new}}
+	}
+
+	var changes []fieldChange
+	switch o := old.(type) {
+	case map[string]any:
+		n, ok := new.(map[string]any)
+		if !ok {
+			return []fieldChange{{Path: path, Old: old, New: new}}
+		}
+		keys := slices.Sorted(maps.Keys(o))
+		for key := range n {
+			if _, ok := o[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			changes = append(changes, diffObjects(joinPath(path, key), o[key], n[key])...)
+		}
+	case []any:
+		n, ok := new.([]any)
+		oldByName, oldNamed := namedItems(o)
+		newByName, newNamed := namedItems(n)
+		if !ok || !oldNamed || !newNamed {
+			return []fieldChange{{Path: path, Old: old, New: new}}
+		}
+		names := slices.Sorted(maps.Keys(oldByName))
+		for name := range newByName {
+			if _, ok := oldByName[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			changes = append(changes, diffObjects(fmt.Sprintf("%s[%s]", path, name), oldByName[name], newByName[name])...)
+		}
+	default:
+		changes = append(changes, fieldChange{Path: path, Old: old, New: new})
+	}
+	return changes
+}
+
+// plainSpec converts a spec into plain values.
+func plainSpec(spec map[string]any) map[string]any {
+	m, err := comparableSpec(coder.Manifest{Spec: spec})
+	if err != nil {
+		return spec
+	}
+	return m
+}
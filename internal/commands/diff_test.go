@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleasesDiff(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/config/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		switch r.URL.Query().Get("version") {
+		case "v1":
+			fmt.Fprint(w, `{"app": "foo", "values": [
+				{"name": "FOO", "value": "bar", "group": "global"},
+				{"name": "OLD", "value": "1", "group": "global"}]}`)
+		case "v2":
+			fmt.Fprint(w, `{"app": "foo", "values": [
+				{"name": "FOO", "value": "baz", "group": "global"}],
+				"limits": {"web": "std1.large.c1m1"}}`)
+		}
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/build/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"app": "foo", "image": "registry/foo:%s", "procfile": {"web": "./server"}}`, r.URL.Query().Get("version"))
+	})
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.ReleasesDiff("foo", 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, `--- foo v1
++++ foo v2
+@@ -6,16 +6,20 @@
+   values:
+     - group: global
+       name: FOO
+-      value: bar
+-    - group: global
+-      name: OLD
+-      value: "1"
++      value: baz
++---
++apiVersion: controller.drycc.cc/v2
++kind: Limits
++metadata:
++  name: ""
++spec:
++  web: std1.large.c1m1
+ ---
+ apiVersion: controller.drycc.cc/v2
+ kind: Build
+ metadata:
+   name: ""
+ spec:
+-  image: registry/foo:v1
++  image: registry/foo:v2
+   procfile:
+     web: ./server
+`, b.String())
+
+	b.Reset()
+	cmdr.Output = "json"
+	err = cmdr.ReleasesDiff("foo", 1, 2)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"path": "Config.values[global/FOO].value", "old": "bar", "new": "baz"},
+		{"path": "Config.values[global/OLD]", "old": {"group": "global", "name": "OLD", "value": "1"}},
+		{"path": "Build.image", "old": "registry/foo:v1", "new": "registry/foo:v2"},
+		{"path": "Limits", "new": {"web": "std1.large.c1m1"}}
+	]`, b.String())
+}
+
+func TestAppsDiff(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	for _, app := range []string{"foo", "bar"} {
+		server.Mux.HandleFunc("/v2/apps/"+app+"/config/", func(w http.ResponseWriter, _ *http.Request) {
+			testutil.SetHeaders(w)
+			fmt.Fprintf(w, `{"app": "%s", "values": [{"name": "FOO", "value": "bar", "group": "global"}]}`, app)
+		})
+		server.Mux.HandleFunc("/v2/apps/"+app+"/build/", func(w http.ResponseWriter, _ *http.Request) {
+			testutil.SetHeaders(w)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"detail": "Not found."}`)
+		})
+	}
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err = cmdr.AppsDiff("foo", "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "No differences between foo and bar.\n", b.String())
+
+	b.Reset()
+	cmdr.Output = "json"
+	err = cmdr.AppsDiff("foo", "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", b.String())
+}
+
+func TestColorizeDiffLine(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "-  value: bar", colorizeDiffLine("-  value: bar", false))
+	assert.Equal(t, "\033[0;31m-  value: bar\033[0m", colorizeDiffLine("-  value: bar", true))
+	assert.Equal(t, "\033[0;32m+  value: baz\033[0m", colorizeDiffLine("+  value: baz", true))
+	assert.Equal(t, "\x1b[1m--- foo v1\033[0m", colorizeDiffLine("--- foo v1", true))
+	assert.Equal(t, "   name: FOO", colorizeDiffLine("   name: FOO", true))
+}
@@ -22,6 +22,11 @@ func (d *DryccCmd) DomainsList(appID string, results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(domains)
+	}
+
 	if count > 0 {
 		table := d.getDefaultFormatTable([]string{"APP", "PTYPE", "CREATED", "UPDATED", "DOMAIN"})
 		for _, domain := range domains {
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/drycc/workflow-cli/pkg/dotenv"
+	"github.com/drycc/workflow-cli/pkg/settings"
+)
+
+// IdentityEnv is the environment variable of the default age identity file.
+const IdentityEnv = "DRYCC_AGE_IDENTITY"
+
+// DefaultRecipients is the file of the age recipients of the config files
+// encrypted, in the directory of the app.
+const DefaultRecipients = ".drycc/recipients"
+
+// Encryption holds the age keys of the config files whose values are
+// encrypted, see dotenv.Encrypt.
+type Encryption struct {
+	// Encrypt writes the values encrypted for the keys of Recipients.
+	Encrypt bool
+	// Recipients is the file of the age public keys, one per line.
+	Recipients string
+	// Identity is the file of the age private keys, IdentityEnv or
+	// ~/.drycc/age.key when empty.
+	Identity string
+}
+
+func (e Encryption) identity() string {
+	if e.Identity != "" {
+		return e.Identity
+	}
+	if identity := os.Getenv(IdentityEnv); identity != "" {
+		return identity
+	}
+	return filepath.Join(settings.FindHome(), ".drycc", "age.key")
+}
+
+// encrypt encrypts the values of vars for the recipients.
+func (e Encryption) encrypt(vars []dotenv.Var) error {
+	recipients := e.Recipients
+	if recipients == "" {
+		recipients = DefaultRecipients
+	}
+	keys, err := dotenv.ReadRecipients(recipients)
+	if err != nil {
+		return err
+	}
+	return dotenv.Encrypt(vars, keys...)
+}
+
+// decrypt decrypts the encrypted values of vars with the identity, which is
+// only read when some are.
+func (e Encryption) decrypt(vars []dotenv.Var) error {
+	if !dotenv.AnyEncrypted(vars) {
+		return nil
+	}
+	identities, err := dotenv.ReadIdentities(e.identity())
+	if err != nil {
+		return err
+	}
+	return dotenv.Decrypt(vars, identities...)
+}
+
+// editText returns text as edited by the user in the editor of VISUAL or
+// EDITOR, in a private temporary file named name.
+func editText(name, text string) (string, error) {
+	dir, err := os.MkdirTemp("", "drycc-edit-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s: %w", editor, err)
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,543 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/volumes"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/webdav"
+)
+
+// filerCommands are the commands of VolumesClient with their minimum and
+// maximum numbers of arguments, -1 for no maximum.
+var filerCommands = map[string][2]int{
+	"ls":    {0, 1},
+	"cat":   {1, -1},
+	"cp":    {2, 2},
+	"rm":    {1, -1},
+	"mkdir": {1, -1},
+	"sync":  {2, 2},
+}
+
+// VolumesClient runs a command on the files of a volume through its WebDAV
+// file access service, which is stopped when the command is done. The paths
+// of the volume given to cp and sync start with a colon, ex: :/data.
+func (d *DryccCmd) VolumesClient(appID, name, command string, args []string, recursive, checksum, prune, dryRun bool) error {
+	limits, ok := filerCommands[command]
+	if !ok {
+		return fmt.Errorf("unknown command %q, use ls, cat, cp, rm, mkdir or sync", command)
+	}
+	if len(args) < limits[0] || limits[1] >= 0 && len(args) > limits[1] {
+		return fmt.Errorf("invalid number of arguments for %s", command)
+	}
+
+	client, stop, err := d.volumeClient(appID, name)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	switch command {
+	case "ls":
+		dir := "/"
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		return d.filerList(client, dir, recursive)
+	case "cat":
+		return d.filerCat(client, args)
+	case "cp":
+		return d.filerCopy(client, args[0], args[1], recursive)
+	case "rm":
+		return d.filerRemove(client, args, recursive)
+	case "mkdir":
+		for _, arg := range args {
+			if err := client.MkdirAll(volumePath(arg)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return d.filerSync(client, args[0], args[1], checksum, prune, dryRun)
+	}
+}
+
+// volumeClient starts the WebDAV service of a volume and returns a client of
+// it, the service is stopped by calling stop.
+func (d *DryccCmd) volumeClient(appID, name string) (*webdav.Client, context.CancelFunc, error) {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	_, filer, err := volumes.Serve(ctx, s.Client, appID, name)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		stop()
+		return nil, nil, err
+	}
+	client, err := webdav.NewClient(filer["endpoint"], filer["username"], filer["password"])
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	client.HTTPClient = s.Client.HTTPClient
+	return client, stop, nil
+}
+
+// volumePath returns the path of a file of a volume, without its colon.
+func volumePath(arg string) string {
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(arg, ":"), "/")
+}
+
+func (d *DryccCmd) filerList(client *webdav.Client, dir string, recursive bool) error {
+	root, err := client.Stat(volumePath(dir))
+	if err != nil {
+		return err
+	}
+	var files []webdav.FileInfo
+	if recursive {
+		err = client.Walk(root.Path, func(file webdav.FileInfo) error {
+			if file.Path != root.Path {
+				files = append(files, file)
+			}
+			return nil
+		})
+	} else if root.IsDir {
+		files, err = client.ReadDir(root.Path)
+	} else {
+		files = []webdav.FileInfo{root}
+	}
+	if err != nil {
+		return err
+	}
+
+	if d.Output != "" {
+		return d.printObject(files)
+	}
+	if len(files) == 0 {
+		d.Printf("No files found in %s.\n", root.Path)
+		return nil
+	}
+	table := d.getDefaultFormatTable([]string{"NAME", "SIZE", "MODIFIED"})
+	for _, file := range files {
+		name := strings.TrimPrefix(strings.TrimPrefix(file.Path, root.Path), "/")
+		if name == "" {
+			name = file.Name()
+		}
+		size := formatBytes(file.Size)
+		if file.IsDir {
+			name += "/"
+			size = "-"
+		}
+		table.Append([]string{name, size, d.formatTime(file.ModTime.Format(time.RFC3339))})
+	}
+	table.Render()
+	return nil
+}
+
+func (d *DryccCmd) filerCat(client *webdav.Client, args []string) error {
+	for _, arg := range args {
+		body, err := client.Open(volumePath(arg))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(d.WOut, body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DryccCmd) filerRemove(client *webdav.Client, args []string, recursive bool) error {
+	for _, arg := range args {
+		file, err := client.Stat(volumePath(arg))
+		if err != nil {
+			return err
+		}
+		if file.Path == "/" {
+			return errors.New("refusing to remove the root of the volume")
+		}
+		if file.IsDir && !recursive {
+			return fmt.Errorf("%s is a directory, use -r to remove it", file.Path)
+		}
+		if err := client.Remove(file.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filerEndpoints returns the file systems and the paths of src and dst, one
+// of which is on the volume.
+func filerEndpoints(client *webdav.Client, src, dst string) (filerFS, string, filerFS, string, error) {
+	srcRemote, dstRemote := strings.HasPrefix(src, ":"), strings.HasPrefix(dst, ":")
+	if srcRemote == dstRemote {
+		return nil, "", nil, "", errors.New("one of the source and the destination must be a volume path, ex: :/data")
+	}
+	if srcRemote {
+		return davFS{client}, volumePath(src), localFS{}, dst, nil
+	}
+	return localFS{}, src, davFS{client}, volumePath(dst), nil
+}
+
+func (d *DryccCmd) filerCopy(client *webdav.Client, src, dst string, recursive bool) error {
+	srcFS, srcPath, dstFS, dstPath, err := filerEndpoints(client, src, dst)
+	if err != nil {
+		return err
+	}
+	srcEntries, err := srcFS.list(srcPath)
+	if err != nil {
+		return err
+	}
+	if srcEntries[""].dir && !recursive {
+		return fmt.Errorf("%s is a directory, use -r to copy it", src)
+	}
+	// copy into the destination when it is a directory, as cp does
+	if entry, err := dstFS.stat(dstPath); err == nil && entry.dir || strings.HasSuffix(dst, "/") {
+		dstPath = dstFS.join(dstPath, srcFS.base(srcPath))
+	}
+
+	d.Printf("Copying %s to %s... ", src, dst)
+	quit := progress(d.WOut)
+	stats, err := transferFiles(srcFS, srcPath, srcEntries, dstFS, dstPath, nil, syncOptions{}, io.Discard)
+	quit <- true
+	<-quit
+	if err != nil {
+		return err
+	}
+	files := "files"
+	if stats.Files == 1 {
+		files = "file"
+	}
+	d.Printf("done, %d %s, %s\n", stats.Files, files, formatBytes(stats.Bytes))
+	return nil
+}
+
+func (d *DryccCmd) filerSync(client *webdav.Client, src, dst string, checksum, prune, dryRun bool) error {
+	srcFS, srcPath, dstFS, dstPath, err := filerEndpoints(client, src, dst)
+	if err != nil {
+		return err
+	}
+	srcEntries, err := srcFS.list(srcPath)
+	if err != nil {
+		return err
+	}
+	dstEntries, err := dstFS.list(dstPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	options := syncOptions{update: true, checksum: checksum, prune: prune, dryRun: dryRun}
+	stats, err := transferFiles(srcFS, srcPath, srcEntries, dstFS, dstPath, dstEntries, options, d.WOut)
+	if err != nil {
+		return err
+	}
+	summary := fmt.Sprintf("%d copied, %s, %d deleted", stats.Files, formatBytes(stats.Bytes), stats.Deleted)
+	if dryRun {
+		summary += " (dry run)"
+	}
+	d.Println(summary)
+	return nil
+}
+
+// filerEntry is a file or a directory of a filerFS.
+type filerEntry struct {
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+// filerFS is the local file system or a volume, the source or the
+// destination of a copy.
+type filerFS interface {
+	// list returns the entries of a file or of a directory and its files,
+	// by their slash separated path relative to root, "" for root itself.
+	list(root string) (map[string]filerEntry, error)
+	stat(name string) (filerEntry, error)
+	open(name string) (io.ReadCloser, error)
+	// create writes a file, with modTime when possible.
+	create(name string, r io.Reader, size int64, modTime time.Time) error
+	mkdir(name string) error
+	remove(name string) error
+	join(root, rel string) string
+	base(name string) string
+}
+
+type localFS struct{}
+
+func (localFS) list(root string) (map[string]filerEntry, error) {
+	entries := make(map[string]filerEntry)
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			rel = ""
+		}
+		entries[filepath.ToSlash(rel)] = filerEntry{size: info.Size(), modTime: info.ModTime(), dir: entry.IsDir()}
+		return nil
+	})
+	return entries, err
+}
+
+func (localFS) stat(name string) (filerEntry, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return filerEntry{}, err
+	}
+	return filerEntry{size: info.Size(), modTime: info.ModTime(), dir: info.IsDir()}, nil
+}
+
+func (localFS) open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (localFS) create(name string, r io.Reader, _ int64, modTime time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(name, modTime, modTime)
+}
+
+func (localFS) mkdir(name string) error {
+	return os.MkdirAll(name, 0o755)
+}
+
+func (localFS) remove(name string) error {
+	return os.RemoveAll(name)
+}
+
+func (localFS) join(root, rel string) string {
+	return filepath.Join(root, filepath.FromSlash(rel))
+}
+
+func (localFS) base(name string) string {
+	return filepath.Base(name)
+}
+
+type davFS struct {
+	client *webdav.Client
+}
+
+func (v davFS) list(root string) (map[string]filerEntry, error) {
+	root = path.Clean(root)
+	entries := make(map[string]filerEntry)
+	err := v.client.Walk(root, func(file webdav.FileInfo) error {
+		rel := strings.TrimPrefix(strings.TrimPrefix(file.Path, root), "/")
+		entries[rel] = filerEntry{size: file.Size, modTime: file.ModTime, dir: file.IsDir}
+		return nil
+	})
+	return entries, err
+}
+
+func (v davFS) stat(name string) (filerEntry, error) {
+	file, err := v.client.Stat(name)
+	if err != nil {
+		return filerEntry{}, err
+	}
+	return filerEntry{size: file.Size, modTime: file.ModTime, dir: file.IsDir}, nil
+}
+
+func (v davFS) open(name string) (io.ReadCloser, error) {
+	return v.client.Open(name)
+}
+
+func (v davFS) create(name string, r io.Reader, size int64, _ time.Time) error {
+	if err := v.client.MkdirAll(path.Dir(name)); err != nil {
+		return err
+	}
+	return v.client.Write(name, r, size)
+}
+
+func (v davFS) mkdir(name string) error {
+	return v.client.MkdirAll(name)
+}
+
+func (v davFS) remove(name string) error {
+	return v.client.Remove(name)
+}
+
+func (davFS) join(root, rel string) string {
+	return path.Join(root, rel)
+}
+
+func (davFS) base(name string) string {
+	return path.Base(name)
+}
+
+type syncOptions struct {
+	// update copies only the files missing or changed in the destination.
+	update   bool
+	checksum bool
+	prune    bool
+	dryRun   bool
+}
+
+type syncStats struct {
+	copyStats
+	Deleted int
+}
+
+// transferFiles copies the src entries to dst, printing the paths copied and
+// deleted to out. With update, a file is copied when its size differs, when
+// its checksum differs with checksum or else when it was modified after the
+// destination.
+func transferFiles(srcFS filerFS, srcRoot string, srcEntries map[string]filerEntry, dstFS filerFS, dstRoot string, dstEntries map[string]filerEntry, options syncOptions, out io.Writer) (syncStats, error) {
+	var stats syncStats
+	names := make([]string, 0, len(srcEntries))
+	for name := range srcEntries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		entry := srcEntries[name]
+		dstEntry, exists := dstEntries[name]
+		srcPath, dstPath := srcFS.join(srcRoot, name), dstFS.join(dstRoot, name)
+		if exists && dstEntry.dir != entry.dir {
+			fmt.Fprintf(out, "deleting %s\n", displayPath(dstRoot, name))
+			stats.Deleted++
+			if !options.dryRun {
+				if err := dstFS.remove(dstPath); err != nil {
+					return stats, err
+				}
+			}
+			exists = false
+		}
+		if entry.dir {
+			if !exists && !options.dryRun {
+				if err := dstFS.mkdir(dstPath); err != nil {
+					return stats, err
+				}
+			}
+			continue
+		}
+		if options.update && exists {
+			changed, err := fileChanged(srcFS, srcPath, entry, dstFS, dstPath, dstEntry, options.checksum)
+			if err != nil {
+				return stats, err
+			}
+			if !changed {
+				continue
+			}
+		}
+		fmt.Fprintf(out, "copying %s\n", displayPath(dstRoot, name))
+		stats.Files++
+		stats.Bytes += entry.size
+		if options.dryRun {
+			continue
+		}
+		if err := copyFile(srcFS, srcPath, dstFS, dstPath, entry); err != nil {
+			return stats, err
+		}
+	}
+
+	if !options.prune {
+		return stats, nil
+	}
+	extra := make([]string, 0)
+	for name := range dstEntries {
+		if _, ok := srcEntries[name]; !ok {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	removed := ""
+	for _, name := range extra {
+		// the files of a removed directory are removed with it
+		if removed != "" && strings.HasPrefix(name, removed+"/") {
+			continue
+		}
+		fmt.Fprintf(out, "deleting %s\n", displayPath(dstRoot, name))
+		stats.Deleted++
+		removed = name
+		if options.dryRun {
+			continue
+		}
+		if err := dstFS.remove(dstFS.join(dstRoot, name)); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+func displayPath(root, name string) string {
+	if name == "" {
+		return root
+	}
+	return name
+}
+
+// fileChanged tells whether a file has to be copied to update dst. The
+// modification times are compared to the second, as WebDAV keeps them.
+func fileChanged(srcFS filerFS, srcPath string, src filerEntry, dstFS filerFS, dstPath string, dst filerEntry, checksum bool) (bool, error) {
+	if src.size != dst.size {
+		return true, nil
+	}
+	if !checksum {
+		return src.modTime.Truncate(time.Second).After(dst.modTime.Truncate(time.Second)), nil
+	}
+	srcSum, err := fileChecksum(srcFS, srcPath)
+	if err != nil {
+		return false, err
+	}
+	dstSum, err := fileChecksum(dstFS, dstPath)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(srcSum, dstSum), nil
+}
+
+func fileChecksum(fsys filerFS, name string) ([]byte, error) {
+	r, err := fsys.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+func copyFile(srcFS filerFS, srcPath string, dstFS filerFS, dstPath string, entry filerEntry) error {
+	r, err := srcFS.open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return dstFS.create(dstPath, r, entry.size, entry.modTime)
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/webdav"
+)
+
+func newFilerTestServer(t *testing.T) (*DryccCmd, *bytes.Buffer) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	var b bytes.Buffer
+	cmdr := &DryccCmd{WOut: &b, ConfigFile: cf}
+
+	base := "/v2/apps/foo/volumes/myvolume/filer"
+	server.Mux.HandleFunc(base+"/_/ping", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `pong`)
+	})
+	server.Mux.HandleFunc(base+"/_/bind", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"username": "user", "password": "pass"}`)
+	})
+	handler := &webdav.Handler{Prefix: base + "/webdav", FileSystem: webdav.NewMemFS(), LockSystem: webdav.NewMemLS()}
+	server.Mux.HandleFunc(base+"/webdav/", func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+	return cmdr, &b
+}
+
+// listedNames returns the names of the files printed by ls.
+func listedNames(output string) []string {
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n")[1:] {
+		names = append(names, strings.Fields(line)[0])
+	}
+	return names
+}
+
+func TestVolumesClient(t *testing.T) {
+	t.Parallel()
+	cmdr, b := newFilerTestServer(t)
+	dir := t.TempDir()
+	local := filepath.Join(dir, "hello.txt")
+	assert.NoError(t, os.WriteFile(local, []byte("hello\n"), 0o644))
+
+	err := cmdr.VolumesClient("foo", "myvolume", "mkdir", []string{"/data/logs", ":/tmp"}, false, false, false, false)
+	assert.NoError(t, err)
+	err = cmdr.VolumesClient("foo", "myvolume", "cp", []string{local, ":/data"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.StripProgress(b.String()), fmt.Sprintf("Copying %s to :/data... done, 1 file, 6 B\n", local), "output")
+
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "ls", []string{"/data"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, listedNames(b.String()), []string{"hello.txt", "logs/"})
+	assert.Contains(t, b.String(), "6 B")
+
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "ls", nil, true, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, listedNames(b.String()), []string{"data/", "data/hello.txt", "data/logs/", "tmp/"})
+
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "cat", []string{"/data/hello.txt", "data/hello.txt"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "hello\nhello\n", "output")
+
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "cp", []string{":/data", dir}, false, false, false, false)
+	assert.EqualError(t, err, ":/data is a directory, use -r to copy it")
+	err = cmdr.VolumesClient("foo", "myvolume", "cp", []string{":/data", dir}, true, false, false, false)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "data", "hello.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), "hello\n")
+	assert.DirExists(t, filepath.Join(dir, "data", "logs"))
+
+	err = cmdr.VolumesClient("foo", "myvolume", "rm", []string{"/data"}, false, false, false, false)
+	assert.EqualError(t, err, "/data is a directory, use -r to remove it")
+	err = cmdr.VolumesClient("foo", "myvolume", "rm", []string{"/"}, true, false, false, false)
+	assert.EqualError(t, err, "refusing to remove the root of the volume")
+	err = cmdr.VolumesClient("foo", "myvolume", "rm", []string{"/data", "/tmp"}, true, false, false, false)
+	assert.NoError(t, err)
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "ls", nil, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "No files found in /.\n", "output")
+
+	err = cmdr.VolumesClient("foo", "myvolume", "cat", []string{"/missing"}, false, false, false, false)
+	assert.EqualError(t, err, "GET /missing: 404 Not Found")
+	err = cmdr.VolumesClient("foo", "myvolume", "cp", []string{local, dir}, false, false, false, false)
+	assert.EqualError(t, err, "one of the source and the destination must be a volume path, ex: :/data")
+	err = cmdr.VolumesClient("foo", "myvolume", "mv", nil, false, false, false, false)
+	assert.EqualError(t, err, `unknown command "mv", use ls, cat, cp, rm, mkdir or sync`)
+	err = cmdr.VolumesClient("foo", "myvolume", "cp", []string{local}, false, false, false, false)
+	assert.EqualError(t, err, "invalid number of arguments for cp")
+}
+
+func TestVolumesClientSync(t *testing.T) {
+	t.Parallel()
+	cmdr, b := newFilerTestServer(t)
+	src := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "css"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "index.html"), []byte("<html>"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "css", "site.css"), []byte("body{}"), 0o644))
+
+	err := cmdr.VolumesClient("foo", "myvolume", "sync", []string{src, ":/www"}, false, false, false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "copying css/site.css\ncopying index.html\n2 copied, 12 B, 0 deleted (dry run)\n", "output")
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "ls", []string{"/"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "No files found in /.\n", "output")
+
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "sync", []string{src, ":/www"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "copying css/site.css\ncopying index.html\n2 copied, 12 B, 0 deleted\n", "output")
+
+	// the files uploaded are newer than the local files
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "sync", []string{src, ":/www"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "0 copied, 0 B, 0 deleted\n", "output")
+
+	// a change of the content is found with the checksum or the time
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "index.html"), []byte("<HTML>"), 0o644))
+	past := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(src, "index.html"), past, past))
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "sync", []string{src, ":/www"}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "0 copied, 0 B, 0 deleted\n", "output")
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "sync", []string{src, ":/www"}, false, true, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "copying index.html\n1 copied, 6 B, 0 deleted\n", "output")
+
+	assert.NoError(t, os.RemoveAll(filepath.Join(src, "css")))
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "sync", []string{src, ":/www"}, false, false, true, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "deleting css\n0 copied, 0 B, 1 deleted\n", "output")
+
+	// the files downloaded have the time of the volume
+	dst := filepath.Join(t.TempDir(), "www")
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "sync", []string{":/www", dst}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "copying index.html\n1 copied, 6 B, 0 deleted\n", "output")
+	data, err := os.ReadFile(filepath.Join(dst, "index.html"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), "<HTML>")
+	b.Reset()
+	err = cmdr.VolumesClient("foo", "myvolume", "sync", []string{":/www", dst}, false, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "0 copied, 0 B, 0 deleted\n", "output")
+}
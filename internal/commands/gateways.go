@@ -26,6 +26,11 @@ func (d *DryccCmd) GatewaysList(appID string, results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(gateways)
+	}
+
 	if count == 0 {
 		d.Println(fmt.Sprintf("No gateways found in %s app.", appID))
 	} else {
@@ -53,6 +58,10 @@ func (d *DryccCmd) GatewaysInfo(appID, name string) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(info)
+	}
+
 	c := &coder.GatewayCoder{Info: info}
 	yamlBytes, err := c.Encode()
 	if err != nil {
@@ -61,6 +61,10 @@ func (d *DryccCmd) HealthchecksList(appID, ptype string, version int) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(filterPtype(config.Healthcheck, ptype))
+	}
+
 	if ptype == "" {
 		if len(config.Healthcheck) == 0 {
 			d.Println("No health checks configured.")
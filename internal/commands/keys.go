@@ -30,6 +30,10 @@ func (d *DryccCmd) KeysList(results int) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(keys)
+	}
+
 	if len(keys) > 0 {
 		table := d.getDefaultFormatTable([]string{"ID", "KEY"})
 		for _, key := range keys {
@@ -21,6 +21,10 @@ func (d *DryccCmd) LabelsList(appID string) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(appSettings.Label)
+	}
+
 	if len(appSettings.Label) == 0 {
 		d.Println(fmt.Sprintf("No labels found in %s app.", appID))
 	} else {
@@ -40,6 +40,11 @@ func (d *DryccCmd) LifecyclesList(appID, ptype string, version int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(filterPtype(config.Lifecycle, ptype))
+	}
+
 	if len(config.Lifecycle) == 0 {
 		d.Println(fmt.Sprintf("No lifecycle found in %s app.", appID))
 		return nil
@@ -22,6 +22,11 @@ func (d *DryccCmd) LimitsList(appID string, version int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(config.Limits)
+	}
+
 	cached := make(map[string]api.LimitPlan)
 
 	if len(config.Limits) > 0 {
@@ -133,6 +138,11 @@ func (d *DryccCmd) LimitsSpecs(keywords string, results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(limitSpecs)
+	}
+
 	if count == 0 {
 		d.Println("Could not find any limit spec.")
 	} else {
@@ -170,6 +180,11 @@ func (d *DryccCmd) LimitsPlans(specID string, cpu, memory, results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(limitPlans)
+	}
+
 	if count == 0 {
 		d.Println("Could not find any limit spec.")
 	} else {
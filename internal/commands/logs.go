@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/ps"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/logging"
+)
+
+// logsPollInterval is the time between two lists of the pods of an app whose
+// logs are followed, to follow the new pods.
+var logsPollInterval = 5 * time.Second
+
+// logsWindow is how long the lines of the pods are held to be ordered by
+// time when following logs.
+const logsWindow = time.Second
+
+// AppLogs prints the logs of every pod of an app, merged and ordered by time,
+// formatted and written to sinks as set in options. The pods are filtered by
+// process type and release. When following, the pods started later are
+// followed too and the pods that stop are dropped.
+func (d *DryccCmd) AppLogs(appID string, lines int, follow bool, ptypes []string, release string, options logging.Options) error {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+	options.Origin = true
+	sink, err := options.NewSink(d.WOut)
+	if err != nil {
+		return err
+	}
+	window := time.Duration(0)
+	if follow {
+		window = logsWindow
+	}
+	merger := logging.NewMerger(sink, window)
+
+	// stop reading on Ctrl+C so that buffered records are flushed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = d.streamAppLogs(ctx, s.Client, appID, lines, follow, s.Retries, ptypes, release, merger)
+	if closeErr := merger.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// streamAppLogs writes the logs of the pods to sink, until ctx is done when
+// following.
+func (d *DryccCmd) streamAppLogs(ctx context.Context, c *drycc.Client, appID string, lines int, follow bool, retries int, ptypes []string, release string, sink logging.Sink) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	var mu sync.Mutex
+	// the pods streamed now, and those streamed before whose lines were read
+	streaming := make(map[string]bool)
+	streamed := make(map[string]bool)
+	for {
+		pods, err := listLogPods(c, appID, ptypes, release)
+		if err != nil && !follow {
+			return err
+		}
+		if err != nil {
+			mu.Lock()
+			d.PrintErrf("error: %v\n", err)
+			mu.Unlock()
+		}
+		if !follow && len(pods) == 0 {
+			return fmt.Errorf("no processes found in %s app", appID)
+		}
+
+		mu.Lock()
+		for _, pod := range pods {
+			if streaming[pod.Name] {
+				continue
+			}
+			// a pod streamed again after its container restarted only
+			// prints its new lines
+			n := lines
+			if streamed[pod.Name] {
+				n = 0
+			}
+			streaming[pod.Name] = true
+			streamed[pod.Name] = true
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := streamPodLogs(ctx, c, appID, pod.Name, n, follow, retries, sink, func(format string, args ...any) {
+					mu.Lock()
+					defer mu.Unlock()
+					d.PrintErrf(format, args...)
+				})
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil && ctx.Err() == nil {
+					d.PrintErrf("error: %s: %v\n", pod.Name, err)
+				}
+				delete(streaming, pod.Name)
+			}()
+		}
+		mu.Unlock()
+
+		if !follow {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logsPollInterval):
+		}
+	}
+}
+
+// listLogPods lists the pods of an app of the process types and the release,
+// when set.
+func listLogPods(c *drycc.Client, appID string, ptypes []string, release string) ([]api.Pods, error) {
+	pods, _, err := ps.List(c, appID, defaultLimit)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return nil, err
+	}
+	if release != "" && !strings.HasPrefix(release, "v") {
+		release = "v" + release
+	}
+	return slices.DeleteFunc(pods, func(pod api.Pods) bool {
+		return len(ptypes) > 0 && !slices.Contains(ptypes, pod.Type) || release != "" && pod.Release != release
+	}), nil
+}
+
+// streamPodLogs writes the lines of a pod to sink with their time, or the
+// time they are received when they have none. The lines following a line
+// with a time in a message, such as a stack trace, have its time. The errors
+// of the sink and the reconnections are passed to warn.
+func streamPodLogs(ctx context.Context, c *drycc.Client, appID, podID string, lines int, follow bool, retries int, sink logging.Sink, warn func(string, ...any)) error {
+	stream := logStream{
+		client: c, appID: appID, podID: podID, retries: retries, warn: warn,
+		request: api.PodLogsRequest{Lines: lines, Follow: follow},
+	}
+	return stream.run(ctx, func(lines []string) {
+		var last time.Time
+		for _, line := range lines {
+			t, ok := logging.ParseLine(line).Time()
+			switch {
+			case ok:
+				last = t
+			case !last.IsZero():
+				t = last
+			default:
+				t = time.Now()
+			}
+			rec := logging.Record{Time: t, App: appID, Pod: podID, Message: line}
+			if err := sink.Write(rec); err != nil {
+				warn("error: %v\n", err)
+			}
+		}
+	})
+}
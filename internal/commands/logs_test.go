@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/logging"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func handleLogPods(server *testutil.TestServer, pods ...string) {
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": %d, "next": null, "previous": null, "results": [%s]}`, len(pods), strings.Join(pods, ","))
+	})
+}
+
+func TestAppLogs(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	handleLogPods(server,
+		`{"release": "v2", "type": "web", "name": "foo-web-1", "state": "up"}`,
+		`{"release": "v2", "type": "worker", "name": "foo-worker-1", "state": "up"}`,
+		`{"release": "v1", "type": "web", "name": "foo-web-2", "state": "up"}`,
+	)
+	server.Mux.Handle(
+		"/v2/apps/foo/pods/foo-web-1/logs/",
+		websocket.Handler(func(conn *websocket.Conn) {
+			websocket.Message.Send(conn, `{"time":"2024-01-02T15:04:01Z","msg":"web 1"}`+"\n")
+			websocket.Message.Send(conn, `{"time":"2024-01-02T15:04:04Z","msg":"web 4"}`+"\n")
+			conn.WriteClose(100)
+		}),
+	)
+	server.Mux.Handle(
+		"/v2/apps/foo/pods/foo-worker-1/logs/",
+		websocket.Handler(func(conn *websocket.Conn) {
+			websocket.Message.Send(conn, "2024-01-02T15:04:02Z worker 2\n  at main.go:3\n")
+			websocket.Message.Send(conn, `{"time":"2024-01-02T15:04:05Z","msg":"worker 5"}`+"\n")
+			conn.WriteClose(100)
+		}),
+	)
+	server.Mux.Handle(
+		"/v2/apps/foo/pods/foo-web-2/logs/",
+		websocket.Handler(func(conn *websocket.Conn) {
+			websocket.Message.Send(conn, `{"time":"2024-01-02T15:04:03Z","msg":"web 3"}`+"\n")
+			conn.WriteClose(100)
+		}),
+	)
+
+	options := logging.Options{Format: "logfmt", Fields: []string{"pod", "msg"}}
+	err = cmdr.AppLogs("foo", 300, false, nil, "", options)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), `pod=foo-web-1 msg="web 1"
+pod=foo-worker-1 msg="2024-01-02T15:04:02Z worker 2"
+pod=foo-worker-1 msg="  at main.go:3"
+pod=foo-web-2 msg="web 3"
+pod=foo-web-1 msg="web 4"
+pod=foo-worker-1 msg="worker 5"
+`, "output")
+
+	b.Reset()
+	err = cmdr.AppLogs("foo", 300, false, []string{"web"}, "2", options)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), `pod=foo-web-1 msg="web 1"
+pod=foo-web-1 msg="web 4"
+`, "output")
+
+	b.Reset()
+	err = cmdr.AppLogs("foo", 300, false, nil, "v1", logging.Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), `foo-web-2 | {"time":"2024-01-02T15:04:03Z","msg":"web 3"}`)
+
+	err = cmdr.AppLogs("foo", 300, false, []string{"cron"}, "", options)
+	assert.EqualError(t, err, "no processes found in foo app")
+}
+
+func TestStreamAppLogsFollow(t *testing.T) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WErr: &b, ConfigFile: cf}
+	_, s, err := loader.LoadAppSettings(cf, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(interval time.Duration) { logsPollInterval = interval }(logsPollInterval)
+	logsPollInterval = 10 * time.Millisecond
+
+	// foo-web-1 is replaced by foo-web-2, which restarts once
+	var mu sync.Mutex
+	polls := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls++
+		testutil.SetHeaders(w)
+		switch {
+		case polls == 1:
+			fmt.Fprintf(w, `{"count": 1, "next": null, "previous": null, "results": [{"type": "web", "name": "foo-web-1"}]}`)
+		case polls < 20:
+			fmt.Fprintf(w, `{"count": 1, "next": null, "previous": null, "results": [{"type": "web", "name": "foo-web-2"}]}`)
+		default:
+			fmt.Fprintf(w, `{"count": 0, "next": null, "previous": null, "results": []}`)
+			cancel()
+		}
+	})
+	lines := make(map[string][]int)
+	for _, pod := range []string{"foo-web-1", "foo-web-2"} {
+		server.Mux.Handle(
+			"/v2/apps/foo/pods/"+pod+"/logs/",
+			websocket.Handler(func(conn *websocket.Conn) {
+				var request api.PodLogsRequest
+				websocket.JSON.Receive(conn, &request)
+				mu.Lock()
+				lines[pod] = append(lines[pod], request.Lines)
+				n := len(lines[pod])
+				mu.Unlock()
+				websocket.Message.Send(conn, fmt.Sprintf("%s %d\n", pod, n))
+				conn.WriteClose(100)
+			}),
+		)
+	}
+
+	var sink recordSink
+	err = cmdr.streamAppLogs(ctx, s.Client, "foo", 300, true, 0, nil, "", &sink)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "", "output")
+	assert.Equal(t, sink.records[:3], []string{"foo-web-1 1", "foo-web-2 1", "foo-web-2 2"}, "records")
+	assert.Equal(t, lines["foo-web-1"], []int{300})
+	assert.Equal(t, lines["foo-web-2"][:2], []int{300, 0})
+}
+
+type recordSink struct {
+	mu      sync.Mutex
+	records []string
+}
+
+func (s *recordSink) Write(rec logging.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec.Message)
+	return nil
+}
+
+func (s *recordSink) Close() error {
+	return nil
+}
@@ -0,0 +1,200 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/ps"
+	"github.com/drycc/workflow-cli/pkg/transport"
+	"golang.org/x/net/websocket"
+)
+
+// logStreamBackoff is the backoff between the connections of a log stream.
+var logStreamBackoff = transport.DefaultBackoff
+
+const (
+	// logResumeLines is the number of last lines of a log stream requested
+	// again when it reconnects, to find the lines it missed.
+	logResumeLines = 100
+	// logResumeWait is how long a log stream waits for the lines requested
+	// again.
+	logResumeWait = 2 * time.Second
+)
+
+// logStream reads the logs of a pod. When following, it reconnects after
+// the connection drops and resumes after the last lines received.
+type logStream struct {
+	client  *drycc.Client
+	appID   string
+	podID   string
+	request api.PodLogsRequest
+	retries int
+	// warn reports the connections retried.
+	warn func(format string, args ...any)
+
+	// recent are the last lines received.
+	recent []string
+}
+
+// run calls emit with the lines of each message of the logs, until the
+// stream ends or ctx is done.
+func (l *logStream) run(ctx context.Context, emit func([]string)) error {
+	request := l.request
+	var replay []string
+	for attempt := 0; ; attempt++ {
+		conn, err := ps.Logs(l.client, l.appID, l.podID, request)
+		if err == nil {
+			var received bool
+			received, err = l.read(ctx, conn, replay, emit)
+			conn.Close()
+			if ctx.Err() != nil {
+				return nil
+			}
+			// a dropped connection ends as the logs of a pod stopped
+			if err == nil && (!request.Follow || !l.podUp()) {
+				return nil
+			}
+			if err == nil {
+				err = errLogsClosed
+			}
+			// the lines received can not be resumed without following
+			if received && !request.Follow {
+				return err
+			}
+			if received {
+				attempt = 0
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if attempt >= l.retries {
+			return err
+		}
+		delay := logStreamBackoff.Delay(attempt)
+		l.warn("warning: logs of %s: %v, reconnecting in %s (%d/%d)\n", l.podID, err, delay.Round(100*time.Millisecond), attempt+1, l.retries)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		if request.Follow && len(l.recent) > 0 {
+			replay = slices.Clone(l.recent)
+			request.Lines = len(replay)
+		}
+	}
+}
+
+// errLogsClosed is the error of a log stream closed while its pod is up.
+var errLogsClosed = errors.New("connection closed")
+
+// podUp tells whether the pod of the logs is up.
+func (l *logStream) podUp() bool {
+	pods, _, err := ps.List(l.client, l.appID, defaultLimit)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return false
+	}
+	return slices.ContainsFunc(pods, func(pod api.Pods) bool {
+		return pod.Name == l.podID && pod.State == "up"
+	})
+}
+
+type logMessage struct {
+	text string
+	err  error
+}
+
+// read emits the messages of conn until it is closed, skipping the lines of
+// replay received again. It returns whether new lines were received and nil
+// when the stream ended.
+func (l *logStream) read(ctx context.Context, conn *websocket.Conn, replay []string, emit func([]string)) (bool, error) {
+	messages := make(chan logMessage)
+	go func() {
+		for {
+			var message logMessage
+			message.err = websocket.Message.Receive(conn, &message.text)
+			select {
+			case messages <- message:
+			case <-ctx.Done():
+				return
+			}
+			if message.err != nil {
+				return
+			}
+		}
+	}()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	received := false
+	var replayed []string
+	var timeout <-chan time.Time
+	if len(replay) > 0 {
+		timer := time.NewTimer(logResumeWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		var message logMessage
+		select {
+		case message = <-messages:
+		case <-timeout:
+			// fewer lines than requested were sent again
+			l.emit(resumeLines(replay, replayed), emit)
+			replay, timeout = nil, nil
+			continue
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+		if message.err == io.EOF {
+			if replay != nil {
+				l.emit(resumeLines(replay, replayed), emit)
+			}
+			return received, nil
+		}
+		if message.err != nil {
+			return received, message.err
+		}
+		received = true
+		lines := strings.Split(strings.TrimRight(message.text, "\n"), "\n")
+		if replay == nil {
+			l.emit(lines, emit)
+			continue
+		}
+		replayed = append(replayed, lines...)
+		if len(replayed) >= len(replay) {
+			l.emit(resumeLines(replay, replayed), emit)
+			replay, timeout = nil, nil
+		}
+	}
+}
+
+func (l *logStream) emit(lines []string, emit func([]string)) {
+	if len(lines) == 0 {
+		return
+	}
+	l.recent = append(l.recent, lines...)
+	if len(l.recent) > logResumeLines {
+		l.recent = slices.Clone(l.recent[len(l.recent)-logResumeLines:])
+	}
+	emit(lines)
+}
+
+// resumeLines returns the lines of replayed, the last lines of a log
+// received again, following recent, the last lines received before. The
+// lines missed while reconnecting follow the end of recent in replayed.
+func resumeLines(recent, replayed []string) []string {
+	for missed := 0; missed < len(recent); missed++ {
+		n := len(recent) - missed
+		if n <= len(replayed) && slices.Equal(recent[missed:], replayed[:n]) {
+			return replayed[n:]
+		}
+	}
+	return replayed
+}
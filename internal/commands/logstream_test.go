@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/drycc/workflow-cli/pkg/transport"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestResumeLines(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		recent, replayed, expected []string
+	}{
+		{[]string{"a", "b"}, []string{"a", "b", "c"}, []string{"c"}},
+		{[]string{"a", "b", "c"}, []string{"b", "c", "d", "e"}, []string{"d", "e"}},
+		{[]string{"a", "b"}, []string{"a", "b"}, []string{}},
+		{[]string{"a", "b"}, []string{"x", "y"}, []string{"x", "y"}},
+		{[]string{"a", "b", "a"}, []string{"a", "b", "a", "b"}, []string{"b"}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, resumeLines(test.recent, test.replayed), test.expected, strings.Join(test.recent, ","))
+	}
+}
+
+func TestLogStreamReconnect(t *testing.T) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	_, s, err := loader.LoadAppSettings(cf, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(backoff transport.Backoff) { logStreamBackoff = backoff }(logStreamBackoff)
+	logStreamBackoff = transport.Backoff{Min: time.Millisecond, Max: time.Millisecond}
+
+	// the first connection drops while foo-web-1 is up
+	var mu sync.Mutex
+	var requests []int
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		state := "up"
+		if len(requests) > 1 {
+			state = "down"
+		}
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 1, "next": null, "previous": null, "results": [{"type": "web", "name": "foo-web-1", "state": "%s"}]}`, state)
+	})
+	server.Mux.Handle("/v2/apps/foo/pods/foo-web-1/logs/", websocket.Handler(func(conn *websocket.Conn) {
+		var request api.PodLogsRequest
+		websocket.JSON.Receive(conn, &request)
+		mu.Lock()
+		requests = append(requests, request.Lines)
+		n := len(requests)
+		mu.Unlock()
+		if n == 1 {
+			websocket.Message.Send(conn, "a\nb\n")
+			return
+		}
+		websocket.Message.Send(conn, "a\nb\nc\n")
+		websocket.Message.Send(conn, "d\n")
+		conn.WriteClose(100)
+	}))
+
+	var warnings []string
+	stream := logStream{
+		client: s.Client, appID: "foo", podID: "foo-web-1", retries: 2,
+		request: api.PodLogsRequest{Lines: 300, Follow: true},
+		warn: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	}
+	var lines []string
+	err = stream.run(context.Background(), func(l []string) { lines = append(lines, l...) })
+	assert.NoError(t, err)
+	assert.Equal(t, lines, []string{"a", "b", "c", "d"}, "lines")
+	assert.Equal(t, requests, []int{300, 2}, "requests")
+	assert.Equal(t, warnings, []string{"warning: logs of foo-web-1: connection closed, reconnecting in 0s (1/2)\n"}, "warnings")
+}
@@ -0,0 +1,444 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/apps"
+	"github.com/drycc/controller-sdk-go/appsettings"
+	"github.com/drycc/controller-sdk-go/builds"
+	"github.com/drycc/controller-sdk-go/certs"
+	"github.com/drycc/controller-sdk-go/config"
+	"github.com/drycc/controller-sdk-go/domains"
+	"github.com/drycc/controller-sdk-go/gateways"
+	"github.com/drycc/controller-sdk-go/routes"
+	"github.com/drycc/controller-sdk-go/services"
+	"github.com/drycc/controller-sdk-go/volumes"
+	"github.com/drycc/workflow-cli/pkg/coder"
+	"github.com/drycc/workflow-cli/pkg/settings"
+)
+
+// manifestKinds lists the kinds of an app manifest in the order they are
+// applied: settings and config first so the first release built from the
+// manifest already carries them, network resources last since routes refer
+// to services and gateways.
+var manifestKinds = []string{
+	"App", "Autoscale", "Config", "Limits", "Timeouts", "Tags", "Healthcheck",
+	"Volume", "Build", "Service", "Domain", "Cert", "Gateway", "Route",
+}
+
+// manifestKind returns the kind used to order m, route kinds such as
+// HTTPRoute or TCPRoute are all reported as Route.
+func manifestKind(m coder.Manifest) string {
+	if strings.HasSuffix(m.Kind, "Route") {
+		return "Route"
+	}
+	return m.Kind
+}
+
+// sortManifests orders manifests by kind as listed in manifestKinds, keeping
+// the order of manifests of the same kind. Unknown kinds are reported.
+func sortManifests(manifests []coder.Manifest) error {
+	for _, m := range manifests {
+		if !slices.Contains(manifestKinds, manifestKind(m)) {
+			return fmt.Errorf("unsupported kind %s for %s", m.Kind, m.Metadata.Name)
+		}
+	}
+	slices.SortStableFunc(manifests, func(a, b coder.Manifest) int {
+		return slices.Index(manifestKinds, manifestKind(a)) - slices.Index(manifestKinds, manifestKind(b))
+	})
+	return nil
+}
+
+// exportManifests gathers the resources of an app into manifests.
+func (d *DryccCmd) exportManifests(s *settings.Settings, appID string) ([]coder.Manifest, error) {
+	appSettings, err := appsettings.List(s.Client, appID)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, err
+	}
+	appSettings.App = appID
+	manifests := []coder.Manifest{(&coder.AppCoder{Info: appSettings}).Manifest()}
+	for _, ptype := range slices.Sorted(maps.Keys(appSettings.Autoscale)) {
+		manifests = append(manifests, (&coder.AutoscaleCoder{Info: appSettings, Ptype: ptype}).Manifest())
+	}
+
+	release, err := d.releaseManifests(s, appID, -1)
+	if err != nil {
+		return nil, err
+	}
+	var build []coder.Manifest
+	for _, m := range release {
+		if m.Kind == "Build" {
+			build = append(build, m)
+		} else {
+			manifests = append(manifests, m)
+		}
+	}
+
+	vols, _, err := volumes.List(s.Client, appID, defaultLimit)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, err
+	}
+	for _, volume := range vols {
+		manifests = append(manifests, (&coder.VolumeCoder{Info: volume}).Manifest())
+	}
+
+	// the build goes after the volumes it may mount
+	manifests = append(manifests, build...)
+
+	svcs, err := services.List(s.Client, appID)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, err
+	}
+	for _, service := range svcs {
+		manifests = append(manifests, (&coder.ServiceCoder{Info: service}).Manifest())
+	}
+
+	doms, _, err := domains.List(s.Client, appID, defaultLimit)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, err
+	}
+	for _, domain := range doms {
+		manifests = append(manifests, (&coder.DomainCoder{Info: domain}).Manifest())
+	}
+
+	gws, _, err := gateways.List(s.Client, appID, defaultLimit)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, err
+	}
+	for _, gateway := range gws {
+		info, err := gateways.Info(s.Client, appID, gateway.Name)
+		if d.checkAPICompatibility(s.Client, err) != nil {
+			return nil, err
+		}
+		manifests = append(manifests, (&coder.GatewayCoder{Info: info}).Manifest())
+	}
+
+	rts, _, err := routes.List(s.Client, appID, defaultLimit)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, err
+	}
+	for _, route := range rts {
+		info, err := routes.Info(s.Client, appID, route.Name)
+		if d.checkAPICompatibility(s.Client, err) != nil {
+			return nil, err
+		}
+		manifests = append(manifests, (&coder.RouteCoder{Info: info}).Manifest())
+	}
+	return manifests, nil
+}
+
+// releaseManifests gathers the config and the build of a release of an app
+// into manifests. Version -1 is the latest release.
+func (d *DryccCmd) releaseManifests(s *settings.Settings, appID string, version int) ([]coder.Manifest, error) {
+	cfg, err := config.List(s.Client, appID, version)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return nil, err
+	}
+	cfg.App = appID
+	cfg.Values = sortConfigValues(cfg.Values)
+	manifests := []coder.Manifest{(&coder.ConfigCoder{Info: cfg}).Manifest()}
+	if len(cfg.Limits) > 0 {
+		manifests = append(manifests, (&coder.LimitsCoder{Info: cfg}).Manifest())
+	}
+	if len(cfg.Timeout) > 0 {
+		manifests = append(manifests, (&coder.TimeoutsCoder{Info: cfg}).Manifest())
+	}
+	if len(cfg.Tags) > 0 {
+		manifests = append(manifests, (&coder.TagsCoder{Info: cfg}).Manifest())
+	}
+	for _, ptype := range slices.Sorted(maps.Keys(cfg.Healthcheck)) {
+		manifests = append(manifests, (&coder.HealthcheckCoder{Info: cfg, Ptype: ptype}).Manifest())
+	}
+
+	build, err := builds.Get(s.Client, appID, version)
+	if err = d.checkAPICompatibility(s.Client, err); err != nil && !isNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		build.App = appID
+		manifests = append(manifests, (&coder.BuildCoder{Info: build}).Manifest())
+	}
+	return manifests, nil
+}
+
+// ensureApp creates the app in the default workspace unless it exists.
+func (d *DryccCmd) ensureApp(s *settings.Settings, appID string) error {
+	_, err := apps.Get(s.Client, appID)
+	if err = d.checkAPICompatibility(s.Client, err); err == nil || !isNotFound(err) {
+		return err
+	}
+	d.Printf("Creating app %s... ", appID)
+	quit := progress(d.WOut)
+	_, err = apps.New(s.Client, appID, s.Workspace)
+	quit <- true
+	<-quit
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+	d.Println("done")
+	return nil
+}
+
+// applyManifest creates or updates the resource described by m in an app.
+// Existing resources that are not mentioned by m are left untouched.
+func (d *DryccCmd) applyManifest(s *settings.Settings, appID string, m coder.Manifest) error {
+	data, err := m.JSON()
+	if err != nil {
+		return err
+	}
+
+	d.Printf("Applying %s %s... ", m.Kind, m.Metadata.Name)
+	quit := progress(d.WOut)
+	err = d.applyManifestData(s, appID, manifestKind(m), data)
+	quit <- true
+	<-quit
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		d.Println("failed")
+		return fmt.Errorf("%s %s: %w", m.Kind, m.Metadata.Name, err)
+	}
+	d.Println("done")
+	return nil
+}
+
+func (d *DryccCmd) applyManifestData(s *settings.Settings, appID, kind string, data []byte) error {
+	var err error
+	switch kind {
+	case "App":
+		c := &coder.AppCoder{}
+		if err = c.Decode(data); err == nil {
+			c.Request.App = ""
+			_, err = appsettings.Set(s.Client, appID, c.Request)
+		}
+	case "Autoscale":
+		c := &coder.AutoscaleCoder{}
+		if err = c.Decode(data); err == nil {
+			_, err = appsettings.Set(s.Client, appID, c.Request)
+		}
+	case "Config":
+		c := &coder.ConfigCoder{}
+		if err = c.Decode(data); err == nil && (len(c.Request.Values) > 0 || len(c.Request.ValuesRefs) > 0) {
+			_, err = config.Set(s.Client, appID, c.Request, true)
+		}
+	case "Limits":
+		c := &coder.LimitsCoder{}
+		if err = c.Decode(data); err == nil {
+			_, err = config.Set(s.Client, appID, c.Request, true)
+		}
+	case "Timeouts":
+		c := &coder.TimeoutsCoder{}
+		if err = c.Decode(data); err == nil {
+			_, err = config.Set(s.Client, appID, c.Request, true)
+		}
+	case "Tags":
+		c := &coder.TagsCoder{}
+		if err = c.Decode(data); err == nil {
+			_, err = config.Set(s.Client, appID, c.Request, true)
+		}
+	case "Healthcheck":
+		c := &coder.HealthcheckCoder{}
+		if err = c.Decode(data); err == nil {
+			_, err = config.Set(s.Client, appID, c.Request, true)
+		}
+	case "Volume":
+		c := &coder.VolumeCoder{}
+		if err = c.Decode(data); err == nil {
+			err = applyVolume(s.Client, appID, c.Request)
+		}
+	case "Build":
+		c := &coder.BuildCoder{}
+		if err = c.Decode(data); err == nil {
+			_, err = builds.New(s.Client, appID, c.Request.Image, c.Request.Stack, c.Request.Procfile, c.Request.Dryccfile)
+		}
+	case "Service":
+		c := &coder.ServiceCoder{}
+		if err = c.Decode(data); err == nil {
+			err = applyService(s.Client, appID, c.Request)
+		}
+	case "Domain":
+		c := &coder.DomainCoder{}
+		if err = c.Decode(data); err == nil {
+			err = applyDomain(s.Client, appID, c.Request)
+		}
+	case "Cert":
+		c := &coder.CertCoder{}
+		if err = c.Decode(data); err == nil {
+			err = applyCert(s.Client, appID, c)
+		}
+	case "Gateway":
+		c := &coder.GatewayCoder{}
+		if err = c.Decode(data); err == nil {
+			_, err = gateways.Apply(s.Client, appID, c.Request)
+		}
+	case "Route":
+		c := &coder.RouteCoder{}
+		if err = c.Decode(data); err == nil {
+			_, err = routes.Apply(s.Client, appID, c.Request)
+		}
+	default:
+		err = fmt.Errorf("unsupported kind %s", kind)
+	}
+	return err
+}
+
+// applyVolume creates a volume unless it exists, expands it when the size
+// differs, then mounts its paths.
+func applyVolume(c *drycc.Client, appID string, volume api.Volume) error {
+	path := volume.Path
+	volume.Path = nil
+	existing, err := volumes.Get(c, appID, volume.Name)
+	if err != nil {
+		if !isNotFound(err) {
+			return err
+		}
+		if _, err := volumes.Create(c, appID, volume); err != nil {
+			return err
+		}
+	} else if volume.Size != "" && volume.Size != existing.Size {
+		if _, err := volumes.Expand(c, appID, api.Volume{Name: volume.Name, Size: volume.Size}); err != nil {
+			return err
+		}
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	_, err = volumes.Mount(c, appID, volume.Name, api.Volume{Path: path})
+	return err
+}
+
+// applyService adds the ports of a service that do not exist yet.
+func applyService(c *drycc.Client, appID string, service api.Service) error {
+	existing, err := services.List(c, appID)
+	if err != nil && !errors.Is(err, drycc.ErrAPIMismatch) {
+		return err
+	}
+	for _, port := range service.Ports {
+		found := slices.ContainsFunc(existing, func(svc api.Service) bool {
+			return svc.Ptype == service.Ptype && slices.ContainsFunc(svc.Ports, func(p api.Port) bool {
+				return p.Port == port.Port && strings.EqualFold(p.Protocol, port.Protocol)
+			})
+		})
+		if found {
+			continue
+		}
+		if err := services.New(c, appID, service.Ptype, port.Port, port.Protocol, port.TargetPort); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDomain adds a domain unless the app already has it, a domain routed to
+// another process type is moved.
+func applyDomain(c *drycc.Client, appID string, domain api.DomainCreateRequest) error {
+	existing, _, err := domains.List(c, appID, defaultLimit)
+	if err != nil && !errors.Is(err, drycc.ErrAPIMismatch) {
+		return err
+	}
+	for _, d := range existing {
+		if d.Domain != domain.Domain {
+			continue
+		}
+		if domain.Ptype == "" || d.Ptype == domain.Ptype {
+			return nil
+		}
+		if err := domains.Delete(c, appID, d.Domain); err != nil {
+			return err
+		}
+	}
+	_, err = domains.New(c, appID, domain.Domain, domain.Ptype)
+	return err
+}
+
+// applyCert adds a cert, replacing an existing one with another certificate,
+// and attaches it to its domains.
+func applyCert(c *drycc.Client, appID string, cert *coder.CertCoder) error {
+	fingerprint, err := cert.Fingerprint()
+	if err != nil {
+		return err
+	}
+	existing, err := certs.Get(c, appID, cert.Request.Name)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err == nil && normalizeFingerprint(existing.Fingerprint) != normalizeFingerprint(fingerprint) {
+		if err := certs.Delete(c, appID, cert.Request.Name); err != nil {
+			return err
+		}
+		existing = api.Cert{}
+	}
+	if existing.Name == "" {
+		if existing, err = certs.New(c, appID, cert.Request.Certificate, cert.Request.Key, cert.Request.Name); err != nil {
+			return err
+		}
+	}
+	for _, domain := range cert.Domains {
+		if slices.Contains(existing.Domains, domain) {
+			continue
+		}
+		if err := certs.Attach(c, appID, cert.Request.Name, domain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteManifest removes the resource described by m from an app.
+func (d *DryccCmd) deleteManifest(s *settings.Settings, appID string, m coder.Manifest) error {
+	d.Printf("Destroying %s %s... ", m.Kind, m.Metadata.Name)
+	quit := progress(d.WOut)
+	err := deleteResource(s.Client, appID, m)
+	quit <- true
+	<-quit
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		d.Println("failed")
+		return fmt.Errorf("%s %s: %w", m.Kind, m.Metadata.Name, err)
+	}
+	d.Println("done")
+	return nil
+}
+
+func deleteResource(c *drycc.Client, appID string, m coder.Manifest) error {
+	name := m.Metadata.Name
+	switch manifestKind(m) {
+	case "Autoscale":
+		_, err := appsettings.Set(c, appID, api.AppSettings{Autoscale: map[string]*api.Autoscale{name: nil}})
+		return err
+	case "Healthcheck":
+		_, err := config.Set(c, appID, api.Config{Healthcheck: map[string]*api.Healthcheck{name: nil}}, true)
+		return err
+	case "Volume":
+		return volumes.Delete(c, appID, name)
+	case "Service":
+		service := &coder.ServiceCoder{}
+		data, err := m.JSON()
+		if err == nil {
+			err = service.Decode(data)
+		}
+		for _, port := range service.Request.Ports {
+			if err != nil {
+				break
+			}
+			err = services.Delete(c, appID, name, port.Protocol, port.Port)
+		}
+		return err
+	case "Domain":
+		return domains.Delete(c, appID, name)
+	case "Cert":
+		return certs.Delete(c, appID, name)
+	case "Gateway":
+		return gateways.Delete(c, appID, name)
+	case "Route":
+		return routes.Delete(c, appID, name)
+	}
+	return fmt.Errorf("%s resources cannot be destroyed", m.Kind)
+}
+
+func isNotFound(err error) bool {
+	var notFound drycc.ErrNotFound
+	return errors.As(err, &notFound)
+}
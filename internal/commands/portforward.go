@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/ps"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"golang.org/x/net/websocket"
+)
+
+// portPair is a local port forwarded to a port of a pod, a zero local port
+// is chosen by the system.
+type portPair struct {
+	Local  int
+	Remote int
+}
+
+// PsPortForward listens on local ports and forwards each connection to a
+// port of a pod, until interrupted.
+func (d *DryccCmd) PsPortForward(appID, podID string, ports []string, address string) error {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+	pairs, err := parsePortPairs(ports)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return d.forwardPorts(ctx, s.Client, appID, podID, pairs, address)
+}
+
+// forwardPorts forwards the ports until ctx is done.
+func (d *DryccCmd) forwardPorts(ctx context.Context, c *drycc.Client, appID, podID string, pairs []portPair, address string) error {
+	var listeners []net.Listener
+	defer func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}()
+	for _, pair := range pairs {
+		listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(pair.Local)))
+		if err != nil {
+			return err
+		}
+		listeners = append(listeners, listener)
+		d.Printf("Forwarding from %s -> %d\n", listener.Addr(), pair.Remote)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, listener := range listeners {
+		remote := pairs[i].Remote
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				local, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					mu.Lock()
+					d.Printf("Handling connection for %d\n", remote)
+					mu.Unlock()
+					if err := forwardConn(ctx, c, appID, podID, remote, local); err != nil {
+						mu.Lock()
+						d.PrintErrf("Error forwarding port %d: %v\n", remote, err)
+						mu.Unlock()
+					}
+				}()
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	for _, listener := range listeners {
+		listener.Close()
+	}
+	wg.Wait()
+	return nil
+}
+
+// forwardCommand connects the stdin and the stdout of an exec to a port of
+// the pod, with socat or nc as the controller has no port forward endpoint.
+// Once stdin ends, socat waits a minute rather than half a second for the
+// response before it exits.
+const forwardCommand = `command -v socat >/dev/null && exec socat -t 60 - TCP:127.0.0.1:%[1]d
+exec nc 127.0.0.1 %[1]d`
+
+// forwardConn copies the data of a local connection to a port of a pod and
+// back, through the stdin and the stdout of an exec of forwardCommand. When
+// the local side stops writing, only the stdin of the exec is closed, so that
+// the response still comes back until the exec ends.
+func forwardConn(ctx context.Context, c *drycc.Client, appID, podID string, port int, local net.Conn) error {
+	defer local.Close()
+	command := api.Command{Stdin: true, Command: []string{"sh", "-c", fmt.Sprintf(forwardCommand, port)}}
+	conn, err := ps.Exec(c, appID, podID, command)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// closing both ends stops the copies when the exec ends, the local side
+	// fails or on ctx
+	var closed atomic.Bool
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			closed.Store(true)
+		case <-done:
+		}
+		local.Close()
+		conn.Close()
+	}()
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := local.Read(buf)
+			if n > 0 {
+				if websocket.Message.Send(conn, append([]byte(stdinChannel), buf[:n]...)) != nil {
+					break
+				}
+			}
+			if err == io.EOF {
+				// an empty frame closes the stdin of the exec
+				websocket.Message.Send(conn, []byte(stdinChannel))
+				return
+			}
+			if err != nil {
+				break
+			}
+		}
+		closed.Store(true)
+		conn.Close()
+	}()
+
+	err = waitExec(conn, local)
+	if closed.Load() {
+		return nil
+	}
+	return err
+}
+
+// parsePortPairs parses ports such as 5432, 8000:80 or :80, where the local
+// port is chosen by the system.
+func parsePortPairs(ports []string) ([]portPair, error) {
+	var pairs []portPair
+	for _, port := range ports {
+		local, remote, found := strings.Cut(port, ":")
+		if !found {
+			remote = local
+		}
+		pair := portPair{}
+		var err error
+		if local != "" {
+			pair.Local, err = parsePort(local)
+		}
+		if err == nil {
+			pair.Remote, err = parsePort(remote)
+		}
+		if err != nil || pair.Remote == 0 {
+			return nil, fmt.Errorf("'%s' does not match the pattern '[local:]remote', ex: 5432 or 8000:80", port)
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+func parsePort(port string) (int, error) {
+	value, err := strconv.ParseUint(port, 10, 16)
+	return int(value), err
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestParsePortPairs(t *testing.T) {
+	t.Parallel()
+
+	pairs, err := parsePortPairs([]string{"5432", "8000:80", ":9090"})
+	assert.NoError(t, err)
+	assert.Equal(t, []portPair{{5432, 5432}, {8000, 80}, {0, 9090}}, pairs)
+
+	for _, port := range []string{"a", "80:", "0", "70000", "1:2:3"} {
+		_, err := parsePortPairs([]string{port})
+		assert.EqualError(t, err, "'"+port+"' does not match the pattern '[local:]remote', ex: 5432 or 8000:80", port)
+	}
+}
+
+func TestForwardPorts(t *testing.T) {
+	t.Parallel()
+	server := testutil.NewTestServer()
+	defer server.Close()
+	client, err := drycc.New(false, server.Server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Mux.Handle("/v2/apps/foo/pods/foo-web-111/exec/", websocket.Handler(func(conn *websocket.Conn) {
+		var command api.Command
+		if err := websocket.JSON.Receive(conn, &command); err != nil {
+			return
+		}
+		assert.True(t, command.Stdin, "stdin")
+		script := command.Command[len(command.Command)-1]
+		assert.Contains(t, script, "exec socat -t 60 - TCP:127.0.0.1:")
+		if strings.HasSuffix(script, "exec nc 127.0.0.1 80") {
+			websocket.Message.Send(conn, []byte(stderrChannel+"nc: 127.0.0.1 (127.0.0.1:80): Connection refused\n"))
+			websocket.Message.Send(conn, []byte(errorChannel+`{"status":"Failure","message":"command terminated with non-zero exit code: exit status 1",`+
+				`"reason":"NonZeroExitCode","details":{"causes":[{"reason":"ExitCode","message":"1"}]}}`))
+			return
+		}
+		// echo the data in upper case, and end once stdin is closed
+		for {
+			var data []byte
+			if err := websocket.Message.Receive(conn, &data); err != nil {
+				return
+			}
+			assert.Equal(t, stdinChannel, string(data[:1]))
+			if len(data) == 1 {
+				websocket.Message.Send(conn, []byte(stdoutChannel+"BYE\n"))
+				websocket.Message.Send(conn, []byte(errorChannel+`{"status":"Success"}`))
+				return
+			}
+			websocket.Message.Send(conn, append([]byte(stdoutChannel), bytes.ToUpper(data[1:])...))
+		}
+	}))
+
+	var out, errOut syncBuffer
+	cmdr := DryccCmd{WOut: &out, WErr: &errOut}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- cmdr.forwardPorts(ctx, client, "foo", "foo-web-111", []portPair{{0, 5432}, {0, 80}}, "127.0.0.1")
+	}()
+
+	var addrs []string
+	assert.Eventually(t, func() bool {
+		addrs = regexp.MustCompile(`Forwarding from (\S+) ->`).FindAllString(out.String(), -1)
+		return len(addrs) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	for i := range addrs {
+		addrs[i] = strings.TrimSuffix(strings.TrimPrefix(addrs[i], "Forwarding from "), " ->")
+	}
+
+	// concurrent connections
+	var wg sync.WaitGroup
+	for _, message := range []string{"hello\n", "world\n"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := net.Dial("tcp", addrs[0])
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+			conn.Write([]byte(message))
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			assert.NoError(t, err)
+			assert.Equal(t, strings.ToUpper(message), line)
+		}()
+	}
+	wg.Wait()
+
+	// the response still comes once the client stopped writing
+	conn, err := net.Dial("tcp", addrs[0])
+	assert.NoError(t, err)
+	conn.Write([]byte("hello\n"))
+	assert.NoError(t, conn.(*net.TCPConn).CloseWrite())
+	response, err := io.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, "HELLO\nBYE\n", string(response))
+	conn.Close()
+
+	conn, err = net.Dial("tcp", addrs[1])
+	assert.NoError(t, err)
+	_, err = bufio.NewReader(conn).ReadString('\n')
+	assert.Error(t, err)
+	conn.Close()
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Contains(t, out.String(), "Handling connection for 5432\n")
+	assert.Equal(t, "Error forwarding port 80: command terminated with non-zero exit code: exit status 1: nc: 127.0.0.1 (127.0.0.1:80): Connection refused\n", errOut.String())
+}
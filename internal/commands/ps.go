@@ -2,16 +2,19 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"os/signal"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/containerd/console"
+	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
 	"github.com/drycc/controller-sdk-go/events"
 	"github.com/drycc/controller-sdk-go/ps"
@@ -29,8 +32,9 @@ const (
 	resizeChannel = "\x04"
 )
 
-// PsList lists an app's processes.
-func (d *DryccCmd) PsList(appID string, results int) error {
+// PsList lists an app's processes, then watches them every interval when it
+// is not 0.
+func (d *DryccCmd) PsList(appID string, results int, interval time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -40,45 +44,73 @@ func (d *DryccCmd) PsList(appID string, results int) error {
 		results = s.Limit
 	}
 
+	if interval > 0 {
+		return d.watch(interval, func() (watchTable, error) {
+			processes, _, err := ps.List(s.Client, appID, results)
+			if d.checkAPICompatibility(s.Client, err) != nil {
+				return watchTable{}, err
+			}
+			return watchTable{
+				headers: processHeaders,
+				rows:    processRows(d, processes),
+				empty:   fmt.Sprintf("No processes found in %s app.", appID),
+				object:  processes,
+			}, nil
+		})
+	}
+
 	processes, _, err := ps.List(s.Client, appID, results)
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(processes)
+	}
+
 	printProcesses(d, appID, processes)
 
 	return nil
 }
 
-// PsLogs returns the logs from a pod.
-func (d *DryccCmd) PsLogs(appID, podID string, lines int, follow bool, container string, previous bool) error {
+// PsLogs returns the logs from a pod, formatted and written to sinks as set
+// in options.
+func (d *DryccCmd) PsLogs(appID, podID string, lines int, follow bool, container string, previous bool, options logging.Options) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
 	}
-	request := api.PodLogsRequest{
-		Lines:     lines,
-		Follow:    follow,
-		Container: container,
-		Previous:  previous,
-	}
-	conn, err := ps.Logs(s.Client, appID, podID, request)
+	sink, err := options.NewSink(d.WOut)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	for {
-		var message string
-		err := websocket.Message.Receive(conn, &message)
-		if err != nil {
-			if err != io.EOF {
-				log.Printf("error: %v", err)
+	stream := logStream{
+		client: s.Client, appID: appID, podID: podID, retries: s.Retries,
+		warn: func(format string, args ...any) { d.PrintErrf(format, args...) },
+		request: api.PodLogsRequest{
+			Lines:     lines,
+			Follow:    follow,
+			Container: container,
+			Previous:  previous,
+		},
+	}
+
+	// stop reading on Ctrl+C so that buffered records are flushed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = stream.run(ctx, func(lines []string) {
+		for _, line := range lines {
+			rec := logging.Record{Time: time.Now(), App: appID, Pod: podID, Container: container, Message: line}
+			if err := sink.Write(rec); err != nil {
+				d.PrintErrf("error: %v\n", err)
 			}
-			break
 		}
-		logging.PrintLog(os.Stdout, strings.TrimRight(string(message), "\n"))
+	})
+	if err != nil {
+		sink.Close()
+		return err
 	}
-	return nil
+	return sink.Close()
 }
 
 // PsExec executes a command in a pod.
@@ -99,14 +131,117 @@ func (d *DryccCmd) PsExec(appID, podID string, tty, stdin bool, command []string
 	defer conn.Close()
 	if stdin {
 		streamExec(conn, tty)
-	} else {
-		printExec(d, conn)
+		return nil
 	}
-	return nil
+	return runExec(conn, d.WOut, d.WErr)
+}
+
+// ExitError is returned by the commands failing with the exit code of a
+// remote command, the CLI exits with Code.
+type ExitError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExitError) Error() string {
+	return e.Message
+}
+
+// execStatus is the status sent on the error channel when an exec ends.
+type execStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+	Details struct {
+		Causes []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"causes"`
+	} `json:"details"`
+}
+
+// runExec writes the stdout and the stderr of a non-interactive exec to
+// stdout and stderr until it ends. It returns an ExitError when the command
+// fails.
+func runExec(conn *websocket.Conn, stdout, stderr io.Writer) error {
+	for {
+		var data []byte
+		err := websocket.Message.Receive(conn, &data)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		switch string(data[:1]) {
+		case stdoutChannel:
+			if _, err := stdout.Write(data[1:]); err != nil {
+				return err
+			}
+		case stderrChannel:
+			if _, err := stderr.Write(data[1:]); err != nil {
+				return err
+			}
+		case errorChannel:
+			if err := execError(data[1:]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// waitExec writes the stdout of a non-interactive exec to stdout until it
+// ends. The ExitError of a failed command holds its stderr.
+func waitExec(conn *websocket.Conn, stdout io.Writer) error {
+	var stderr strings.Builder
+	err := runExec(conn, stdout, &stderr)
+	text := strings.TrimSpace(stderr.String())
+	if err == nil || text == "" {
+		return err
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitErr.Code, Message: exitErr.Message + ": " + text}
+	}
+	return fmt.Errorf("%w: %s", err, text)
+}
+
+// execError returns the failure of an exec from its status, nil when it
+// succeeded.
+func execError(data []byte) error {
+	var status execStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		if message := strings.TrimSpace(string(data)); message != "" {
+			return &ExitError{Code: 1, Message: message}
+		}
+		return nil
+	}
+	if status.Status == "" || status.Status == "Success" {
+		return nil
+	}
+	code := 1
+	if status.Reason == "NonZeroExitCode" {
+		for _, cause := range status.Details.Causes {
+			if n, err := strconv.Atoi(cause.Message); err == nil && cause.Reason == "ExitCode" && n > 0 {
+				code = n
+			}
+		}
+	}
+	return &ExitError{Code: code, Message: status.Message}
+}
+
+// podDescription is the machine-readable form of the ps:describe output.
+type podDescription struct {
+	Containers api.PodState  `json:"containers"`
+	Events     api.AppEvents `json:"events"`
 }
 
-// PsDescribe describe an app's processes.
-func (d *DryccCmd) PsDescribe(appID, podID string) error {
+// PsDescribe describe an app's processes, then watches its new events every
+// interval when it is not 0.
+func (d *DryccCmd) PsDescribe(appID, podID string, interval time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -116,6 +251,18 @@ func (d *DryccCmd) PsDescribe(appID, podID string) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+	events, _, err := events.ListPodEvents(s.Client, appID, podID, 1000)
+	if err != nil {
+		return err
+	}
+
+	if d.Output != "" {
+		if err := d.printObject(podDescription{Containers: podState, Events: events}); err != nil || interval == 0 {
+			return err
+		}
+		return d.watchPodEvents(s.Client, appID, podID, interval, events)
+	}
+
 	table := d.getDefaultFormatTable([]string{})
 	for _, containerState := range podState {
 		table.Append([]string{"Container:", containerState.Container})
@@ -176,10 +323,6 @@ func (d *DryccCmd) PsDescribe(appID, podID string) error {
 	}
 	table.Render()
 	// display events
-	events, _, err := events.ListPodEvents(s.Client, appID, podID, 1000)
-	if err != nil {
-		return err
-	}
 	if len(events) != 0 {
 		// table event
 		te := d.getDefaultFormatTable([]string{})
@@ -194,7 +337,17 @@ func (d *DryccCmd) PsDescribe(appID, podID string) error {
 		}
 		te.Render()
 	}
-	return nil
+	if interval == 0 {
+		return nil
+	}
+	return d.watchPodEvents(s.Client, appID, podID, interval, events)
+}
+
+func (d *DryccCmd) watchPodEvents(c *drycc.Client, appID, podID string, interval time.Duration, seen api.AppEvents) error {
+	return d.watchEvents(interval, seen, func() (api.AppEvents, error) {
+		events, _, err := events.ListPodEvents(c, appID, podID, 1000)
+		return events, err
+	})
 }
 
 // PsDelete delete an pod.
@@ -218,47 +371,39 @@ func (d *DryccCmd) PsDelete(appID string, podIDs []string) error {
 	return nil
 }
 
+var processHeaders = []string{"NAME", "RELEASE", "STATE", "PTYPE", "READY", "RESTARTS", "STARTED"}
+
 func printProcesses(d *DryccCmd, appID string, input []api.Pods) {
-	processes := ps.ByType(input)
+	rows := processRows(d, input)
 
-	if len(processes) == 0 {
+	if len(rows) == 0 {
 		d.Println(fmt.Sprintf("No processes found in %s app.", appID))
 	} else {
-		table := d.getDefaultFormatTable([]string{"NAME", "RELEASE", "STATE", "PTYPE", "READY", "RESTARTS", "STARTED"})
-		for _, process := range processes {
-			for _, pod := range process.PodsList {
-				table.Append([]string{
-					pod.Name,
-					pod.Release,
-					pod.State,
-					pod.Type,
-					pod.Ready,
-					fmt.Sprintf("%v", pod.Restarts),
-					d.formatTime(pod.Started),
-				})
-			}
-		}
+		table := d.getDefaultFormatTable(processHeaders)
+		table.AppendBulk(rows)
 		table.Render()
 	}
 }
 
-func printExec(d *DryccCmd, conn *websocket.Conn) error {
-	var data string
-	err := websocket.Message.Receive(conn, &data)
-	if err != nil {
-		if err != io.EOF {
-			log.Printf("error: %v", err)
+func processRows(d *DryccCmd, input []api.Pods) [][]string {
+	var rows [][]string
+	for _, process := range ps.ByType(input) {
+		for _, pod := range process.PodsList {
+			rows = append(rows, []string{
+				pod.Name,
+				pod.Release,
+				pod.State,
+				pod.Type,
+				pod.Ready,
+				fmt.Sprintf("%v", pod.Restarts),
+				d.formatTime(pod.Started),
+			})
 		}
-		return nil
 	}
-	message, err := parseChannelMessage(data)
-	if err == nil {
-		d.Printf("%s", message)
-	}
-	return err
+	return rows
 }
 
-func runRecvTask(conn *websocket.Conn, c console.Console, recvChan, sendChan chan string) (context.Context, context.CancelFunc) {
+func runRecvTask(conn *websocket.Conn, in io.Reader, recvChan, sendChan chan string) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		for {
@@ -273,20 +418,28 @@ func runRecvTask(conn *websocket.Conn, c console.Console, recvChan, sendChan cha
 				cancel()
 				break
 			}
-			recvChan <- message
+			select {
+			case recvChan <- message:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 	go func() {
 		buf := make([]byte, 1024)
 		for {
-			size, err := c.Read(buf)
+			size, err := in.Read(buf)
 			if err == io.EOF {
 				cancel()
 				break
 			} else if err != nil {
 				continue
 			}
-			sendChan <- string(buf[:size])
+			select {
+			case sendChan <- string(buf[:size]):
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 	return ctx, cancel
@@ -319,11 +472,15 @@ func streamExec(conn *websocket.Conn, tty bool) error {
 		}
 		runResizeTask(conn, c)
 	}
+	return pipeExec(conn, c, c)
+}
+
+// pipeExec sends in to the stdin of an exec and writes its output to out,
+// until the exec or in ends.
+func pipeExec(conn *websocket.Conn, in io.Reader, out io.Writer) error {
 	recvChan, sendChan := make(chan string, 10), make(chan string, 10)
-	ctx, cancel := runRecvTask(conn, c, recvChan, sendChan)
+	ctx, cancel := runRecvTask(conn, in, recvChan, sendChan)
 	defer cancel()
-	defer close(recvChan)
-	defer close(sendChan)
 	for {
 		select {
 		case <-ctx.Done():
@@ -333,7 +490,7 @@ func streamExec(conn *websocket.Conn, tty bool) error {
 				return err
 			}
 		case message := <-recvChan:
-			c.Write([]byte(message))
+			out.Write([]byte(message))
 		}
 	}
 }
@@ -3,11 +3,13 @@ package commands
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/pkg/logging"
 	"github.com/drycc/workflow-cli/pkg/testutil"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/websocket"
@@ -79,7 +81,7 @@ func TestPsList(t *testing.T) {
 		}`)
 	})
 
-	err = cmdr.PsList("foo", -1)
+	err = cmdr.PsList("foo", -1, 0)
 	assert.NoError(t, err)
 
 	assert.Equal(t, b.String(), `NAME                        RELEASE    STATE    PTYPE    READY    RESTARTS    STARTED             
@@ -94,16 +96,62 @@ func TestPsExec(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer server.Close()
-	var b bytes.Buffer
-	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	var stdout, stderr bytes.Buffer
+	cmdr := DryccCmd{WOut: &stdout, WErr: &stderr, ConfigFile: cf}
 	server.Mux.Handle(
 		"/v2/apps/foo/pods/foo-web-111/exec/",
 		websocket.Handler(func(conn *websocket.Conn) {
-			io.Copy(conn, conn)
+			var command string
+			websocket.Message.Receive(conn, &command)
+			websocket.Message.Send(conn, []byte("\x01hello "))
+			websocket.Message.Send(conn, []byte("\x02warning\n"))
+			websocket.Message.Send(conn, []byte("\x01world\n"))
+			websocket.Message.Send(conn, []byte("\x03"+`{"metadata":{},"status":"Success"}`))
+			conn.Close()
 		}),
 	)
-	err = cmdr.PsExec("foo", "foo-web-111", true, false, []string{"/bin/sh"})
+	err = cmdr.PsExec("foo", "foo-web-111", false, false, []string{"/bin/sh"})
 	assert.NoError(t, err)
+	assert.Equal(t, stdout.String(), "hello world\n", "stdout")
+	assert.Equal(t, stderr.String(), "warning\n", "stderr")
+}
+
+func TestPsExecExitCode(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WErr: &b, ConfigFile: cf}
+	server.Mux.Handle(
+		"/v2/apps/foo/pods/foo-web-111/exec/",
+		websocket.Handler(func(conn *websocket.Conn) {
+			var command string
+			websocket.Message.Receive(conn, &command)
+			websocket.Message.Send(conn, []byte("\x02ls: /missing: No such file or directory\n"))
+			websocket.Message.Send(conn, []byte("\x03"+`{"metadata":{},"status":"Failure",`+
+				`"message":"command terminated with non-zero exit code: exit status 2","reason":"NonZeroExitCode",`+
+				`"details":{"causes":[{"reason":"ExitCode","message":"2"}]}}`))
+			conn.Close()
+		}),
+	)
+	err = cmdr.PsExec("foo", "foo-web-111", false, false, []string{"ls", "/missing"})
+	var exitErr *ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, exitErr.Code, 2, "exit code")
+	assert.Equal(t, b.String(), "ls: /missing: No such file or directory\n", "output")
+}
+
+func TestExecError(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, execError([]byte(`{"status":"Success"}`)))
+	assert.NoError(t, execError(nil))
+	assert.Equal(t, execError([]byte(`{"status":"Failure","message":"pod not found","reason":"NotFound"}`)),
+		&ExitError{Code: 1, Message: "pod not found"}, "failure")
+	assert.Equal(t, execError([]byte("container not running")),
+		&ExitError{Code: 1, Message: "container not running"}, "raw")
 }
 
 func TestPsLogs(t *testing.T) {
@@ -121,8 +169,60 @@ func TestPsLogs(t *testing.T) {
 			conn.WriteClose(100)
 		}),
 	)
-	err = cmdr.PsLogs("foo", "foo-web-111", 300, true, "runner", false)
+	err = cmdr.PsLogs("foo", "foo-web-111", 300, true, "runner", false, logging.Options{})
+	assert.NoError(t, err)
+}
+
+func TestPsLogsSink(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	server.Mux.Handle(
+		"/v2/apps/foo/pods/foo-web-111/logs/",
+		websocket.Handler(func(conn *websocket.Conn) {
+			websocket.Message.Send(conn, "hello\n")
+			websocket.Message.Send(conn, "world\n")
+			conn.WriteClose(100)
+		}),
+	)
+	path := filepath.Join(t.TempDir(), "foo.log")
+	err = cmdr.PsLogs("foo", "foo-web-111", 300, true, "runner", false, logging.Options{Sinks: []string{"file://" + path}})
+	assert.NoError(t, err)
+	assert.Equal(t, "", b.String())
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\nworld\n", string(data))
+
+	err = cmdr.PsLogs("foo", "foo-web-111", 300, true, "runner", false, logging.Options{Sinks: []string{"bad://"}})
+	assert.EqualError(t, err, `invalid sink bad://: unsupported scheme "bad", use stdout, file, syslog or otlp`)
+}
+
+func TestPsLogsFormat(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+	server.Mux.Handle(
+		"/v2/apps/foo/pods/foo-web-111/logs/",
+		websocket.Handler(func(conn *websocket.Conn) {
+			websocket.Message.Send(conn, `{"level":"info","msg":"started"}`+"\n")
+			websocket.Message.Send(conn, `level=error msg="query failed" ms=12`+"\n")
+			conn.WriteClose(100)
+		}),
+	)
+	options := logging.Options{Format: "json", Fields: []string{"pod", "level", "msg"}, Filters: []string{"level>=warn"}}
+	err = cmdr.PsLogs("foo", "foo-web-111", 300, false, "", false, options)
 	assert.NoError(t, err)
+	assert.Equal(t, `{"pod":"foo-web-111","level":"error","msg":"query failed"}`+"\n", b.String())
 }
 
 type psTargetCases struct {
@@ -213,7 +313,7 @@ func TestPsDescribe(t *testing.T) {
 			}]
 		}`)
 	})
-	err = cmdr.PsDescribe("foo", "foo-web-111")
+	err = cmdr.PsDescribe("foo", "foo-web-111", 0)
 	assert.NoError(t, err)
 }
 
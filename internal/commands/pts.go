@@ -2,19 +2,23 @@ package commands
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
 	"github.com/drycc/controller-sdk-go/events"
 	"github.com/drycc/controller-sdk-go/pts"
 	"github.com/drycc/workflow-cli/internal/loader"
 )
 
-// PtsList lists an app's processes.
-func (d *DryccCmd) PtsList(appID string, results int) error {
+// PtsList lists an app's processes, then watches them every interval when it
+// is not 0.
+func (d *DryccCmd) PtsList(appID string, results int, interval time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -24,18 +28,44 @@ func (d *DryccCmd) PtsList(appID string, results int) error {
 		results = s.Limit
 	}
 
+	if interval > 0 {
+		return d.watch(interval, func() (watchTable, error) {
+			ptypes, _, err := pts.List(s.Client, appID, results)
+			if d.checkAPICompatibility(s.Client, err) != nil {
+				return watchTable{}, err
+			}
+			return watchTable{
+				headers: processTypeHeaders,
+				rows:    processTypeRows(d, ptypes),
+				empty:   fmt.Sprintf("No processes found in %s app.", appID),
+				object:  ptypes,
+			}, nil
+		})
+	}
+
 	ptypes, _, err := pts.List(s.Client, appID, results)
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(ptypes)
+	}
+
 	printProcessTypes(d, appID, ptypes)
 
 	return nil
 }
 
-// PtsDescribe describe an app's processes.
-func (d *DryccCmd) PtsDescribe(appID, ptype string) error {
+// ptypeDescription is the machine-readable form of the pts:describe output.
+type ptypeDescription struct {
+	States api.PtypeStates `json:"states"`
+	Events api.AppEvents   `json:"events"`
+}
+
+// PtsDescribe describe an app's processes, then watches its new events every
+// interval when it is not 0.
+func (d *DryccCmd) PtsDescribe(appID, ptype string, interval time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -49,12 +79,28 @@ func (d *DryccCmd) PtsDescribe(appID, ptype string) error {
 	if err != nil {
 		return err
 	}
-	printProcessTypeDetail(d, ptypeStates, events)
-	return nil
+
+	if d.Output != "" {
+		err = d.printObject(ptypeDescription{States: ptypeStates, Events: events})
+	} else {
+		printProcessTypeDetail(d, ptypeStates, events)
+	}
+	if err != nil || interval == 0 {
+		return err
+	}
+	return d.watchPtypeEvents(s.Client, appID, ptype, interval, events)
 }
 
-// PtsScale scales an app's processes.
-func (d *DryccCmd) PtsScale(appID string, targets []string) error {
+func (d *DryccCmd) watchPtypeEvents(c *drycc.Client, appID, ptype string, interval time.Duration, seen api.AppEvents) error {
+	return d.watchEvents(interval, seen, func() (api.AppEvents, error) {
+		events, _, err := events.ListPtypeEvents(c, appID, ptype, 1000)
+		return events, err
+	})
+}
+
+// PtsScale scales an app's processes, then waits for the rollout until
+// timeout when it is not 0.
+func (d *DryccCmd) PtsScale(appID string, targets []string, timeout time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -77,12 +123,15 @@ func (d *DryccCmd) PtsScale(appID string, targets []string) error {
 	}
 
 	d.Printf("done in %ds\n", int(time.Since(startTime).Seconds()))
-
-	return nil
+	if timeout == 0 {
+		return nil
+	}
+	return d.waitRollout(s.Client, appID, slices.Sorted(maps.Keys(targetMap)), 0, startTime, timeout)
 }
 
-// PtsRestart restarts an app's processes.
-func (d *DryccCmd) PtsRestart(appID string, targets []string, confirm string) error {
+// PtsRestart restarts an app's processes, then waits for the rollout until
+// timeout when it is not 0.
+func (d *DryccCmd) PtsRestart(appID string, targets []string, confirm string, timeout time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -114,7 +163,10 @@ func (d *DryccCmd) PtsRestart(appID string, targets []string, confirm string) er
 	}
 
 	d.Printf("done in %ds\n", int(time.Since(startTime).Seconds()))
-	return nil
+	if timeout == 0 {
+		return nil
+	}
+	return d.waitRollout(s.Client, appID, slices.DeleteFunc(slices.Clone(targets), func(ptype string) bool { return ptype == "" }), 0, startTime, timeout)
 }
 
 // PtsClean cleans process types that are not used.
@@ -141,28 +193,36 @@ func (d *DryccCmd) PtsClean(appID string, targets []string) error {
 	return nil
 }
 
+var processTypeHeaders = []string{"NAME", "RELEASE", "READY", "UP-TO-DATE", "AVAILABLE", "STARTED", "GARBAGE"}
+
 func printProcessTypes(d *DryccCmd, appID string, ptypes api.Ptypes) {
-	pts := pts.ByType(ptypes)
+	rows := processTypeRows(d, ptypes)
 
-	if len(pts) == 0 {
+	if len(rows) == 0 {
 		d.Println(fmt.Sprintf("No processes found in %s app.", appID))
 	} else {
-		table := d.getDefaultFormatTable([]string{"NAME", "RELEASE", "READY", "UP-TO-DATE", "AVAILABLE", "STARTED", "GARBAGE"})
-		for _, pt := range pts {
-			table.Append([]string{
-				pt.Name,
-				pt.Release,
-				pt.Ready,
-				fmt.Sprintf("%v", pt.UpToDate),
-				fmt.Sprintf("%v", pt.AvailableReplicas),
-				d.formatTime(pt.Started),
-				fmt.Sprintf("%t", pt.Garbage),
-			})
-		}
+		table := d.getDefaultFormatTable(processTypeHeaders)
+		table.AppendBulk(rows)
 		table.Render()
 	}
 }
 
+func processTypeRows(d *DryccCmd, ptypes api.Ptypes) [][]string {
+	var rows [][]string
+	for _, pt := range pts.ByType(ptypes) {
+		rows = append(rows, []string{
+			pt.Name,
+			pt.Release,
+			pt.Ready,
+			fmt.Sprintf("%v", pt.UpToDate),
+			fmt.Sprintf("%v", pt.AvailableReplicas),
+			d.formatTime(pt.Started),
+			fmt.Sprintf("%t", pt.Garbage),
+		})
+	}
+	return rows
+}
+
 func printProcessTypeDetail(d *DryccCmd, ptypeStates api.PtypeStates, events api.AppEvents) {
 	// table process type
 	tpt := d.getDefaultFormatTable([]string{})
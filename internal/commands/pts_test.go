@@ -77,7 +77,7 @@ func TestPtsList(t *testing.T) {
 		}`)
 	})
 
-	err = cmdr.PtsList("foo", -1)
+	err = cmdr.PtsList("foo", -1, 0)
 	assert.NoError(t, err)
 
 	assert.Equal(t, b.String(), `NAME    RELEASE    READY    UP-TO-DATE    AVAILABLE    STARTED                GARBAGE 
@@ -125,7 +125,7 @@ func TestPtsScale(t *testing.T) {
 	defer server.Close()
 	var b bytes.Buffer
 	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
-	err = cmdr.PtsScale("foo", []string{"test"})
+	err = cmdr.PtsScale("foo", []string{"test"}, 0)
 	assert.Equal(t, err.Error(), "'test' does not match the pattern 'ptype=num', ex: web=2", "error")
 
 	server.Mux.HandleFunc("/v2/apps/foo/ptypes/scale/", func(w http.ResponseWriter, r *http.Request) {
@@ -135,7 +135,7 @@ func TestPtsScale(t *testing.T) {
 	})
 
 	b.Reset()
-	err = cmdr.PtsScale("foo", []string{"web=1"})
+	err = cmdr.PtsScale("foo", []string{"web=1"}, 0)
 	assert.NoError(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), `Scaling process types... but first, coffee!
 done in 0s
@@ -159,7 +159,7 @@ func TestPtsRestart(t *testing.T) {
 	})
 
 	b.Reset()
-	err = cmdr.PtsRestart("foo", []string{""}, "yes")
+	err = cmdr.PtsRestart("foo", []string{""}, "yes", 0)
 	assert.NoError(t, err)
 
 	server.Mux.HandleFunc("/v2/apps/coolapp/ptypes/restart/", func(w http.ResponseWriter, r *http.Request) {
@@ -170,7 +170,7 @@ func TestPtsRestart(t *testing.T) {
 
 	b.Reset()
 
-	err = cmdr.PtsRestart("coolapp", []string{"web"}, "")
+	err = cmdr.PtsRestart("coolapp", []string{"web"}, "", 0)
 	assert.NoError(t, err)
 
 	server.Mux.HandleFunc("/v2/apps/testapp/ptypes/restart/", func(w http.ResponseWriter, r *http.Request) {
@@ -181,7 +181,7 @@ func TestPtsRestart(t *testing.T) {
 
 	b.Reset()
 
-	err = cmdr.PtsRestart("testapp", []string{"web", "worker"}, "")
+	err = cmdr.PtsRestart("testapp", []string{"web", "worker"}, "", 0)
 	assert.NoError(t, err)
 }
 
@@ -241,7 +241,7 @@ func TestPtsDescribe(t *testing.T) {
             }]
         }`)
 	})
-	err = cmdr.PtsDescribe("foo", "web")
+	err = cmdr.PtsDescribe("foo", "web", 0)
 	assert.NoError(t, err)
 }
 
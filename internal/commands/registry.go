@@ -19,6 +19,11 @@ func (d *DryccCmd) RegistryList(appID, ptype string, version int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(filterPtype(config.Registry, ptype))
+	}
+
 	if len(config.Registry) == 0 {
 		d.Println(fmt.Sprintf("No registrys found in %s app.", appID))
 		return nil
@@ -2,15 +2,18 @@ package commands
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/drycc/controller-sdk-go/api"
 	"github.com/drycc/controller-sdk-go/releases"
 	"github.com/drycc/workflow-cli/internal/loader"
 )
 
-// ReleasesList lists an app's releases.
-func (d *DryccCmd) ReleasesList(appID string, targets []string, results int) error {
+// ReleasesList lists an app's releases, then watches them every interval
+// when it is not 0.
+func (d *DryccCmd) ReleasesList(appID string, targets []string, results int, interval time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -20,26 +23,56 @@ func (d *DryccCmd) ReleasesList(appID string, targets []string, results int) err
 		results = s.Limit
 	}
 	ptypes := strings.Join(targets, ",")
+
+	if interval > 0 {
+		return d.watch(interval, func() (watchTable, error) {
+			releases, _, err := releases.List(s.Client, appID, ptypes, results)
+			if d.checkAPICompatibility(s.Client, err) != nil {
+				return watchTable{}, err
+			}
+			return watchTable{
+				headers: releaseHeaders,
+				rows:    releaseRows(d, releases),
+				key:     1,
+				empty:   fmt.Sprintf("No releases found in %s app.", appID),
+				object:  releases,
+			}, nil
+		})
+	}
+
 	releases, count, err := releases.List(s.Client, appID, ptypes, results)
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(releases)
+	}
+
 	if count == 0 {
 		d.Println(fmt.Sprintf("No releases found in %s app.", appID))
 	} else {
-		table := d.getDefaultFormatTable([]string{"STATE", "VERSION", "CREATED", "SUMMARY"})
-		for _, r := range releases {
-			summary := r.Summary
-			if len(summary) > 64 {
-				summary = fmt.Sprintf("%s[...]", summary[:64])
-			}
-			table.Append([]string{r.State, fmt.Sprintf("v%d", r.Version), d.formatTime(r.Created), summary})
-		}
+		table := d.getDefaultFormatTable(releaseHeaders)
+		table.AppendBulk(releaseRows(d, releases))
 		table.Render()
 	}
 	return nil
 }
 
+var releaseHeaders = []string{"STATE", "VERSION", "CREATED", "SUMMARY"}
+
+func releaseRows(d *DryccCmd, releases []api.Release) [][]string {
+	var rows [][]string
+	for _, r := range releases {
+		summary := r.Summary
+		if len(summary) > 64 {
+			summary = fmt.Sprintf("%s[...]", summary[:64])
+		}
+		rows = append(rows, []string{r.State, fmt.Sprintf("v%d", r.Version), d.formatTime(r.Created), summary})
+	}
+	return rows
+}
+
 // ReleasesInfo prints info about a specific release.
 func (d *DryccCmd) ReleasesInfo(appID string, version int) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
@@ -51,6 +84,11 @@ func (d *DryccCmd) ReleasesInfo(appID string, version int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(r)
+	}
+
 	table := d.getDefaultFormatTable([]string{})
 	table.Append([]string{"App:", r.App})
 	table.Append([]string{"UUID:", r.UUID})
@@ -90,8 +128,9 @@ func (d *DryccCmd) ReleasesInfo(appID string, version int) error {
 	return nil
 }
 
-// ReleasesDeploy force deploy lastest release.
-func (d *DryccCmd) ReleasesDeploy(appID string, targets []string, force bool, confirm string) error {
+// ReleasesDeploy force deploy lastest release, then waits for the rollout
+// until timeout when it is not 0.
+func (d *DryccCmd) ReleasesDeploy(appID string, targets []string, force bool, confirm string, timeout time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -124,11 +163,23 @@ func (d *DryccCmd) ReleasesDeploy(appID string, targets []string, force bool, co
 	}
 
 	d.Printf("done in %ds\n", int(time.Since(startTime).Seconds()))
-	return nil
+	if timeout == 0 {
+		return nil
+	}
+	latest, _, err := releases.List(s.Client, appID, "", 1)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+	version := 0
+	if len(latest) > 0 {
+		version = latest[0].Version
+	}
+	return d.waitRollout(s.Client, appID, slices.DeleteFunc(slices.Clone(targets), func(ptype string) bool { return ptype == "" }), version, startTime, timeout)
 }
 
-// ReleasesRollback rolls an app back to a previous release.
-func (d *DryccCmd) ReleasesRollback(appID string, targets []string, version int) error {
+// ReleasesRollback rolls an app back to a previous release, then waits for
+// the rollout until timeout when it is not 0.
+func (d *DryccCmd) ReleasesRollback(appID string, targets []string, version int, timeout time.Duration) error {
 	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
 	if err != nil {
 		return err
@@ -140,6 +191,7 @@ func (d *DryccCmd) ReleasesRollback(appID string, targets []string, version int)
 		d.Printf("Rolling back to v%d... ", version)
 	}
 
+	startTime := time.Now()
 	quit := progress(d.WOut)
 	newVersion, err := releases.Rollback(s.Client, appID, ptypes, version)
 	quit <- true
@@ -149,6 +201,8 @@ func (d *DryccCmd) ReleasesRollback(appID string, targets []string, version int)
 	}
 
 	d.Printf("done, v%d\n", newVersion)
-
-	return nil
+	if timeout == 0 {
+		return nil
+	}
+	return d.waitRollout(s.Client, appID, targets, newVersion, startTime, timeout)
 }
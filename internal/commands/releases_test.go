@@ -57,7 +57,7 @@ func TestReleasesList(t *testing.T) {
 		}`)
 	})
 
-	err = cmdr.ReleasesList("numenor", []string{}, -1)
+	err = cmdr.ReleasesList("numenor", []string{}, -1, 0)
 	assert.NoError(t, err)
 	assert.Equal(t, b.String(), `STATE      VERSION    CREATED                   SUMMARY                        
 succeed    v2         2016-08-22T17:40:16Z      khamul added ANGMAR               
@@ -98,7 +98,7 @@ func TestReleasesListLimit(t *testing.T) {
 		}`)
 	})
 
-	err = cmdr.ReleasesList("numenor", []string{}, 1)
+	err = cmdr.ReleasesList("numenor", []string{}, 1, 0)
 	assert.NoError(t, err)
 	assert.Equal(t, b.String(), `STATE      VERSION    CREATED                 SUMMARY             
 succeed    v2         2016-08-22T17:40:16Z    khamul added ANGMAR    
@@ -179,11 +179,11 @@ func TestReleasesDeploy(t *testing.T) {
 
 		res.WriteHeader(http.StatusCreated)
 	})
-	err = cmdr.ReleasesDeploy("example-go", []string{}, false, "yes")
+	err = cmdr.ReleasesDeploy("example-go", []string{}, false, "yes", 0)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmdr.ReleasesDeploy("example-go", []string{"web", "task"}, false, "")
+	err = cmdr.ReleasesDeploy("example-go", []string{"web", "task"}, false, "", 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -211,7 +211,7 @@ func TestReleasesRollback(t *testing.T) {
 		fmt.Fprintf(w, `{"version": 5}`)
 	})
 
-	err = cmdr.ReleasesRollback("numenor", []string{"web"}, -1)
+	err = cmdr.ReleasesRollback("numenor", []string{"web"}, -1, 0)
 	assert.NoError(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), "Rolling back one release... done, v5\n", "output")
 
@@ -224,7 +224,7 @@ func TestReleasesRollback(t *testing.T) {
 
 	b.Reset()
 
-	err = cmdr.ReleasesRollback("angmar", []string{"web"}, 3)
+	err = cmdr.ReleasesRollback("angmar", []string{"web"}, 3, 0)
 	assert.NoError(t, err)
 	assert.Equal(t, testutil.StripProgress(b.String()), "Rolling back to v3... done, v3\n", "output")
 }
@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/events"
+	"github.com/drycc/controller-sdk-go/pts"
+	"github.com/drycc/controller-sdk-go/releases"
+	"github.com/drycc/pkg/prettyprint"
+)
+
+// rolloutInterval is the time between two polls of a rollout.
+var rolloutInterval = 2 * time.Second
+
+// failedStates are the states of failed releases and release conditions.
+var failedStates = []string{"failed", "crashed"}
+
+// troubleReasons are the reasons of the events of a rollout that does not
+// progress, they are highlighted.
+var troubleReasons = []string{
+	"BackOff", "CrashLoopBackOff", "ErrImagePull", "ImagePullBackOff", "InspectFailed",
+	"Failed", "FailedCreate", "FailedMount", "FailedScheduling", "Unhealthy", "Evicted", "OOMKilling",
+}
+
+// rollout tracks the progress of process types towards a release.
+type rollout struct {
+	client *drycc.Client
+	appID  string
+	// ptypes are the process types to wait for, all of them when empty.
+	ptypes []string
+	// version is the release to roll out, any release when 0.
+	version int
+	// since is the start of the rollout, older events and conditions are
+	// ignored.
+	since time.Time
+
+	status map[string]string
+	events map[string]bool
+}
+
+// waitRollout polls the process types of an app until they run the release
+// with all their replicas ready, printing their progress and their events.
+// It fails on timeout or when the release fails.
+func (d *DryccCmd) waitRollout(c *drycc.Client, appID string, ptypes []string, version int, since time.Time, timeout time.Duration) error {
+	r := &rollout{
+		client:  c,
+		appID:   appID,
+		ptypes:  ptypes,
+		version: version,
+		since:   since,
+		status:  make(map[string]string),
+		events:  make(map[string]bool),
+	}
+	d.Printf("Waiting for the rollout of %s...\n", appID)
+	startTime := time.Now()
+	deadline := startTime.Add(timeout)
+	for {
+		pending, err := d.pollRollout(r)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			d.Printf("Rollout of %s complete in %ds\n", appID, int(time.Since(startTime).Seconds()))
+			return nil
+		}
+		if !time.Now().Add(rolloutInterval).Before(deadline) {
+			return fmt.Errorf("timed out after %s waiting for the rollout of %s", timeout, strings.Join(pending, ", "))
+		}
+		time.Sleep(rolloutInterval)
+	}
+}
+
+// pollRollout prints the changes of the rollout and returns the process
+// types that are not rolled out yet.
+func (d *DryccCmd) pollRollout(r *rollout) ([]string, error) {
+	if r.version > 0 {
+		if err := r.checkRelease(); err != nil {
+			return nil, err
+		}
+	}
+
+	list, _, err := pts.List(r.client, r.appID, 100)
+	if d.checkAPICompatibility(r.client, err) != nil {
+		return nil, err
+	}
+	found := make(map[string]bool)
+	var pending []string
+	for _, pt := range list {
+		if len(r.ptypes) > 0 && !slices.Contains(r.ptypes, pt.Name) || len(r.ptypes) == 0 && pt.Garbage {
+			continue
+		}
+		found[pt.Name] = true
+
+		done, status := r.progress(pt)
+		if status != r.status[pt.Name] {
+			r.status[pt.Name] = status
+			color := "Yellow"
+			if done {
+				color = "Green"
+			}
+			d.Println(d.colorize(color, status))
+		}
+		if !done {
+			pending = append(pending, pt.Name)
+		}
+
+		ptypeEvents, _, err := events.ListPtypeEvents(r.client, r.appID, pt.Name, 100)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range r.newEvents(ptypeEvents) {
+			line := fmt.Sprintf("  %s: %s: %s", pt.Name, event.Reason, event.Message)
+			if slices.Contains(troubleReasons, event.Reason) {
+				line = d.colorize("Red", line)
+			}
+			d.Println(line)
+		}
+	}
+	for _, ptype := range r.ptypes {
+		if !found[ptype] {
+			pending = append(pending, ptype)
+		}
+	}
+	if len(r.ptypes) == 0 && len(found) == 0 {
+		pending = append(pending, r.appID)
+	}
+	return pending, nil
+}
+
+// checkRelease fails when the release, or one of its conditions on the
+// process types, failed during the rollout.
+func (r *rollout) checkRelease() error {
+	release, err := releases.Get(r.client, r.appID, r.version)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return err
+	}
+	for _, condition := range release.Conditions {
+		if !slices.Contains(failedStates, condition.State) || !r.recent(condition.Created) {
+			continue
+		}
+		if len(r.ptypes) > 0 && len(condition.Ptypes) > 0 && !slices.ContainsFunc(condition.Ptypes, func(ptype string) bool {
+			return slices.Contains(r.ptypes, ptype)
+		}) {
+			continue
+		}
+		return fmt.Errorf("release v%d %s of %s %s: %s", r.version, condition.Action,
+			strings.Join(condition.Ptypes, ", "), condition.State, condition.Exception)
+	}
+	if slices.Contains(failedStates, release.State) && r.recent(release.Created) {
+		if release.Exception != "" {
+			return fmt.Errorf("release v%d %s: %s", r.version, release.State, release.Exception)
+		}
+		return fmt.Errorf("release v%d %s", r.version, release.State)
+	}
+	return nil
+}
+
+// progress reports whether a process type runs the release with all its
+// replicas ready, and describes its progress.
+func (r *rollout) progress(pt api.Ptype) (bool, string) {
+	ready, desired := -1, -1
+	if a, b, ok := strings.Cut(pt.Ready, "/"); ok {
+		ready, _ = strconv.Atoi(a)
+		desired, _ = strconv.Atoi(b)
+	}
+	done := desired >= 0 && ready == desired && pt.UpToDate >= desired && pt.AvailableReplicas >= desired
+	status := fmt.Sprintf("%s: %s ready, %d up-to-date, %d available", pt.Name, pt.Ready, pt.UpToDate, pt.AvailableReplicas)
+	if r.version > 0 {
+		done = done && pt.Release == fmt.Sprintf("v%d", r.version)
+		status = fmt.Sprintf("%s (%s)", status, pt.Release)
+	}
+	return done, status
+}
+
+// newEvents returns the events of the rollout that were not seen yet.
+func (r *rollout) newEvents(list api.AppEvents) api.AppEvents {
+	var result api.AppEvents
+	for _, event := range list {
+		key := eventKey(event)
+		if r.events[key] || !r.recent(event.Created) {
+			continue
+		}
+		r.events[key] = true
+		result = append(result, event)
+	}
+	slices.SortStableFunc(result, func(a, b api.AppEvent) int {
+		return strings.Compare(a.Created, b.Created)
+	})
+	return result
+}
+
+// recent reports whether a time is not before the rollout, times that can
+// not be parsed are.
+func (r *rollout) recent(created string) bool {
+	t, err := time.Parse(time.RFC3339, created)
+	return err != nil || !t.Before(r.since.Truncate(time.Second))
+}
+
+// colorize wraps text with a color of prettyprint.Colors when the output
+// accepts colors.
+func (d *DryccCmd) colorize(color, text string) string {
+	if !d.colorEnabled() {
+		return text
+	}
+	return prettyprint.Colors[color] + text + prettyprint.Colors["Default"]
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPtsScaleWait(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	server.Mux.HandleFunc("/v2/apps/foo/ptypes/scale/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/ptypes/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"count": 2,
+			"next": null,
+			"previous": null,
+			"results": [
+				{"name": "web", "release": "v2", "ready": "2/2", "up_to_date": 2, "available_replicas": 2, "garbage": false},
+				{"name": "worker", "release": "v2", "ready": "0/1", "up_to_date": 0, "available_replicas": 0, "garbage": false}
+			]
+		}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/events/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 0, "next": null, "previous": null, "results": []}`)
+	})
+
+	err = cmdr.PtsScale("foo", []string{"web=2"}, 10*rolloutInterval)
+	assert.NoError(t, err)
+	assert.Equal(t, testutil.StripProgress(b.String()), `Scaling process types... but first, coffee!
+done in 0s
+Waiting for the rollout of foo...
+web: 2/2 ready, 2 up-to-date, 2 available
+Rollout of foo complete in 0s
+`, "output")
+}
+
+func TestPtsRestartWaitTimeout(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	server.Mux.HandleFunc("/v2/apps/foo/ptypes/restart/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/ptypes/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"count": 1,
+			"next": null,
+			"previous": null,
+			"results": [
+				{"name": "web", "release": "v2", "ready": "0/1", "up_to_date": 1, "available_replicas": 0, "garbage": false}
+			]
+		}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/events/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("ptype"), "foo-web", "ptype")
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"count": 3,
+			"next": null,
+			"previous": null,
+			"results": [
+				{"reason": "BackOff", "message": "Back-off restarting failed container", "created": "2099-01-01T00:00:02Z"},
+				{"reason": "Pulled", "message": "Container image already present", "created": "2099-01-01T00:00:01Z"},
+				{"reason": "Started", "message": "Started container web", "created": "2000-01-01T00:00:00Z"}
+			]
+		}`)
+	})
+
+	err = cmdr.PtsRestart("foo", []string{"web"}, "", rolloutInterval/2)
+	assert.EqualError(t, err, fmt.Sprintf("timed out after %s waiting for the rollout of web", rolloutInterval/2))
+	assert.Equal(t, testutil.StripProgress(b.String()), `Restarting process types... but first, coffee!
+done in 0s
+Waiting for the rollout of foo...
+web: 0/1 ready, 1 up-to-date, 0 available
+  web: Pulled: Container image already present
+  web: BackOff: Back-off restarting failed container
+`, "output")
+}
+
+func TestReleasesRollbackWaitFailed(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	server.Mux.HandleFunc("/v2/apps/numenor/releases/rollback/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"version": 5}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/numenor/releases/v5/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"app": "numenor",
+			"state": "failed",
+			"version": 5,
+			"created": "2099-01-01T00:00:00Z",
+			"conditions": [
+				{"state": "succeed", "action": "deploy", "ptypes": ["worker"], "created": "2099-01-01T00:00:00Z"},
+				{"state": "failed", "action": "deploy", "ptypes": ["web"], "exception": "image not found", "created": "2099-01-01T00:00:00Z"}
+			]
+		}`)
+	})
+
+	err = cmdr.ReleasesRollback("numenor", []string{"web"}, -1, 10*rolloutInterval)
+	assert.EqualError(t, err, "release v5 deploy of web failed: image not found")
+	assert.Equal(t, testutil.StripProgress(b.String()), `Rolling back one release... done, v5
+Waiting for the rollout of numenor...
+`, "output")
+}
+
+func TestReleasesDeployWaitListError(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	server.Mux.HandleFunc("/v2/apps/numenor/releases/deploy/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusCreated)
+	})
+	server.Mux.HandleFunc("/v2/apps/numenor/releases/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"detail": "Not found."}`)
+	})
+
+	// the rollout is not waited for against an unknown release
+	err = cmdr.ReleasesDeploy("numenor", []string{"web", ""}, false, "", 10*rolloutInterval)
+	assert.ErrorContains(t, err, "Not found.")
+	assert.NotContains(t, b.String(), "Waiting for the rollout", "output")
+}
@@ -25,6 +25,11 @@ func (d *DryccCmd) RoutesList(appID string, results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(routes)
+	}
+
 	if count == 0 {
 		d.Println(fmt.Sprintf("No routes found in %s app.", appID))
 	} else {
@@ -71,6 +76,10 @@ func (d *DryccCmd) RoutesInfo(appID, name string) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(info)
+	}
+
 	c := &coder.RouteCoder{Info: info}
 	yamlBytes, err := c.Encode()
 	if err != nil {
@@ -18,6 +18,10 @@ func (d *DryccCmd) RoutingInfo(appID string) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(appSettings)
+	}
+
 	if appSettings.Routable == nil || *appSettings.Routable {
 		d.Println("Routing is enabled.")
 	} else {
@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/ps"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"golang.org/x/net/websocket"
+)
+
+// runPtype is the process type of the pods of the one-off commands.
+const runPtype = "run"
+
+var (
+	// runInterval is the time between two polls of a one-off pod.
+	runInterval = time.Second
+	// runWaitTimeout is how long a one-off pod takes to start, and to report
+	// its exit code once its logs ended.
+	runWaitTimeout = 2 * time.Minute
+)
+
+// allLogLines requests all the log lines of a pod, as ps logs --lines -1.
+const allLogLines = -1
+
+// runPendingStates are the states of the pods that do not run yet.
+var runPendingStates = []string{"", "pending", "initializing", "starting"}
+
+// runActiveStates are the states of the pods that run, which can be attached.
+var runActiveStates = []string{"up", "running"}
+
+// RunList lists the one-off commands of an app.
+func (d *DryccCmd) RunList(appID string) error {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+	pods, err := runPods(s.Client, appID)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+	if d.Output != "" {
+		return d.printObject(pods)
+	}
+	if len(pods) == 0 {
+		d.Printf("No one-off commands found in %s app.\n", appID)
+		return nil
+	}
+	table := d.getDefaultFormatTable([]string{"NAME", "RELEASE", "STATE", "STARTED"})
+	for _, pod := range pods {
+		table.Append([]string{pod.Name, pod.Release, pod.State, d.formatTime(pod.Started)})
+	}
+	table.Render()
+	return nil
+}
+
+// RunKill stops one-off commands of an app, deleting their pods.
+func (d *DryccCmd) RunKill(appID string, podIDs []string) error {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+	pods, err := runPods(s.Client, appID)
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+	for _, podID := range podIDs {
+		if !slices.ContainsFunc(pods, func(pod api.Pods) bool { return pod.Name == podID }) {
+			return fmt.Errorf("%s is not a one-off command of %s, see drycc run list", podID, appID)
+		}
+	}
+
+	names := strings.Join(podIDs, ",")
+	d.Printf("Killing %s from %s... ", names, appID)
+	quit := progress(d.WOut)
+	err = ps.Delete(s.Client, appID, names)
+	quit <- true
+	<-quit
+	if d.checkAPICompatibility(s.Client, err) != nil {
+		return err
+	}
+	d.Println("done")
+	return nil
+}
+
+// attachRun mirrors the stdout and the stderr of the one-off pod until it
+// ends, and returns an ExitError when the command failed. The output is read
+// from the attach websocket, and from the logs of the pod when it already
+// finished or can not be attached, the logs mixing stdout and stderr. On
+// Ctrl+C the command keeps running.
+func (d *DryccCmd) attachRun(c *drycc.Client, appID, podID string, retries int) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	state, err := waitRunState(ctx, c, appID, podID)
+	if err != nil {
+		return err
+	}
+	var conn *websocket.Conn
+	if slices.Contains(runActiveStates, strings.ToLower(state)) {
+		conn, err = attachPod(c, appID, podID)
+	}
+	if conn != nil && err == nil {
+		err = d.attachExec(ctx, conn)
+	} else {
+		err = d.printRunLogs(ctx, c, appID, podID, retries)
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitErr.Code, Message: fmt.Sprintf("%s exited with code %d", podID, exitErr.Code)}
+	}
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		d.PrintErrf("Detached from %s, it keeps running, see drycc run logs %s -a %s\n", podID, podID, appID)
+		return nil
+	}
+	code, err := runExitCode(ctx, c, appID, podID)
+	if err != nil || code == 0 {
+		return err
+	}
+	return &ExitError{Code: code, Message: fmt.Sprintf("%s exited with code %d", podID, code)}
+}
+
+// attachExec writes the stdout and the stderr of an attached pod to the
+// output and the error output until it ends or ctx is done.
+func (d *DryccCmd) attachExec(ctx context.Context, conn *websocket.Conn) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+	err := runExec(conn, d.WOut, d.WErr)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// printRunLogs prints the logs of a one-off pod until they end.
+func (d *DryccCmd) printRunLogs(ctx context.Context, c *drycc.Client, appID, podID string, retries int) error {
+	stream := logStream{
+		client: c, appID: appID, podID: podID, retries: retries,
+		warn:    func(format string, args ...any) { d.PrintErrf(format, args...) },
+		request: api.PodLogsRequest{Lines: allLogLines, Follow: true},
+	}
+	return stream.run(ctx, func(lines []string) {
+		for _, line := range lines {
+			d.Printf("%s\n", line)
+		}
+	})
+}
+
+// attachPod opens the attach websocket of a pod, which sends its output on
+// the channels of the exec websocket.
+func attachPod(c *drycc.Client, appID, podID string) (*websocket.Conn, error) {
+	scheme := "ws"
+	if c.ControllerURL.Scheme == "https" {
+		scheme = "wss"
+	}
+	u := url.URL{Scheme: scheme, Host: c.ControllerURL.Host, Path: fmt.Sprintf("v2/apps/%s/pods/%s/attach/", appID, podID)}
+	config, err := websocket.NewConfig(u.String(), c.ControllerURL.String())
+	if err != nil {
+		return nil, err
+	}
+	token := c.Token
+	if !strings.HasPrefix(strings.ToLower(token), "bearer ") && !strings.HasPrefix(strings.ToLower(token), "token ") {
+		token = "token " + token
+	}
+	config.Header = http.Header{"User-Agent": {c.UserAgent}, "Authorization": {token}}
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := websocket.JSON.Send(conn, api.Command{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// runPods returns the pods of the one-off commands of an app, the most
+// recent first.
+func runPods(c *drycc.Client, appID string) (api.PodsList, error) {
+	pods, _, err := ps.List(c, appID, defaultLimit)
+	if err != nil {
+		return nil, err
+	}
+	pods = slices.DeleteFunc(pods, func(pod api.Pods) bool { return pod.Type != runPtype })
+	slices.SortStableFunc(pods, func(a, b api.Pods) int { return strings.Compare(b.Started, a.Started) })
+	return pods, nil
+}
+
+// waitRunPod returns the name of the one-off pod of command started after
+// the pods of known. As the run request does not tell the pod it starts, the
+// pod is the new one whose container runs command, and it fails when several
+// new pods run the same command.
+func waitRunPod(c *drycc.Client, appID, command string, known api.PodsList) (string, error) {
+	deadline := time.Now().Add(runWaitTimeout)
+	for {
+		pods, err := runPods(c, appID)
+		if err != nil && !drycc.IsErrAPIMismatch(err) {
+			return "", err
+		}
+		var matches []string
+		for _, pod := range pods {
+			if slices.ContainsFunc(known, func(k api.Pods) bool { return k.Name == pod.Name }) {
+				continue
+			}
+			states, _, err := ps.Describe(c, appID, pod.Name, defaultLimit)
+			if err != nil && !drycc.IsErrAPIMismatch(err) {
+				return "", err
+			}
+			if runsCommand(states, command) {
+				matches = append(matches, pod.Name)
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0], nil
+		}
+		if len(matches) > 1 {
+			return "", fmt.Errorf("%s all run '%s', see drycc run list and drycc run logs", strings.Join(matches, ", "), command)
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("timed out after %s waiting for the pod of the command", runWaitTimeout)
+		}
+		time.Sleep(runInterval)
+	}
+}
+
+// runsCommand tells whether a container of a pod runs command, the spaces
+// aside.
+func runsCommand(states api.PodState, command string) bool {
+	want := strings.Join(strings.Fields(command), " ")
+	for _, state := range states {
+		args := strings.Join(strings.Fields(strings.Join(append(slices.Clone(state.Command), state.Args...), " ")), " ")
+		if want != "" && strings.Contains(args, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitRunState waits for a one-off pod to run or to be done, and returns its
+// state.
+func waitRunState(ctx context.Context, c *drycc.Client, appID, podID string) (string, error) {
+	deadline := time.Now().Add(runWaitTimeout)
+	for {
+		pods, err := runPods(c, appID)
+		if err != nil && !drycc.IsErrAPIMismatch(err) {
+			return "", err
+		}
+		index := slices.IndexFunc(pods, func(pod api.Pods) bool { return pod.Name == podID })
+		if index >= 0 && !slices.Contains(runPendingStates, strings.ToLower(pods[index].State)) {
+			return pods[index].State, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("timed out after %s waiting for %s to start", runWaitTimeout, podID)
+		}
+		select {
+		case <-ctx.Done():
+			return "", nil
+		case <-time.After(runInterval):
+		}
+	}
+}
+
+// runExitCode returns the exit code of the container of a one-off pod once
+// it terminated.
+func runExitCode(ctx context.Context, c *drycc.Client, appID, podID string) (int, error) {
+	deadline := time.Now().Add(runWaitTimeout)
+	for {
+		states, _, err := ps.Describe(c, appID, podID, defaultLimit)
+		if err != nil && !drycc.IsErrAPIMismatch(err) {
+			return 0, err
+		}
+		if code, ok := exitCode(states); ok {
+			return code, nil
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("timed out after %s waiting for the exit code of %s", runWaitTimeout, podID)
+		}
+		select {
+		case <-ctx.Done():
+			return 0, nil
+		case <-time.After(runInterval):
+		}
+	}
+}
+
+// exitCode returns the exit code of the containers terminated, the first
+// non-zero one, and whether they all terminated.
+func exitCode(states api.PodState) (int, bool) {
+	if len(states) == 0 {
+		return 0, false
+	}
+	code := 0
+	for _, state := range states {
+		terminated, ok := state.State["terminated"]
+		if !ok {
+			return 0, false
+		}
+		if n, ok := terminated["exitCode"].(float64); ok && code == 0 {
+			code = int(n)
+		}
+	}
+	return code, true
+}
@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+// runServer serves a one-off pod foo-run-1 of the app foo, started by a run,
+// printing two lines and exiting with code, along with a one-off pod
+// foo-run-2 running another command. Unless attached, foo-run-1 ends before
+// it is attached and its output is only in its logs.
+func runServer(t *testing.T, code int, attached bool) (string, *testutil.TestServer) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var started, done atomic.Bool
+	done.Store(!attached)
+	server.Mux.HandleFunc("/v2/apps/foo/run", func(w http.ResponseWriter, _ *http.Request) {
+		started.Store(true)
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		pods := `{"release": "v2", "type": "web", "name": "foo-web-1", "state": "up", "started": "2016-02-13T00:47:52"}`
+		if started.Load() {
+			state := "up"
+			if done.Load() {
+				state = "down"
+			}
+			pods += fmt.Sprintf(`, {"release": "v2", "type": "run", "name": "foo-run-1", "state": "%s", "started": "2016-02-14T00:47:52"}`, state)
+			pods += `, {"release": "v2", "type": "run", "name": "foo-run-2", "state": "up", "started": "2016-02-14T00:47:53"}`
+		}
+		fmt.Fprintf(w, `{"count": 2, "next": null, "previous": null, "results": [%s]}`, pods)
+	})
+	server.Mux.Handle("/v2/apps/foo/pods/foo-run-1/logs/", websocket.Handler(func(conn *websocket.Conn) {
+		var request string
+		websocket.Message.Receive(conn, &request)
+		websocket.Message.Send(conn, "migrating\n")
+		websocket.Message.Send(conn, "migrated\n")
+		done.Store(true)
+		conn.Close()
+	}))
+	if attached {
+		server.Mux.Handle("/v2/apps/foo/pods/foo-run-1/attach/", websocket.Handler(func(conn *websocket.Conn) {
+			var request string
+			websocket.Message.Receive(conn, &request)
+			websocket.Message.Send(conn, []byte("\x01migrating\n"))
+			websocket.Message.Send(conn, []byte("\x02warning: no fixtures\n"))
+			websocket.Message.Send(conn, []byte("\x01migrated\n"))
+			status := `{"metadata":{},"status":"Success"}`
+			if code != 0 {
+				status = fmt.Sprintf(`{"metadata":{},"status":"Failure","message":"command terminated with non-zero exit code",`+
+					`"reason":"NonZeroExitCode","details":{"causes":[{"reason":"ExitCode","message":"%d"}]}}`, code)
+			}
+			websocket.Message.Send(conn, []byte("\x03"+status))
+			done.Store(true)
+			conn.Close()
+		}))
+	}
+	server.Mux.HandleFunc("/v2/apps/foo/pods/foo-run-1/describe/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{"count": 1, "next": null, "previous": null, "results": [{
+			"container": "foo-run", "command": ["/bin/sh", "-c"], "args": ["./manage.py  migrate"],
+			"state": {"terminated": {"exitCode": %d, "reason": "Completed"}}
+		}]}`, code)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/pods/foo-run-2/describe/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `{"count": 1, "next": null, "previous": null, "results": [{
+			"container": "foo-run", "command": ["/bin/sh", "-c"], "args": ["./manage.py shell"], "state": {"running": {}}
+		}]}`)
+	})
+	return cf, server
+}
+
+func TestAppRun(t *testing.T) {
+	t.Parallel()
+	cf, server := runServer(t, 0, true)
+	defer server.Close()
+	var b, e bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WErr: &e, ConfigFile: cf}
+
+	err := cmdr.AppRun("foo", "./manage.py migrate", nil, 3600, 3600, false)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "Running './manage.py migrate'...\nmigrating\nmigrated\n", "output")
+	assert.Equal(t, e.String(), "warning: no fixtures\n", "stderr")
+}
+
+func TestAppRunExitCode(t *testing.T) {
+	t.Parallel()
+	cf, server := runServer(t, 3, true)
+	defer server.Close()
+	var b, e bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WErr: &e, ConfigFile: cf}
+
+	err := cmdr.AppRun("foo", "./manage.py migrate", nil, 3600, 3600, false)
+	assert.Equal(t, err, &ExitError{Code: 3, Message: "foo-run-1 exited with code 3"}, "error")
+	assert.Equal(t, b.String(), "Running './manage.py migrate'...\nmigrating\nmigrated\n", "output")
+	assert.Equal(t, e.String(), "warning: no fixtures\n", "stderr")
+}
+
+func TestAppRunFinished(t *testing.T) {
+	t.Parallel()
+	for _, code := range []int{0, 3} {
+		cf, server := runServer(t, code, false)
+		defer server.Close()
+		var b bytes.Buffer
+		cmdr := DryccCmd{WOut: &b, WErr: &b, ConfigFile: cf}
+
+		err := cmdr.AppRun("foo", "./manage.py migrate", nil, 3600, 3600, false)
+		if code == 0 {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, err, &ExitError{Code: 3, Message: "foo-run-1 exited with code 3"}, "error")
+		}
+		assert.Equal(t, b.String(), "Running './manage.py migrate'...\nmigrating\nmigrated\n", "output")
+	}
+}
+
+func TestAppRunDetach(t *testing.T) {
+	t.Parallel()
+	cf, server := runServer(t, 0, true)
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, WErr: &b, ConfigFile: cf}
+
+	err := cmdr.AppRun("foo", "./manage.py migrate", nil, 3600, 3600, true)
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), `Running './manage.py migrate'...
+Started foo-run-1, see its logs with drycc run logs foo-run-1 -a foo
+`, "output")
+}
+
+func TestRunList(t *testing.T) {
+	t.Parallel()
+	cf, server := runServer(t, 0, true)
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err := cmdr.RunList("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "No one-off commands found in foo app.\n", "output")
+
+	res, err := http.Post(server.Server.URL+"/v2/apps/foo/run", "application/json", nil)
+	assert.NoError(t, err)
+	res.Body.Close()
+	b.Reset()
+	err = cmdr.RunList("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), `NAME         RELEASE    STATE    STARTED             
+foo-run-2    v2         up       2016-02-14T00:47:53    
+foo-run-1    v2         up       2016-02-14T00:47:52    
+`, "output")
+}
+
+func TestRunKill(t *testing.T) {
+	t.Parallel()
+	cf, server := runServer(t, 0, true)
+	defer server.Close()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf}
+
+	err := cmdr.RunKill("foo", []string{"foo-web-1"})
+	assert.EqualError(t, err, "foo-web-1 is not a one-off command of foo, see drycc run list")
+}
+
+func TestRunsCommand(t *testing.T) {
+	t.Parallel()
+	states := api.PodState{
+		{Container: "sidecar", Command: []string{"envoy"}},
+		{Container: "foo-run", Command: []string{"/bin/sh", "-c"}, Args: []string{"./manage.py migrate --noinput"}},
+	}
+	assert.True(t, runsCommand(states, "./manage.py   migrate --noinput"))
+	assert.False(t, runsCommand(states, "./manage.py shell"))
+	assert.False(t, runsCommand(states, " "))
+	assert.False(t, runsCommand(nil, "./manage.py migrate"))
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+	terminated := func(code float64) map[string]map[string]any {
+		return map[string]map[string]any{"terminated": {"exitCode": code}}
+	}
+	running := map[string]map[string]any{"running": {}}
+
+	_, ok := exitCode(nil)
+	assert.False(t, ok)
+	_, ok = exitCode(api.PodState{{State: terminated(0)}, {State: running}})
+	assert.False(t, ok)
+	code, ok := exitCode(api.PodState{{State: terminated(0)}, {State: terminated(137)}})
+	assert.True(t, ok)
+	assert.Equal(t, code, 137, "exit code")
+}
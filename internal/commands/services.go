@@ -20,6 +20,11 @@ func (d *DryccCmd) ServicesList(appID string) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(services)
+	}
+
 	if len(services) > 0 {
 		table := d.getDefaultFormatTable([]string{"NAME", "PTYPE", "PORT", "PROTOCOL", "TARGET-PORT", "DOMAIN"})
 		for _, service := range services {
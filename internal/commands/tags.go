@@ -20,6 +20,11 @@ func (d *DryccCmd) TagsList(appID, ptype string, version int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(filterPtype(config.Tags, ptype))
+	}
+
 	if len(config.Tags) == 0 {
 		d.Println(fmt.Sprintf("No tags found in %s app.", appID))
 		return nil
@@ -114,6 +114,43 @@ web      warp    8
 `)
 }
 
+func TestTagsListOutput(t *testing.T) {
+	t.Parallel()
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/enterprise/config/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"owner": "jkirk",
+			"app": "enterprise",
+			"values": [],
+			"tags": {
+				"web": {"warp": "8"},
+				"worker": {"ncc": "1701"}
+			},
+			"created": "2014-01-01T00:00:00UTC",
+			"updated": "2014-01-01T00:00:00UTC",
+			"uuid": "de1bf5b5-4a72-4f94-a10c-d2a3741cdf75"
+		}`)
+	})
+
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, ConfigFile: cf, Output: "json"}
+
+	err = cmdr.TagsList("enterprise", "web", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "web": {
+    "warp": "8"
+  }
+}
+`, b.String())
+}
+
 func TestTagsSet(t *testing.T) {
 	t.Parallel()
 	cf, server, err := testutil.NewTestServerAndClient()
@@ -21,6 +21,10 @@ func (d *DryccCmd) TimeoutsList(appID string, version int) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(config.Timeout)
+	}
+
 	if len(config.Timeout) == 0 {
 		d.Println("Default (30 sec) or controlled by drycc controller.")
 	} else {
@@ -18,6 +18,11 @@ func (d *DryccCmd) TLSInfo(appID string) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(tls)
+	}
+
 	table := d.getDefaultFormatTable([]string{})
 	table.Append([]string{"UUID:", tls.UUID})
 	table.Append([]string{"CertsAuto:", fmt.Sprintf("%v", tls.CertsAutoEnabled != nil && *(tls.CertsAutoEnabled))})
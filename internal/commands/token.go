@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 	"github.com/drycc/controller-sdk-go/auth"
 	"github.com/drycc/controller-sdk-go/tokens"
 	"github.com/drycc/workflow-cli/pkg/settings"
+	"github.com/drycc/workflow-cli/pkg/transport"
 )
 
 // TokensList lists authentication tokens.
@@ -30,6 +32,11 @@ func (d *DryccCmd) TokensList(results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(tokens)
+	}
+
 	table := d.getDefaultFormatTable([]string{"UUID", "OWNER", "ALIAS", "KEY", "CREATE", "UPDATED"})
 	for _, token := range tokens {
 		table.Append([]string{
@@ -150,17 +157,27 @@ func (d *DryccCmd) openBrower(URL string) error {
 	return nil
 }
 
+// loginTimeout is how long to wait for a login in the browser, polled with
+// loginBackoff.
+var (
+	loginTimeout = 10 * time.Minute
+	loginBackoff = transport.Backoff{Min: time.Second, Max: 5 * time.Second}
+)
+
 func (d *DryccCmd) doToken(c *drycc.Client, key, alias string) (*api.AuthTokenResponse, error) {
-	var token api.AuthTokenResponse
-	for i := 0; i <= 120; i++ {
-		token, _ = auth.Token(c, key, alias)
-		time.Sleep(time.Duration(5) * time.Second)
-		if token.Token != "" && token.Username != "" {
-			break
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+	for attempt := 0; ; attempt++ {
+		// the token is empty until the login is done
+		token, err := auth.Token(c, key, alias)
+		if token.Token == "fail" {
+			return nil, errors.New("logged fail")
+		}
+		if err == nil && token.Token != "" && token.Username != "" {
+			return &token, nil
+		}
+		if loginBackoff.Wait(ctx, attempt) != nil {
+			return nil, fmt.Errorf("timed out waiting for the login after %s", loginTimeout)
 		}
 	}
-	if token.Token == "" || token.Token == "fail" {
-		return nil, errors.New("logged fail")
-	}
-	return &token, nil
 }
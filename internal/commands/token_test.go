@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/drycc/workflow-cli/pkg/settings"
 	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/drycc/workflow-cli/pkg/transport"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -76,3 +80,52 @@ func TestTokenDelete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, b.String(), "done\n")
 }
+
+func TestDoToken(t *testing.T) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	s, err := settings.Load(cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(backoff transport.Backoff, timeout time.Duration) {
+		loginBackoff, loginTimeout = backoff, timeout
+	}(loginBackoff, loginTimeout)
+	loginBackoff = transport.Backoff{Min: time.Millisecond, Max: time.Millisecond}
+	loginTimeout = time.Second
+
+	// the login is done on the third poll
+	var polls atomic.Int32
+	server.Mux.HandleFunc("/v2/auth/token/done/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		if polls.Add(1) < 3 {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		fmt.Fprint(w, `{"username": "test", "token": "abc"}`)
+	})
+	server.Mux.HandleFunc("/v2/auth/token/fail/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `{"token": "fail"}`)
+	})
+	server.Mux.HandleFunc("/v2/auth/token/never/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprint(w, `{}`)
+	})
+	cmdr := DryccCmd{ConfigFile: cf}
+
+	token, err := cmdr.doToken(s.Client, "done", "")
+	assert.NoError(t, err)
+	assert.Equal(t, token.Token, "abc")
+	assert.Equal(t, polls.Load(), int32(3))
+
+	_, err = cmdr.doToken(s.Client, "fail", "")
+	assert.EqualError(t, err, "logged fail")
+
+	loginTimeout = 50 * time.Millisecond
+	_, err = cmdr.doToken(s.Client, "never", "")
+	assert.EqualError(t, err, "timed out waiting for the login after 50ms")
+}
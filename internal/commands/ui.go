@@ -0,0 +1,567 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/console"
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/events"
+	"github.com/drycc/controller-sdk-go/ps"
+	"github.com/drycc/controller-sdk-go/pts"
+	"github.com/drycc/controller-sdk-go/releases"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"golang.org/x/net/websocket"
+	"golang.org/x/term"
+)
+
+// the panes of the dashboard with a cursor
+const (
+	uiProcesses = iota
+	uiPtypes
+	uiReleases
+	uiPanes
+)
+
+const (
+	// uiLogLines is the number of log lines kept by the dashboard.
+	uiLogLines = 500
+	// uiReleaseCount is the number of releases shown by the dashboard.
+	uiReleaseCount = 20
+	// uiEventCount is the number of events shown by the dashboard.
+	uiEventCount = 20
+
+	uiEnterScreen = "\033[?1049h\033[?25l"
+	uiLeaveScreen = "\033[?25h\033[?1049l"
+)
+
+var uiPaneTitles = [uiPanes]string{"Processes", "Process types", "Releases"}
+
+const uiHelp = "tab pane  ↑↓ select  l logs  e exec  s scale  r restart  b rollback  R refresh  q quit"
+
+// dashboard is the state of drycc ui.
+type dashboard struct {
+	d      *DryccCmd
+	client *drycc.Client
+	appID  string
+	out    io.Writer
+	// size returns the width and the height of the screen.
+	size func() (int, int)
+	// keys are the keys typed, closed when the input ends.
+	keys <-chan []byte
+	// redraw is signaled when the logs change.
+	redraw chan struct{}
+
+	mu      sync.Mutex
+	rows    [uiPanes][][]string
+	events  api.AppEvents
+	updated time.Time
+	status  string
+
+	logPod  string
+	logs    []string
+	logConn *websocket.Conn
+
+	pane   int
+	cursor [uiPanes]int
+	prompt *uiPrompt
+}
+
+// uiPrompt asks for a confirmation, or a number with digits, before an
+// action.
+type uiPrompt struct {
+	label  string
+	digits bool
+	input  string
+	action func(input string) (string, error)
+}
+
+// UI shows a full-screen dashboard of an app with its processes, process
+// types, releases, events and the logs of a pod, refreshed every interval.
+func (d *DryccCmd) UI(appID string, interval time.Duration) error {
+	appID, s, err := loader.LoadAppSettings(d.ConfigFile, appID)
+	if err != nil {
+		return err
+	}
+	in, inOK := d.WIn.(*os.File)
+	out, outOK := d.WOut.(*os.File)
+	if !inOK || !outOK || !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return errors.New("drycc ui needs a terminal")
+	}
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(in.Fd()), state)
+
+	keys := make(chan []byte)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- slices.Clone(buf[:n])
+		}
+	}()
+
+	u := newDashboard(d, s.Client, appID, out)
+	u.keys = keys
+	u.size = func() (int, int) {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil {
+			return 80, 24
+		}
+		return width, height
+	}
+	io.WriteString(out, uiEnterScreen)
+	defer io.WriteString(out, uiLeaveScreen)
+	defer u.closeLogs()
+	return u.run(interval)
+}
+
+func newDashboard(d *DryccCmd, c *drycc.Client, appID string, out io.Writer) *dashboard {
+	return &dashboard{
+		d:      d,
+		client: c,
+		appID:  appID,
+		out:    out,
+		size:   func() (int, int) { return 80, 24 },
+		redraw: make(chan struct{}, 1),
+	}
+}
+
+// run refreshes and draws the dashboard until q is typed or the input ends.
+func (u *dashboard) run(interval time.Duration) error {
+	u.refresh()
+	if pod := u.selected(uiProcesses); pod != "" {
+		u.followLogs(pod)
+	}
+	u.draw()
+	ticker := time.NewTicker(max(interval, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			u.refresh()
+		case <-u.redraw:
+		case key, ok := <-u.keys:
+			if !ok || u.handleKey(key) {
+				return nil
+			}
+		}
+		u.draw()
+	}
+}
+
+// refresh fetches the processes, process types, releases and the events of
+// the selected process type.
+func (u *dashboard) refresh() {
+	var rows [uiPanes][][]string
+	processes, _, err := ps.List(u.client, u.appID, defaultLimit)
+	if err == nil || drycc.IsErrAPIMismatch(err) {
+		rows[uiProcesses] = processRows(u.d, processes)
+		var ptypes api.Ptypes
+		ptypes, _, err = pts.List(u.client, u.appID, defaultLimit)
+		rows[uiPtypes] = processTypeRows(u.d, ptypes)
+	}
+	if err == nil || drycc.IsErrAPIMismatch(err) {
+		var list []api.Release
+		list, _, err = releases.List(u.client, u.appID, "", uiReleaseCount)
+		rows[uiReleases] = releaseRows(u.d, list)
+	}
+
+	u.mu.Lock()
+	u.rows = rows
+	for pane := range u.cursor {
+		u.cursor[pane] = max(min(u.cursor[pane], len(rows[pane])-1), 0)
+	}
+	ptype := u.selectedLocked(uiPtypes)
+	u.mu.Unlock()
+
+	var list api.AppEvents
+	if (err == nil || drycc.IsErrAPIMismatch(err)) && ptype != "" {
+		list, _, err = events.ListPtypeEvents(u.client, u.appID, ptype, uiEventCount)
+		slices.SortStableFunc(list, func(a, b api.AppEvent) int {
+			return strings.Compare(a.Created, b.Created)
+		})
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.events = list
+	u.updated = time.Now()
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		u.status = err.Error()
+	}
+}
+
+// selected returns the first cell of the selected row of a pane, the pod,
+// the process type or the version of the release.
+func (u *dashboard) selected(pane int) string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.selectedLocked(pane)
+}
+
+func (u *dashboard) selectedLocked(pane int) string {
+	rows := u.rows[pane]
+	if len(rows) == 0 {
+		return ""
+	}
+	if pane == uiReleases {
+		return rows[u.cursor[pane]][1]
+	}
+	return rows[u.cursor[pane]][0]
+}
+
+// handleKey runs the action of a key and reports whether to quit.
+func (u *dashboard) handleKey(key []byte) bool {
+	if u.prompt != nil {
+		u.handlePromptKey(key)
+		return false
+	}
+	switch string(key) {
+	case "q", "\x03":
+		return true
+	case "\t":
+		u.pane = (u.pane + 1) % uiPanes
+	case "\033[Z":
+		u.pane = (u.pane + uiPanes - 1) % uiPanes
+	case "j", "\033[B", "\033OB":
+		u.moveCursor(1)
+	case "k", "\033[A", "\033OA":
+		u.moveCursor(-1)
+	case "R", "\x0c":
+		u.setStatus("")
+		u.refresh()
+	case "l", "\r":
+		if pod := u.selected(uiProcesses); pod != "" {
+			u.followLogs(pod)
+		}
+	case "e":
+		if pod := u.selected(uiProcesses); pod != "" {
+			u.exec(pod)
+		}
+	case "s":
+		if ptype := u.selected(uiPtypes); ptype != "" {
+			u.prompt = &uiPrompt{label: fmt.Sprintf("Scale %s to: ", ptype), digits: true, action: func(input string) (string, error) {
+				replicas, err := strconv.Atoi(input)
+				if err != nil {
+					return "", fmt.Errorf("'%s' is not a number of replicas", input)
+				}
+				return fmt.Sprintf("Scaled %s to %d", ptype, replicas), pts.Scale(u.client, u.appID, map[string]int{ptype: replicas})
+			}}
+		}
+	case "r":
+		if ptype := u.selected(uiPtypes); ptype != "" {
+			u.prompt = &uiPrompt{label: fmt.Sprintf("Restart %s? (y/N) ", ptype), action: func(string) (string, error) {
+				return fmt.Sprintf("Restarted %s", ptype), pts.Restart(u.client, u.appID, map[string]string{"ptypes": ptype})
+			}}
+		}
+	case "b":
+		if release := u.selected(uiReleases); release != "" {
+			u.prompt = &uiPrompt{label: fmt.Sprintf("Roll back to %s? (y/N) ", release), action: func(string) (string, error) {
+				version, _ := strconv.Atoi(strings.TrimPrefix(release, "v"))
+				newVersion, err := releases.Rollback(u.client, u.appID, "", version)
+				return fmt.Sprintf("Rolled back to %s, v%d", release, newVersion), err
+			}}
+		}
+	}
+	return false
+}
+
+// handlePromptKey edits the prompt, runs its action on enter or y and
+// cancels it on escape or any other answer.
+func (u *dashboard) handlePromptKey(key []byte) {
+	prompt := u.prompt
+	text := string(key)
+	switch {
+	case text == "\033" || text == "\x03":
+		u.prompt = nil
+	case !prompt.digits:
+		u.prompt = nil
+		if text == "y" || text == "Y" {
+			u.runAction(prompt)
+		}
+	case text == "\r":
+		u.prompt = nil
+		u.runAction(prompt)
+	case text == "\x7f" || text == "\b":
+		if prompt.input != "" {
+			prompt.input = prompt.input[:len(prompt.input)-1]
+		}
+	case strings.Trim(text, "0123456789") == "":
+		prompt.input += text
+	}
+}
+
+func (u *dashboard) runAction(prompt *uiPrompt) {
+	message, err := prompt.action(prompt.input)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		message = err.Error()
+	}
+	u.refresh()
+	u.setStatus(message)
+}
+
+// moveCursor moves the cursor of the pane with the focus, the events follow
+// the selected process type.
+func (u *dashboard) moveCursor(delta int) {
+	u.mu.Lock()
+	u.cursor[u.pane] = max(min(u.cursor[u.pane]+delta, len(u.rows[u.pane])-1), 0)
+	pane := u.pane
+	u.mu.Unlock()
+	if pane == uiPtypes {
+		u.refresh()
+	}
+}
+
+func (u *dashboard) setStatus(status string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.status = status
+}
+
+// followLogs tails the logs of a pod in the background.
+func (u *dashboard) followLogs(pod string) {
+	u.closeLogs()
+	conn, err := ps.Logs(u.client, u.appID, pod, api.PodLogsRequest{Lines: 100, Follow: true})
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.logPod = pod
+	u.logs = nil
+	if err != nil {
+		u.status = err.Error()
+		return
+	}
+	u.logConn = conn
+	go func() {
+		for {
+			var message string
+			if err := websocket.Message.Receive(conn, &message); err != nil {
+				return
+			}
+			u.mu.Lock()
+			if u.logConn != conn {
+				u.mu.Unlock()
+				return
+			}
+			u.logs = append(u.logs, strings.Split(strings.TrimRight(message, "\n"), "\n")...)
+			if len(u.logs) > uiLogLines {
+				u.logs = slices.Clone(u.logs[len(u.logs)-uiLogLines:])
+			}
+			u.mu.Unlock()
+			select {
+			case u.redraw <- struct{}{}:
+			default:
+			}
+		}
+	}()
+}
+
+func (u *dashboard) closeLogs() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.logConn != nil {
+		u.logConn.Close()
+		u.logConn = nil
+	}
+}
+
+// exec runs a shell in a pod on the whole screen, the keys typed are sent to
+// the shell until it exits.
+func (u *dashboard) exec(pod string) {
+	io.WriteString(u.out, uiLeaveScreen)
+	defer io.WriteString(u.out, uiEnterScreen)
+	conn, err := ps.Exec(u.client, u.appID, pod, api.Command{Tty: true, Stdin: true, Command: []string{"sh"}})
+	if err != nil {
+		u.setStatus(err.Error())
+		return
+	}
+	defer conn.Close()
+	if c, err := console.ConsoleFromFile(os.Stdout); err == nil {
+		runResizeTask(conn, c)
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case key, ok := <-u.keys:
+				if !ok {
+					pw.Close()
+					return
+				}
+				if _, err := pw.Write(key); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	err = pipeExec(conn, pr, u.out)
+	pw.Close()
+	close(done)
+	if err != nil {
+		u.setStatus(err.Error())
+	}
+}
+
+// draw renders the dashboard over the screen.
+func (u *dashboard) draw() {
+	width, height := u.size()
+	lines := u.render(width, height)
+	io.WriteString(u.out, "\033[H"+strings.Join(lines, "\033[K\r\n")+"\033[K\033[J")
+}
+
+// render returns the lines of the dashboard for a screen, from top to
+// bottom: a title, the panes and the help or the prompt.
+func (u *dashboard) render(width, height int) []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	eventLines := make([]string, len(u.events))
+	if len(u.events) > 0 {
+		var b bytes.Buffer
+		table := newFormatTable(&b, []string{})
+		for _, event := range u.events {
+			table.Append([]string{event.Reason, event.Message, u.d.formatTime(event.Created)})
+		}
+		table.Render()
+		eventLines = strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	}
+
+	// the sizes of the panes with their title, the logs take the rest
+	sizes := []int{
+		min(len(u.rows[uiProcesses])+2, 10),
+		min(len(u.rows[uiPtypes])+2, 8),
+		min(len(u.rows[uiReleases])+2, 8),
+		min(len(eventLines)+1, 7),
+	}
+	available := height - 2
+	for sum(sizes)+4 > available {
+		largest := 0
+		for i, size := range sizes {
+			if size > sizes[largest] {
+				largest = i
+			}
+		}
+		if sizes[largest] <= 2 {
+			break
+		}
+		sizes[largest]--
+	}
+
+	title := fmt.Sprintf(" drycc ui: %s    updated %s", u.appID, u.updated.Format(time.TimeOnly))
+	if u.status != "" {
+		title += "    " + u.status
+	}
+	lines := []string{u.d.colorize("BoldWhite", fit(title, width))}
+	for pane := range uiPanes {
+		lines = append(lines, u.renderPane(pane, sizes[pane], width)...)
+	}
+
+	ptype := u.selectedLocked(uiPtypes)
+	lines = append(lines, u.d.colorize("BoldCyan", fit(fmt.Sprintf(" Events of %s", ptype), width)))
+	eventLines = eventLines[max(len(eventLines)-(sizes[3]-1), 0):]
+	for _, line := range eventLines {
+		line = fit("  "+line, width)
+		if slices.ContainsFunc(troubleReasons, func(reason string) bool { return strings.HasPrefix(line, "  "+reason+" ") }) {
+			line = u.d.colorize("Red", line)
+		}
+		lines = append(lines, line)
+	}
+
+	logSize := available - sum(sizes) - 1
+	if logSize >= 0 {
+		lines = append(lines, u.d.colorize("BoldCyan", fit(fmt.Sprintf(" Logs of %s", u.logPod), width)))
+		for _, line := range u.logs[max(len(u.logs)-logSize, 0):] {
+			lines = append(lines, fit("  "+line, width))
+		}
+		for range logSize - min(len(u.logs), logSize) {
+			lines = append(lines, "")
+		}
+	}
+
+	footer := uiHelp
+	if u.prompt != nil {
+		footer = u.prompt.label + u.prompt.input
+	}
+	lines = append(lines, u.d.colorize("BoldWhite", fit(" "+footer, width)))
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+	return lines
+}
+
+// renderPane renders the rows of a pane that fit in size lines, with the
+// selected row marked when the pane has the focus.
+func (u *dashboard) renderPane(pane, size, width int) []string {
+	marker := " "
+	if pane == u.pane {
+		marker = ">"
+	}
+	lines := []string{u.d.colorize("BoldCyan", fit(marker+uiPaneTitles[pane], width))}
+	if size < 2 {
+		return lines
+	}
+	rows := u.rows[pane]
+	if len(rows) == 0 {
+		return append(lines, "  (none)")
+	}
+	var b bytes.Buffer
+	table := newFormatTable(&b, [][]string{processHeaders, processTypeHeaders, releaseHeaders}[pane])
+	table.AppendBulk(rows)
+	table.Render()
+	text := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	lines = append(lines, fit("  "+text[0], width))
+
+	visible := size - 2
+	cursor := u.cursor[pane]
+	offset := max(cursor-visible+1, 0)
+	for i := offset; i < min(offset+visible, len(rows)); i++ {
+		prefix := "  "
+		if i == cursor && pane == u.pane {
+			prefix = "> "
+		}
+		line := fit(prefix+text[i+1], width)
+		switch {
+		case i == cursor && pane == u.pane:
+			line = u.d.colorize("BoldYellow", line)
+		case slices.ContainsFunc(rows[i], func(cell string) bool { return slices.Contains(troubleStates, cell) }):
+			line = u.d.colorize("Red", line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit cuts a line to a width and trims its trailing spaces.
+func fit(line string, width int) string {
+	if runes := []rune(line); len(runes) > width {
+		line = string(runes[:max(width, 0)])
+	}
+	return strings.TrimRight(line, " ")
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
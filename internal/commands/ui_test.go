@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func newTestDashboard(t *testing.T) (*dashboard, *testutil.TestServer, *bytes.Buffer) {
+	cf, server, err := testutil.NewTestServerAndClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	cmdr := &DryccCmd{WOut: &b, ConfigFile: cf}
+	_, s, err := loader.LoadAppSettings(cf, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Mux.HandleFunc("/v2/apps/foo/pods/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"count": 2,
+			"next": null,
+			"previous": null,
+			"results": [
+				{"release": "v2", "type": "web", "name": "foo-web-1", "state": "up", "ready": "1/1", "restarts": 0, "started": "2016-02-13T00:47:52"},
+				{"release": "v2", "type": "worker", "name": "foo-worker-1", "state": "CrashLoopBackOff", "ready": "0/1", "restarts": 4, "started": "2016-02-13T00:47:52"}
+			]
+		}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/ptypes/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"count": 2,
+			"next": null,
+			"previous": null,
+			"results": [
+				{"name": "web", "release": "v2", "ready": "1/1", "up_to_date": 1, "available_replicas": 1, "started": "2016-02-13T00:47:52", "garbage": false},
+				{"name": "worker", "release": "v2", "ready": "0/1", "up_to_date": 1, "available_replicas": 0, "started": "2016-02-13T00:47:52", "garbage": false}
+			]
+		}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/releases/", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.SetHeaders(w)
+		fmt.Fprintf(w, `{
+			"count": 2,
+			"next": null,
+			"previous": null,
+			"results": [
+				{"state": "succeed", "version": 2, "created": "2016-02-13T00:47:52Z", "summary": "bob changed PORT"},
+				{"state": "succeed", "version": 1, "created": "2016-02-12T00:47:52Z", "summary": "bob created foo"}
+			]
+		}`)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/events/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.SetHeaders(w)
+		if r.URL.Query().Get("ptype") != "foo-worker" {
+			fmt.Fprintf(w, `{"count": 0, "next": null, "previous": null, "results": []}`)
+			return
+		}
+		fmt.Fprintf(w, `{
+			"count": 2,
+			"next": null,
+			"previous": null,
+			"results": [
+				{"reason": "BackOff", "message": "Back-off restarting failed container", "created": "2016-02-13T00:48:52Z"},
+				{"reason": "Started", "message": "Started container worker", "created": "2016-02-13T00:47:52Z"}
+			]
+		}`)
+	})
+
+	return newDashboard(cmdr, s.Client, "foo", &b), server, &b
+}
+
+func TestDashboardRender(t *testing.T) {
+	t.Parallel()
+	u, server, _ := newTestDashboard(t)
+	defer server.Close()
+
+	u.refresh()
+	u.handleKey([]byte("\t"))
+	u.handleKey([]byte("j"))
+	u.updated = time.Date(2016, 2, 13, 1, 2, 3, 0, time.UTC)
+	u.logPod = "foo-web-1"
+	u.logs = []string{"hello", "world"}
+
+	assert.Equal(t, strings.Join(u.render(100, 24), "\n"), ` drycc ui: foo    updated 01:02:03
+ Processes
+  NAME            RELEASE    STATE               PTYPE     READY    RESTARTS    STARTED
+  foo-web-1       v2         up                  web       1/1      0           2016-02-13T00:47:52
+  foo-worker-1    v2         CrashLoopBackOff    worker    0/1      4           2016-02-13T00:47:52
+>Process types
+  NAME      RELEASE    READY    UP-TO-DATE    AVAILABLE    STARTED                GARBAGE
+  web       v2         1/1      1             1            2016-02-13T00:47:52    false
+> worker    v2         0/1      1             0            2016-02-13T00:47:52    false
+ Releases
+  STATE      VERSION    CREATED                 SUMMARY
+  succeed    v2         2016-02-13T00:47:52Z    bob changed PORT
+  succeed    v1         2016-02-12T00:47:52Z    bob created foo
+ Events of worker
+  Started    Started container worker                2016-02-13T00:47:52Z
+  BackOff    Back-off restarting failed container    2016-02-13T00:48:52Z
+ Logs of foo-web-1
+  hello
+  world
+
+
+
+
+ tab pane  ↑↓ select  l logs  e exec  s scale  r restart  b rollback  R refresh  q quit`, "output")
+
+	// the panes shrink to leave room for the logs
+	lines := u.render(100, 16)
+	assert.Len(t, lines, 16)
+	assert.Equal(t, lines[11], " Logs of foo-web-1")
+}
+
+func TestDashboardActions(t *testing.T) {
+	t.Parallel()
+	u, server, _ := newTestDashboard(t)
+	defer server.Close()
+
+	server.Mux.HandleFunc("/v2/apps/foo/ptypes/scale/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.AssertBody(t, map[string]int{"worker": 3}, r)
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/ptypes/restart/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.AssertBody(t, map[string]string{"ptypes": "worker"}, r)
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server.Mux.HandleFunc("/v2/apps/foo/releases/rollback/", func(w http.ResponseWriter, r *http.Request) {
+		testutil.AssertBody(t, api.ReleaseRollback{Version: 1}, r)
+		testutil.SetHeaders(w)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"version": 3}`)
+	})
+
+	u.refresh()
+	for _, key := range []string{"\t", "j", "s", "1", "x", "\x7f", "3", "\r"} {
+		assert.False(t, u.handleKey([]byte(key)))
+	}
+	assert.Nil(t, u.prompt)
+	assert.Equal(t, u.status, "Scaled worker to 3")
+
+	u.handleKey([]byte("r"))
+	assert.Equal(t, u.prompt.label, "Restart worker? (y/N) ")
+	u.handleKey([]byte("n"))
+	assert.Nil(t, u.prompt)
+	u.handleKey([]byte("r"))
+	u.handleKey([]byte("y"))
+	assert.Equal(t, u.status, "Restarted worker")
+
+	for _, key := range []string{"\t", "\033[B", "\033[B", "b", "y"} {
+		u.handleKey([]byte(key))
+	}
+	assert.Equal(t, u.status, "Rolled back to v1, v3")
+
+	u.handleKey([]byte("s"))
+	u.handleKey([]byte("\033"))
+	assert.Nil(t, u.prompt)
+	assert.True(t, u.handleKey([]byte("q")))
+}
+
+func TestDashboardLogs(t *testing.T) {
+	t.Parallel()
+	u, server, _ := newTestDashboard(t)
+	defer server.Close()
+
+	server.Mux.Handle(
+		"/v2/apps/foo/pods/foo-worker-1/logs/",
+		websocket.Handler(func(conn *websocket.Conn) {
+			websocket.Message.Send(conn, "hello\nworld\n")
+			conn.WriteClose(100)
+		}),
+	)
+
+	u.refresh()
+	u.handleKey([]byte("j"))
+	u.handleKey([]byte("l"))
+	defer u.closeLogs()
+	select {
+	case <-u.redraw:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no logs")
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	assert.Equal(t, u.logPod, "foo-worker-1")
+	assert.Equal(t, u.logs, []string{"hello", "world"})
+}
@@ -12,6 +12,7 @@ import (
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/workflow-cli/pkg/printer"
 	"github.com/olekukonko/tablewriter"
 	yaml "gopkg.in/yaml.v3"
 )
@@ -22,10 +23,17 @@ var (
 )
 
 func progress(wOut io.Writer) chan bool {
+	quit := make(chan bool)
+	if _, ok := wOut.(*batchOutput); ok {
+		go func() {
+			<-quit
+			close(quit)
+		}()
+		return quit
+	}
 	frames := []string{"...", "o..", ".o.", "..o"}
 	backspaces := strings.Repeat("\b", 3)
 	tick := time.NewTicker(400 * time.Millisecond)
-	quit := make(chan bool)
 	go func() {
 		for {
 			for _, frame := range frames {
@@ -83,7 +91,12 @@ func (d *DryccCmd) checkAPICompatibility(c *drycc.Client, err error) error {
 
 // getDefaultFormatTable return default format ascii table
 func (d *DryccCmd) getDefaultFormatTable(headers []string) *tablewriter.Table {
-	table := tablewriter.NewWriter(d.WOut)
+	return newFormatTable(d.WOut, headers)
+}
+
+// newFormatTable returns a table in the default format that renders to w.
+func newFormatTable(w io.Writer, headers []string) *tablewriter.Table {
+	table := tablewriter.NewWriter(w)
 	table.SetHeader(headers)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
 	table.SetAutoWrapText(false)
@@ -100,6 +113,15 @@ func (d *DryccCmd) getDefaultFormatTable(headers []string) *tablewriter.Table {
 	return table
 }
 
+// printObject renders obj with the output format selected by the --output flag.
+func (d *DryccCmd) printObject(obj any) error {
+	p, err := printer.New(d.Output)
+	if err != nil {
+		return err
+	}
+	return p.Print(d.WOut, obj)
+}
+
 // format time string to local time
 func (d *DryccCmd) formatTime(timeStr string) string {
 	t, err := time.Parse(time.RFC3339, timeStr)
@@ -182,3 +204,16 @@ func ResponseLimit(limit int) (int, error) {
 	}
 	return limit, nil
 }
+
+// filterPtype returns the entries of a per-ptype map that belong to ptype,
+// or the whole map when ptype is empty.
+func filterPtype[T any](values map[string]T, ptype string) map[string]T {
+	if ptype == "" {
+		return values
+	}
+	filtered := make(map[string]T)
+	if value, ok := values[ptype]; ok {
+		filtered[ptype] = value
+	}
+	return filtered
+}
@@ -29,6 +29,10 @@ func (d *DryccCmd) VolumesList(appID string, results int) error {
 		return err
 	}
 
+	if d.Output != "" {
+		return d.printObject(volumes)
+	}
+
 	if count == 0 {
 		d.Println("Could not find any volume.")
 	} else {
@@ -48,6 +52,11 @@ func (d *DryccCmd) VolumesInfo(appID, name string) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(volume)
+	}
+
 	table := d.getDefaultFormatTable([]string{})
 	table.Append([]string{"UUID:", volume.UUID})
 	table.Append([]string{"Name:", volume.Name})
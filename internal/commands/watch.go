@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// troubleStates are the cell values highlighted in red by watch.
+var troubleStates = []string{
+	"CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerConfigError",
+	"Error", "Failed", "OOMKilled", "Evicted", "Unknown", "failed", "crashed",
+}
+
+// watchTable is a snapshot of a list printed by watch.
+type watchTable struct {
+	headers []string
+	rows    [][]string
+	// key is the column identifying the rows between snapshots.
+	key int
+	// empty is printed when there are no rows.
+	empty string
+	// object is printed instead of the table with --output.
+	object any
+}
+
+// watch polls a list every interval until interrupted. On a terminal the
+// table is redrawn in place with its new rows in green, its changed cells in
+// yellow and the cells in trouble in red. Otherwise the table is printed once,
+// then only its new and changed rows.
+func (d *DryccCmd) watch(interval time.Duration, poll func() (watchTable, error)) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return d.watchTables(ctx, interval, poll)
+}
+
+func (d *DryccCmd) watchTables(ctx context.Context, interval time.Duration, poll func() (watchTable, error)) error {
+	var previous map[string][]string
+	tty := d.isTerminal()
+	lines := 0
+	for {
+		table, err := poll()
+		if err != nil {
+			return err
+		}
+		current := make(map[string][]string, len(table.rows))
+		for _, row := range table.rows {
+			current[row[table.key]] = row
+		}
+
+		switch {
+		case d.Output != "":
+			if previous == nil || !maps.EqualFunc(previous, current, slices.Equal[[]string]) {
+				if err := d.printObject(table.object); err != nil {
+					return err
+				}
+			}
+		case tty:
+			var b bytes.Buffer
+			d.renderWatchTable(&b, table, previous, true)
+			// move up to the previous table and clear it
+			if lines > 0 {
+				d.Printf("\033[%dA\033[J", lines)
+			}
+			lines = strings.Count(b.String(), "\n")
+			d.Print(b.String())
+		default:
+			d.renderWatchTable(d.WOut, table, previous, false)
+		}
+		previous = current
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// renderWatchTable renders the rows of a table that are new or changed since
+// previous, or all of them with all.
+func (d *DryccCmd) renderWatchTable(w io.Writer, table watchTable, previous map[string][]string, all bool) {
+	if len(table.rows) == 0 {
+		if all || previous == nil || len(previous) > 0 {
+			io.WriteString(w, table.empty+"\n")
+		}
+		return
+	}
+	headers := table.headers
+	if !all && previous != nil {
+		headers = []string{}
+	}
+	t := newFormatTable(w, headers)
+	rendered := false
+	for _, row := range table.rows {
+		old, found := previous[row[table.key]]
+		if !all && found && slices.Equal(old, row) {
+			continue
+		}
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			switch {
+			case slices.Contains(troubleStates, cell):
+				cells[i] = d.colorize("Red", cell)
+			case previous != nil && !found:
+				cells[i] = d.colorize("Green", cell)
+			case found && i < len(old) && old[i] != cell:
+				cells[i] = d.colorize("Yellow", cell)
+			default:
+				cells[i] = cell
+			}
+		}
+		t.Append(cells)
+		rendered = true
+	}
+	if rendered {
+		t.Render()
+	}
+}
+
+// watchEvents prints the events that were not seen yet every interval until
+// interrupted, the events in trouble in red.
+func (d *DryccCmd) watchEvents(interval time.Duration, seen api.AppEvents, list func() (api.AppEvents, error)) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return d.streamEvents(ctx, interval, seen, list)
+}
+
+func (d *DryccCmd) streamEvents(ctx context.Context, interval time.Duration, seen api.AppEvents, list func() (api.AppEvents, error)) error {
+	keys := make(map[string]bool)
+	for _, event := range seen {
+		keys[eventKey(event)] = true
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+		events, err := list()
+		if err != nil {
+			return err
+		}
+		var fresh api.AppEvents
+		for _, event := range events {
+			if key := eventKey(event); !keys[key] {
+				keys[key] = true
+				fresh = append(fresh, event)
+			}
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+		slices.SortStableFunc(fresh, func(a, b api.AppEvent) int {
+			return strings.Compare(a.Created, b.Created)
+		})
+		if d.Output != "" {
+			for _, event := range fresh {
+				if err := d.printObject(event); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		table := d.getDefaultFormatTable([]string{})
+		for _, event := range fresh {
+			reason := d.indentString(event.Reason, 2)
+			if slices.Contains(troubleReasons, event.Reason) {
+				reason = d.colorize("Red", reason)
+			}
+			table.Append([]string{reason, event.Message, d.formatTime(event.Created)})
+		}
+		table.Render()
+	}
+}
+
+// eventKey identifies an event between two polls.
+func eventKey(event api.AppEvent) string {
+	return event.Reason + "\x00" + event.Message + "\x00" + event.Created
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchTables(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b}
+
+	snapshots := [][][]string{
+		{{"foo-web-1", "v1", "Running"}, {"foo-worker-1", "v1", "Running"}},
+		{{"foo-web-1", "v1", "Running"}, {"foo-worker-1", "v1", "Running"}},
+		{{"foo-web-1", "v1", "CrashLoopBackOff"}, {"foo-worker-1", "v1", "Running"}, {"foo-web-2", "v2", "Starting"}},
+		{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	polls := 0
+	err := cmdr.watchTables(ctx, time.Millisecond, func() (watchTable, error) {
+		rows := snapshots[polls]
+		polls++
+		if polls == len(snapshots) {
+			cancel()
+		}
+		return watchTable{
+			headers: []string{"NAME", "RELEASE", "STATE"},
+			rows:    rows,
+			empty:   "No processes found in foo app.",
+		}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, polls, len(snapshots))
+	assert.Equal(t, b.String(), `NAME            RELEASE    STATE   
+foo-web-1       v1         Running    
+foo-worker-1    v1         Running    
+foo-web-1    v1    CrashLoopBackOff    
+foo-web-2    v2    Starting            
+No processes found in foo app.
+`, "output")
+}
+
+func TestWatchTablesOutput(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b, Output: "json"}
+
+	snapshots := []string{"v1", "v1", "v2"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	polls := 0
+	err := cmdr.watchTables(ctx, time.Millisecond, func() (watchTable, error) {
+		version := snapshots[polls]
+		polls++
+		if polls == len(snapshots) {
+			cancel()
+		}
+		return watchTable{
+			rows:   [][]string{{"web", version}},
+			object: map[string]string{"web": version},
+		}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), "{\n  \"web\": \"v1\"\n}\n{\n  \"web\": \"v2\"\n}\n", "output")
+}
+
+func TestRenderWatchTable(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b}
+
+	table := watchTable{
+		headers: []string{"STATE", "VERSION"},
+		rows:    [][]string{{"failed", "v2"}, {"succeed", "v1"}},
+		key:     1,
+	}
+	cmdr.renderWatchTable(&b, table, map[string][]string{"v1": {"succeed", "v1"}}, true)
+	assert.Equal(t, b.String(), `STATE      VERSION 
+failed     v2         
+succeed    v1         
+`, "output")
+}
+
+func TestStreamEvents(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	cmdr := DryccCmd{WOut: &b}
+
+	seen := api.AppEvents{{Reason: "Started", Message: "Started container web", Created: "2024-01-01T00:00:00Z"}}
+	snapshots := []api.AppEvents{
+		seen,
+		append(api.AppEvents{
+			{Reason: "BackOff", Message: "Back-off restarting failed container", Created: "2024-01-01T00:00:02Z"},
+			{Reason: "Killing", Message: "Stopping container web", Created: "2024-01-01T00:00:01Z"},
+		}, seen...),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	polls := 0
+	err := cmdr.streamEvents(ctx, time.Millisecond, seen, func() (api.AppEvents, error) {
+		events := snapshots[polls]
+		polls++
+		if polls == len(snapshots) {
+			cancel()
+		}
+		return events, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), `  Killing    Stopping container web                  2024-01-01T00:00:01Z    
+  BackOff    Back-off restarting failed container    2024-01-01T00:00:02Z    
+`, "output")
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 
+	"github.com/drycc/controller-sdk-go/api"
 	"github.com/drycc/controller-sdk-go/workspaces"
 	"github.com/drycc/controller-sdk-go/workspaces/invitations"
 	"github.com/drycc/controller-sdk-go/workspaces/members"
@@ -24,6 +25,11 @@ func (d *DryccCmd) WorkspacesList(results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(wkspaces)
+	}
+
 	if count > 0 {
 		table := d.getDefaultFormatTable([]string{"NAME", "EMAIL", "CREATED", "UPDATED"})
 		for _, ws := range wkspaces {
@@ -61,6 +67,12 @@ func (d *DryccCmd) WorkspacesCreate(name, email string) error {
 	return nil
 }
 
+// workspaceInfo is the machine-readable form of the workspaces:info output.
+type workspaceInfo struct {
+	api.Workspace
+	Members api.WorkspaceMembers `json:"members"`
+}
+
 // WorkspacesInfo shows detailed information about a workspace.
 func (d *DryccCmd) WorkspacesInfo(name string, results int) error {
 	s, err := settings.Load(d.ConfigFile)
@@ -73,12 +85,6 @@ func (d *DryccCmd) WorkspacesInfo(name string, results int) error {
 		return err
 	}
 
-	table := d.getDefaultFormatTable([]string{})
-	table.Append([]string{"Name:", ws.ID})
-	table.Append([]string{"Email:", ws.Email})
-	table.Append([]string{"Created:", d.formatTime(ws.Created)})
-	table.Append([]string{"Updated:", d.formatTime(ws.Updated)})
-
 	if results == defaultLimit {
 		results = s.Limit
 	}
@@ -88,6 +94,18 @@ func (d *DryccCmd) WorkspacesInfo(name string, results int) error {
 	if d.checkAPICompatibility(s.Client, err) != nil {
 		return err
 	}
+
+	if d.Output != "" {
+		return d.printObject(workspaceInfo{Workspace: ws, Members: mems})
+	}
+
+	table := d.getDefaultFormatTable([]string{})
+	table.Append([]string{"Name:", ws.ID})
+	table.Append([]string{"Email:", ws.Email})
+	table.Append([]string{"Created:", d.formatTime(ws.Created)})
+	table.Append([]string{"Updated:", d.formatTime(ws.Updated)})
+
+	// print members
 	if len(mems) > 0 {
 		table.Append([]string{"Members:"})
 		for index, m := range mems {
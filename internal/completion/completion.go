@@ -25,6 +25,7 @@ import (
 	"github.com/drycc/controller-sdk-go/workspaces"
 	"github.com/drycc/controller-sdk-go/workspaces/members"
 	"github.com/drycc/workflow-cli/internal/loader"
+	"github.com/drycc/workflow-cli/pkg/printer"
 	"github.com/drycc/workflow-cli/pkg/settings"
 	"github.com/spf13/cobra"
 )
@@ -34,6 +35,28 @@ type Completion interface {
 	CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
 }
 
+// loadSettings loads the settings of a completion, whose requests are served
+// from the cache while it is fresh.
+func loadSettings(cf string) (*settings.Settings, error) {
+	s, err := settings.Load(cf)
+	if err != nil {
+		return nil, err
+	}
+	s.UseCache()
+	return s, nil
+}
+
+// loadAppSettings loads the settings of a completion of an app, see
+// loadSettings.
+func loadAppSettings(cf, appID string) (string, *settings.Settings, error) {
+	appID, s, err := loader.LoadAppSettings(cf, appID)
+	if err != nil {
+		return "", nil, err
+	}
+	s.UseCache()
+	return appID, s, nil
+}
+
 // AppCompletion provides completion for application names
 type AppCompletion struct {
 	ArgsLen    int
@@ -42,7 +65,7 @@ type AppCompletion struct {
 
 // CompletionFunc returns a list of application names for completion
 func (c *AppCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if s, err := settings.Load(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if s, err := loadSettings(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if s.Workspace != "" {
 			if apps, _, err := apps.List(s.Client, s.Workspace, -1); err == nil {
 				var results []string
@@ -67,7 +90,7 @@ type CertCompletion struct {
 
 // CompletionFunc returns a list of certificate names for completion
 func (c *CertCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if certs, _, err := certs.List(s.Client, appID, -1); err == nil {
 			var results []string
 			for _, cert := range certs {
@@ -110,7 +133,7 @@ func (c *ConfigPtsGroupArgsCompletion) CompletionFunc(cmd *cobra.Command, args [
 		return ptsCompletion.CompletionFunc(cmd, args, toComplete)
 	}
 	groups := args[1:]
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil {
 		if config, err := config.List(s.Client, appID, -1); err == nil {
 			var results []string
 			for _, value := range config.Values {
@@ -135,7 +158,7 @@ type ConfigGroupCompletion struct {
 
 // CompletionFunc returns a list of config group names for completion
 func (c *ConfigGroupCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if config, err := config.List(s.Client, appID, -1); err == nil {
 			var results []string
 			for _, value := range config.Values {
@@ -159,7 +182,7 @@ type DomainCompletion struct {
 
 // CompletionFunc returns a list of domain names for completion
 func (c *DomainCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if domains, _, err := domains.List(s.Client, appID, -1); err == nil {
 			var results []string
 			for _, domain := range domains {
@@ -206,7 +229,7 @@ type GatewayNameCompletion struct {
 // CompletionFunc returns a list of gateway names for completion
 func (c *GatewayNameCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	var results []string
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if gateways, _, err := gateways.List(s.Client, appID, -1); err == nil {
 			for _, gateway := range gateways {
 				if strings.HasPrefix(gateway.Name, toComplete) {
@@ -372,7 +395,7 @@ type KeyCompletion struct {
 
 // CompletionFunc returns a list of SSH key names for completion
 func (c *KeyCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if s, err := settings.Load(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if s, err := loadSettings(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if keys, _, err := keys.List(s.Client, -1); err == nil {
 			var results []string
 			for _, key := range keys {
@@ -386,6 +409,26 @@ func (c *KeyCompletion) CompletionFunc(_ *cobra.Command, args []string, toComple
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
 
+// ContextCompletion provides completion for context names
+type ContextCompletion struct {
+	ArgsLen    int
+	ConfigFile *string
+}
+
+// CompletionFunc returns a list of context names for completion
+func (c *ContextCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if contexts, err := settings.ListContexts(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+		var results []string
+		for _, context := range contexts {
+			if strings.HasPrefix(context.Name, toComplete) {
+				results = append(results, context.Name)
+			}
+		}
+		return results, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
 // TokenCompletion provides completion for tokens
 type TokenCompletion struct {
 	ArgsLen    int
@@ -394,7 +437,7 @@ type TokenCompletion struct {
 
 // CompletionFunc returns a list of tokens for completion
 func (c *TokenCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if s, err := settings.Load(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if s, err := loadSettings(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if tokens, _, err := tokens.List(s.Client, -1); err == nil {
 			var results []string
 			for _, token := range tokens {
@@ -417,7 +460,7 @@ type LabelCompletion struct {
 
 // CompletionFunc returns a list of label names for completion
 func (c *LabelCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if appsettings, err := appsettings.List(s.Client, appID); err == nil {
 			var results []string
 			for key := range appsettings.Label {
@@ -441,7 +484,7 @@ type LimitSpecCompletion struct {
 
 // CompletionFunc returns a list of limit specifications for completion
 func (c *LimitSpecCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if s, err := settings.Load(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if s, err := loadSettings(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if specs, _, err := limits.Specs(s.Client, toComplete, -1); err == nil {
 			var results []string
 			for _, sepc := range specs {
@@ -465,7 +508,7 @@ func (c *LimitSetPlanCompletion) CompletionFunc(cmd *cobra.Command, args []strin
 	if strings.Contains(toComplete, "=") {
 		var results []string
 		parts := strings.Split(toComplete, "=")
-		if s, err := settings.Load(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+		if s, err := loadSettings(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 			if plans, _, err := limits.Plans(s.Client, "", 0, 0, -1); err == nil {
 				for _, plan := range plans {
 					if strings.HasPrefix(plan.ID, parts[1]) {
@@ -490,7 +533,7 @@ type WorkspaceCompletion struct {
 
 // CompletionFunc returns a list of workspace names for completion
 func (c *WorkspaceCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if s, err := settings.Load(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if s, err := loadSettings(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if wkspaces, _, err := workspaces.List(s.Client, -1); err == nil {
 			var results []string
 			for _, ws := range wkspaces {
@@ -518,7 +561,7 @@ func (c *WorkspaceMemberCompletion) CompletionFunc(_ *cobra.Command, args []stri
 		workspace = args[0]
 	}
 	if workspace != "" {
-		if s, err := settings.Load(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+		if s, err := loadSettings(*c.ConfigFile); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 			if mems, _, err := members.List(s.Client, workspace, -1); err == nil {
 				var results []string
 				for _, m := range mems {
@@ -599,7 +642,7 @@ type PsCompletion struct {
 
 // CompletionFunc returns a list of process names for completion
 func (c *PsCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if pods, _, err := ps.List(s.Client, appID, -1); err == nil {
 			var results []string
 			for _, pod := range pods {
@@ -623,7 +666,7 @@ type PtsCompletion struct {
 // CompletionFunc returns a list of pts names for completion
 func (c *PtsCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	var results []string
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if ptypes, _, err := pts.List(s.Client, appID, -1); err == nil {
 			for _, ptype := range ptypes {
 				if strings.HasPrefix(ptype.Name, toComplete) {
@@ -689,7 +732,7 @@ type ReleaseCompletion struct {
 
 // CompletionFunc returns a list of release versions for completion.
 func (c *ReleaseCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if releases, _, err := releases.List(s.Client, appID, "", -1); err == nil {
 			var results []string
 			toComplete = strings.TrimPrefix(toComplete, "v")
@@ -737,7 +780,7 @@ type RouteCompletion struct {
 
 // CompletionFunc returns a list of routes for completion
 func (c *RouteCompletion) CompletionFunc(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if routes, _, err := routes.List(s.Client, appID, -1); err == nil {
 			var results []string
 			for _, route := range routes {
@@ -758,7 +801,7 @@ type ServiceCompletion struct {
 
 // CompletionFunc returns a list of services for completion
 func (c *ServiceCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if services, err := services.List(s.Client, appID); err == nil {
 			var results []string
 			for _, service := range services {
@@ -782,7 +825,7 @@ type TagCompletion struct {
 
 // CompletionFunc returns a list of tags for completion
 func (c *TagCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if config, err := config.List(s.Client, appID, -1); err == nil {
 			var results []string
 			for tag := range config.Tags[*c.Ptype] {
@@ -828,7 +871,7 @@ type VolumeCompletion struct {
 
 // CompletionFunc returns a list of volumes for completion
 func (c *VolumeCompletion) CompletionFunc(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if appID, s, err := loader.LoadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
+	if appID, s, err := loadAppSettings(*c.ConfigFile, *c.AppID); err == nil && (c.ArgsLen < 0 || len(args) == c.ArgsLen) {
 		if volumes, _, err := volumes.List(s.Client, appID, -1); err == nil {
 			var results []string
 			for _, volume := range volumes {
@@ -900,3 +943,17 @@ func (c *VolumesUnmountCompletion) CompletionFunc(cmd *cobra.Command, args []str
 	}
 	return ptsArgCompletion.CompletionFunc(cmd, args, toComplete)
 }
+
+// OutputFormatCompletion provides completion for the --output flag
+type OutputFormatCompletion struct{}
+
+// CompletionFunc returns a list of output formats for completion
+func (c *OutputFormatCompletion) CompletionFunc(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var results []string
+	for _, format := range printer.Formats {
+		if strings.HasPrefix(format, toComplete) {
+			results = append(results, format)
+		}
+	}
+	return results, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
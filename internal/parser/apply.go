@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"github.com/drycc/workflow-cli/internal/commands"
+	"github.com/drycc/workflow-cli/internal/completion"
+	"github.com/drycc/workflow-cli/internal/template"
+	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// NewApplyCommand creates the apply command
+func NewApplyCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		files   []string
+		dryRun  bool
+		prune   bool
+		show    bool
+		confirm string
+	}
+	cmd := &cobra.Command{
+		Use:  "apply",
+		Args: cobra.NoArgs,
+		Example: template.CustomExample(
+			"drycc apply -f <path> --dry-run",
+			map[string]string{
+				"<path>": i18n.T("A manifest file, a directory of manifests or '-' for stdin"),
+			},
+		),
+		Short: i18n.T("Apply manifests to an application"),
+		Long: i18n.T(`Applies App, Config, Limits, Timeouts, Tags, Healthcheck, Autoscale, Volume,
+Build, Service, Domain, Cert, Gateway and Route manifests to an application.
+
+The manifests are compared with the live state of the application, the
+planned changes are printed and only the resources that differ are applied.
+Fields missing from a manifest are left untouched, resources missing from the
+manifests are destroyed only with --prune. The config values are printed as
+(sensitive) in the plan, unless --show-values is given.`),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmdr.Apply(app, flags.files, flags.dryRun, flags.prune, flags.show, flags.confirm)
+		},
+	}
+
+	cmd.Flags().StringVarP(&app, "app", "a", "", i18n.T("The application to apply the manifests to, defaults to the name in the manifests"))
+	cmd.Flags().StringArrayVarP(&flags.files, "filename", "f", nil, i18n.T("A manifest file, a directory of manifests or '-' for stdin, may be repeated"))
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, i18n.T("Only print the planned changes"))
+	cmd.Flags().BoolVar(&flags.prune, "prune", false, i18n.T("Destroy resources and config values missing from the manifests"))
+	cmd.Flags().BoolVar(&flags.show, "show-values", false, i18n.T("Print the config values in the plan instead of (sensitive)"))
+	cmd.Flags().StringVar(&flags.confirm, "confirm", "", i18n.T("Skips the prompt before destroying resources. \n<app> is the uniquely identifiable name for the application"))
+	cmd.MarkFlagRequired("filename")
+
+	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
+	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
+	return cmd
+}
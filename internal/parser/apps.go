@@ -8,6 +8,7 @@ import (
 	"github.com/drycc/workflow-cli/internal/completion"
 	"github.com/drycc/workflow-cli/internal/template"
 	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/drycc/workflow-cli/pkg/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +30,9 @@ func NewAppsCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.AddCommand(appsRun(cmdr))
 	cmd.AddCommand(appsDestroy(cmdr))
 	cmd.AddCommand(appsTransfer(cmdr))
+	cmd.AddCommand(appsExport(cmdr))
+	cmd.AddCommand(appsImport(cmdr))
+	cmd.AddCommand(appsDiff(cmdr))
 	return cmd
 }
 
@@ -117,6 +121,7 @@ func appsRun(cmdr *commands.DryccCmd) *cobra.Command {
 		timeout uint32
 		expires uint32
 		mounts  []string
+		detach  bool
 	}
 
 	cmd := &cobra.Command{
@@ -131,20 +136,88 @@ func appsRun(cmdr *commands.DryccCmd) *cobra.Command {
 			},
 		),
 		Short: i18n.T("Run a command in an ephemeral app container"),
-		Long:  i18n.T("Runs a command inside an ephemeral app container"),
+		Long: i18n.T(`Runs a command inside an ephemeral app container.
+
+The stdout and the stderr of the command are mirrored until it ends, and the
+CLI exits with its exit code. Ctrl+C detaches from the command, which keeps running. With --detach
+the CLI returns once the command started, see the list, logs and kill
+subcommands to manage the commands running. A command named list, logs or kill
+follows --.`),
 		RunE: func(_ *cobra.Command, args []string) error {
 			command := strings.Join(args, " ")
-			return cmdr.AppRun(app, command, flags.mounts, flags.timeout, flags.expires)
+			return cmdr.AppRun(app, command, flags.mounts, flags.timeout, flags.expires, flags.detach)
 		},
 	}
 
-	cmd.Flags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
+	cmd.PersistentFlags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
 	cmd.Flags().StringSliceVarP(&flags.mounts, "mount", "m", nil, i18n.T("Volume mounts in format 'volume:path'"))
 	cmd.Flags().Uint32VarP(&flags.timeout, "timeout", "t", 3600, i18n.T("Command execution timeout in seconds"))
 	cmd.Flags().Uint32VarP(&flags.expires, "expires", "e", 3600, i18n.T("Retention time of records in seconds"))
+	cmd.Flags().BoolVarP(&flags.detach, "detach", "d", false, i18n.T("Return once the command started, without printing its logs"))
 
 	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
 	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
+
+	cmd.AddCommand(appsRunList(cmdr))
+	cmd.AddCommand(appsRunLogs(cmdr))
+	cmd.AddCommand(appsRunKill(cmdr))
+	return cmd
+}
+
+func appsRunList(cmdr *commands.DryccCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Example: "drycc run list -a <app>",
+		Short:   i18n.T("List the one-off commands of an application"),
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmdr.RunList(app)
+		},
+	}
+	return cmd
+}
+
+func appsRunLogs(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		lines   int
+		follow  bool
+		options logging.Options
+	}
+
+	psCompletion := completion.PsCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use:               "logs <pod>",
+		Example:           "drycc run logs <pod> -f",
+		Short:             i18n.T("Print the logs of a one-off command"),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: psCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if flags.lines < 0 {
+				flags.lines = -1
+			}
+			return cmdr.PsLogs(app, args[0], flags.lines, flags.follow, "", false, flags.options)
+		},
+	}
+
+	cmd.Flags().IntVarP(&flags.lines, "lines", "l", -1, i18n.T("The number of lines to display, -1 showing all log lines"))
+	cmd.Flags().BoolVarP(&flags.follow, "follow", "f", false, i18n.T("Specify if the logs should be streamed"))
+	addLogFlags(cmd, &flags.options)
+	cmd.Flags().SortFlags = false
+	return cmd
+}
+
+func appsRunKill(cmdr *commands.DryccCmd) *cobra.Command {
+	psCompletion := completion.PsCompletion{AppID: &app, ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use:               "kill <pod>...",
+		Example:           "drycc run kill <pod> -a <app>",
+		Short:             i18n.T("Stop one-off commands of an application"),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: psCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.RunKill(app, args)
+		},
+	}
 	return cmd
 }
 
@@ -193,3 +266,90 @@ func appsTransfer(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
 	return cmd
 }
+
+// AppsExport creates the apps export command
+func appsExport(cmdr *commands.DryccCmd) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use: "export",
+		Example: template.CustomExample(
+			"drycc apps export -a <app> -f <file>",
+			map[string]string{
+				"<app>":  i18n.T("The uniquely identifiable name for the application"),
+				"<file>": i18n.T("Path of the manifest file, defaults to stdout"),
+			},
+		),
+		Short: i18n.T("Export an application as manifests"),
+		Long: i18n.T(`Exports the config, build, limits, healthchecks, autoscale, tags, labels,
+timeouts, volumes, domains, services, gateways and routes of an application
+as a multi-document YAML file that can be loaded with 'drycc apps import'`),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmdr.AppExport(app, file)
+		},
+	}
+
+	cmd.Flags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
+	cmd.Flags().StringVarP(&file, "file", "f", "", i18n.T("Path of the manifest file, defaults to stdout"))
+
+	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
+	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
+	return cmd
+}
+
+// AppsImport creates the apps import command
+func appsImport(cmdr *commands.DryccCmd) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use: "import",
+		Example: template.CustomExample(
+			"drycc apps import -a <app> -f <file>",
+			map[string]string{
+				"<app>":  i18n.T("The application to create or update, defaults to the name in the manifests"),
+				"<file>": i18n.T("Path of the manifest file, '-' reads stdin"),
+			},
+		),
+		Short: i18n.T("Import an application from manifests"),
+		Long: i18n.T(`Creates or updates an application from a file written by 'drycc apps export'.
+The application is created in the current workspace when it does not exist,
+so an app can be cloned to another workspace or controller. Only what differs
+from the manifests is applied, so importing a file twice changes nothing:
+config values, limits, timeouts and tags missing from the manifests are
+unset, other resources missing from them are left untouched`),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmdr.AppImport(app, file)
+		},
+	}
+
+	cmd.Flags().StringVarP(&app, "app", "a", "", i18n.T("The application to create or update, defaults to the name in the manifests"))
+	cmd.Flags().StringVarP(&file, "file", "f", "", i18n.T("Path of the manifest file, '-' reads stdin"))
+	cmd.MarkFlagRequired("file")
+
+	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
+	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
+	return cmd
+}
+
+// AppsDiff creates the apps diff command
+func appsDiff(cmdr *commands.DryccCmd) *cobra.Command {
+	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use:  "diff <app> <app>",
+		Args: cobra.ExactArgs(2),
+		Example: template.CustomExample(
+			"drycc apps diff app-a app-b",
+			map[string]string{
+				"<app>": i18n.T("The uniquely identifiable name for the application"),
+			},
+		),
+		Short: i18n.T("Compare two applications"),
+		Long: i18n.T(`Compares the config values, limits, timeouts, tags, healthchecks and build
+of the latest releases of two applications as a unified diff, use --output json
+for the changed fields`),
+		ValidArgsFunction: appCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.AppsDiff(args[0], args[1])
+		},
+	}
+
+	return cmd
+}
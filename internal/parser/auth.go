@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"os"
+
 	"github.com/drycc/workflow-cli/internal/commands"
 	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/drycc/workflow-cli/pkg/settings"
 	"github.com/spf13/cobra"
 )
 
@@ -22,9 +25,10 @@ func NewAuthCommand(cmdr *commands.DryccCmd) *cobra.Command {
 // AuthLogin creates the auth:login command
 func authLogin(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
-		username  string
-		password  string
-		sslVerify bool
+		username        string
+		password        string
+		sslVerify       bool
+		credentialStore string
 	}
 
 	cmd := &cobra.Command{
@@ -32,15 +36,22 @@ func authLogin(cmdr *commands.DryccCmd) *cobra.Command {
 		Args:    cobra.ExactArgs(1),
 		Example: "drycc auth login http://drycc.local3.dryccapp.com/",
 		Short:   i18n.T("Authenticate against a controller"),
-		Long:    i18n.T("Logs in by authenticating against a controller"),
+		Long: i18n.T(`Logs in by authenticating against a controller.
+
+The token is kept in the encrypted file keyring by default. Its key is the
+passphrase of DRYCC_CREDENTIAL_PASSPHRASE or the key file of
+DRYCC_CREDENTIAL_KEY_FILE, and otherwise a passphrase asked on the terminal.
+Without a terminal, one of them must be set. Use --credential-store <name> to
+keep the token with the drycc-credential-<name> helper, ex: of the OS keyring.`),
 		RunE: func(_ *cobra.Command, args []string) error {
 			controller := args[0]
-			return cmdr.Login(controller, flags.sslVerify, flags.username, flags.password)
+			return cmdr.Login(controller, flags.sslVerify, flags.username, flags.password, flags.credentialStore)
 		},
 	}
 	cmd.Flags().StringVarP(&flags.username, "username", "u", "", i18n.T("Provide a username for the account"))
 	cmd.Flags().StringVarP(&flags.password, "password", "p", "", i18n.T("Provide a password for the account"))
 	cmd.Flags().BoolVar(&flags.sslVerify, "ssl-verify", true, i18n.T("Enables or disables SSL certificate verification for API requests"))
+	cmd.Flags().StringVar(&flags.credentialStore, "credential-store", os.Getenv(settings.CredentialStoreEnv), i18n.T("Where to keep the token, 'file' for the encrypted keyring or <name> for the drycc-credential-<name> helper"))
 	cmd.Flags().SortFlags = false
 	return cmd
 }
@@ -46,6 +46,7 @@ func NewConfigCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.AddCommand(configUnsetCommand(cmdr))
 	cmd.AddCommand(configPullCommand(cmdr))
 	cmd.AddCommand(configPushCommand(cmdr))
+	cmd.AddCommand(configEditCommand(cmdr))
 	cmd.AddCommand(configAttachCommand(cmdr))
 	cmd.AddCommand(configDetachCommand(cmdr))
 	return cmd
@@ -77,14 +78,38 @@ func configSetCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
 		group   string
 		confirm string
+		batch   batchFlags
 	}
 
 	cmd := &cobra.Command{
 		Use:   "set <key>=<value>...",
 		Args:  cobra.MinimumNArgs(1),
 		Short: i18n.T("Set environment variables for an app"),
-		Long:  i18n.T("Sets environment variables for an application or config group"),
+		Long: i18n.T(`Sets environment variables for an application or config group.
+
+The values are set as given, after the quotes removed by the shell. A value
+@<path> is read from the file at <path>, such as a PEM key, and a value - from
+stdin. The arguments starting with # are comments, they are skipped.
+
+A value ref+<scheme>://<path>[#<field>] is a secret reference, resolved by
+the client before the value is set:
+
+  ref+file://secrets/db.txt#password   the field password of the file
+  ref+env://DB_URL                     the environment variable DB_URL
+  ref+exec://pass show db              the output of the command
+
+The other schemes are resolved by the plugins named drycc-secret-<scheme> in
+the PATH, such as drycc-secret-vault, run with the reference as argument and
+printing the secret.
+
+With --selector or --all-apps, the values are read once and set on all the
+apps selected, a value - then needs --confirm yes as stdin holds the value.`),
+		Example: "drycc config set MODE=production TLS_KEY=@tls.key PASSWORD=- < password.txt",
 		RunE: func(_ *cobra.Command, args []string) error {
+			if flags.batch.Enabled() {
+				flags.batch.Confirm = flags.confirm
+				return cmdr.BatchConfigSet(app, flags.batch.Batch, configFlags.ptype, configFlags.group, args, true)
+			}
 			return cmdr.ConfigSet(app, configFlags.ptype, configFlags.group, args, true, flags.confirm)
 		},
 	}
@@ -92,6 +117,7 @@ func configSetCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.Flags().StringVarP(&configFlags.ptype, "ptype", "p", "", i18n.T("The ptype for which the config needs to be set"))
 	cmd.Flags().StringVarP(&configFlags.group, "group", "g", "", i18n.T("The group for which the config needs to be set"))
 	cmd.Flags().StringVarP(&flags.confirm, "confirm", "", "", i18n.T("To proceed, type 'yes'"))
+	flags.batch.addFlags(cmd)
 	cmd.Flags().SortFlags = false
 	cmd.MarkFlagsMutuallyExclusive("ptype", "group")
 
@@ -106,6 +132,7 @@ func configSetCommand(cmdr *commands.DryccCmd) *cobra.Command {
 func configUnsetCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
 		confirm string
+		batch   batchFlags
 	}
 
 	cmd := &cobra.Command{
@@ -114,6 +141,12 @@ func configUnsetCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		Short: i18n.T("Unset environment variables for an app"),
 		Long:  i18n.T("Unsets an environment variable for an application or config group"),
 		RunE: func(_ *cobra.Command, args []string) error {
+			if flags.batch.Enabled() {
+				flags.batch.Confirm = flags.confirm
+				return cmdr.BatchApps(app, flags.batch.Batch, "unset the config", func(d *commands.DryccCmd, appID string) error {
+					return d.ConfigUnset(appID, configFlags.ptype, configFlags.group, args, "yes")
+				})
+			}
 			return cmdr.ConfigUnset(app, configFlags.ptype, configFlags.group, args, flags.confirm)
 		},
 	}
@@ -121,6 +154,7 @@ func configUnsetCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.Flags().StringVarP(&configFlags.ptype, "ptype", "p", "", i18n.T("The ptype for which the config needs to be unset"))
 	cmd.Flags().StringVarP(&configFlags.group, "group", "g", "", i18n.T("The group for which the config needs to be unset"))
 	cmd.Flags().StringVarP(&flags.confirm, "confirm", "", "", i18n.T("To proceed, type 'yes'"))
+	flags.batch.addFlags(cmd)
 	cmd.Flags().SortFlags = false
 	cmd.MarkFlagsMutuallyExclusive("ptype", "group")
 
@@ -137,6 +171,9 @@ func configPullCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		path        string
 		interactive bool
 		overwrite   bool
+		keepRefs    bool
+		output      string
+		encryption  encryptionFlags
 	}
 
 	cmd := &cobra.Command{
@@ -146,9 +183,30 @@ func configPullCommand(cmdr *commands.DryccCmd) *cobra.Command {
 
 The environmental variables can be piped into a file, 'drycc config pull > file',
 or stored locally in a file named .env. This file can be
-read by foreman to load the local environment for your app.`),
+read by foreman to load the local environment for your app.
+
+With --encrypt the values are encrypted with age for the public keys listed in
+the --recipients file, while the names stay readable, so that the file can be
+committed. 'drycc config push' decrypts them with the --identity file, and a
+file encrypted stays encrypted when pulled again with --interactive. The
+values unchanged keep their encrypted text, so that the file only changes
+where the config did.
+
+With --keep-refs the variables whose values are secret references in the
+--path file, such as ref+file://secrets/db.txt#password, keep their references
+rather than the values they were resolved to.
+
+An existing --path file is only replaced with --overwrite. Its former -o
+shorthand is deprecated as it is the shorthand of the global --output flag,
+the other commands read -o as --output.`),
+		Example: "drycc config pull --encrypt --recipients .drycc/recipients --path .env",
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return cmdr.ConfigPull(app, configFlags.ptype, configFlags.group, flags.path, flags.interactive, flags.overwrite)
+			if flags.output == overwriteShorthand {
+				flags.overwrite = true
+			} else if flags.output != "" {
+				cmdr.Output = flags.output
+			}
+			return cmdr.ConfigPull(app, configFlags.ptype, configFlags.group, flags.path, flags.interactive, flags.overwrite, flags.keepRefs, flags.encryption.Encryption)
 		},
 	}
 
@@ -157,7 +215,14 @@ read by foreman to load the local environment for your app.`),
 	cmd.Flags().StringVarP(&configFlags.group, "group", "g", "", i18n.T("The group for which the config needs to be pull"))
 	cmd.Flags().StringVar(&flags.path, "path", ".env", i18n.T("A path leading to an environment file"))
 	cmd.Flags().BoolVarP(&flags.interactive, "interactive", "i", false, i18n.T("Prompts for each value to be overwritten"))
-	cmd.Flags().BoolVarP(&flags.overwrite, "overwrite", "o", false, i18n.T("Allows you to have the pull overwrite keys to the path"))
+	cmd.Flags().BoolVar(&flags.overwrite, "overwrite", false, i18n.T("Allows you to have the pull overwrite keys to the path"))
+	// -o alone is the former shorthand of --overwrite, the flag shadows the
+	// global --output whose shorthand it takes
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", i18n.T("Output format, -o alone is the deprecated shorthand of --overwrite"))
+	cmd.Flags().Lookup("output").NoOptDefVal = overwriteShorthand
+	cmd.Flags().MarkShorthandDeprecated("output", "use --overwrite to overwrite the path")
+	cmd.Flags().BoolVar(&flags.keepRefs, "keep-refs", false, i18n.T("Keep the secret references of the path rather than their values"))
+	flags.encryption.addFlags(cmd, "encrypt")
 	cmd.Flags().SortFlags = false
 	cmd.MarkFlagsMutuallyExclusive("ptype", "group")
 
@@ -171,11 +236,17 @@ read by foreman to load the local environment for your app.`),
 	return cmd
 }
 
+// overwriteShorthand is the value of the --output flag of config pull set by
+// -o alone, the former shorthand of --overwrite.
+const overwriteShorthand = "overwrite"
+
 func configPushCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
-		path    string
-		merge   bool
-		confirm string
+		path       string
+		merge      bool
+		confirm    string
+		allowExec  bool
+		encryption encryptionFlags
 	}
 
 	cmd := &cobra.Command{
@@ -185,9 +256,20 @@ func configPushCommand(cmdr *commands.DryccCmd) *cobra.Command {
 
 This file can be read by foreman
 to load the local environment for your app. The file should be piped via
-stdin, 'drycc config push < .env', or using the --path option.`),
+stdin, 'drycc config push < .env', or using the --path option.
+
+The file holds a NAME=value per line, optionally prefixed by export. Values
+may be 'single quoted', as is, or "double quoted", with the escapes \n, \t,
+\" and \\, and span lines, such as PEM keys. Unquoted values end at a #
+following a space, as comments do. An unquoted value @<path> is read from the
+file at <path>, and - from stdin when the file is given with --path. Values
+encrypted by 'drycc config pull --encrypt' are decrypted with --identity.
+Secret references such as ref+env://DB_URL are resolved as by 'drycc config
+set', see its help. As the file may be written by anyone committing to the
+repository, the references running programs, ref+exec and those of the
+plugins, are only resolved with --allow-exec.`),
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return cmdr.ConfigPush(app, configFlags.ptype, configFlags.group, flags.path, flags.merge, flags.confirm)
+			return cmdr.ConfigPush(app, configFlags.ptype, configFlags.group, flags.path, flags.merge, flags.confirm, flags.encryption.Encryption, flags.allowExec)
 		},
 	}
 
@@ -196,6 +278,52 @@ stdin, 'drycc config push < .env', or using the --path option.`),
 	cmd.Flags().StringVar(&flags.path, "path", ".env", i18n.T("A path leading to an environment file"))
 	cmd.Flags().BoolVarP(&flags.merge, "merge", "", false, i18n.T("Merge config values"))
 	cmd.Flags().StringVar(&flags.confirm, "confirm", "", i18n.T("To proceed, type 'yes'"))
+	cmd.Flags().BoolVar(&flags.allowExec, "allow-exec", false, i18n.T("Resolve the secret references of the file running programs, such as ref+exec"))
+	flags.encryption.addFlags(cmd, "")
+	cmd.Flags().SortFlags = false
+	cmd.MarkFlagsMutuallyExclusive("ptype", "group")
+
+	ptypeCompletion := completion.PtsCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile, AppID: &app}
+	cmd.RegisterFlagCompletionFunc("ptype", ptypeCompletion.CompletionFunc)
+	configGroupCompletion := completion.ConfigGroupCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile, AppID: &app}
+	cmd.RegisterFlagCompletionFunc("group", configGroupCompletion.CompletionFunc)
+	return cmd
+}
+
+func configEditCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		path       string
+		confirm    string
+		allowExec  bool
+		encryption encryptionFlags
+	}
+
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: i18n.T("Edit environment variables in an editor"),
+		Long: i18n.T(`Edits the environment variables of an application or config group in the
+editor of $VISUAL or $EDITOR, and pushes them once saved, replacing the
+config of the ptype or group.
+
+With --encrypted the variables edited are those of the --path file, whose
+values encrypted by 'drycc config pull --encrypt' are decrypted with
+--identity, or those of the app when the file does not exist yet. Once pushed,
+the file is written again with its values encrypted for --recipients. The
+secret references running programs, such as ref+exec, are only resolved with
+--allow-exec.`),
+		Example: "drycc config edit --encrypted --path .env",
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmdr.ConfigEdit(app, configFlags.ptype, configFlags.group, flags.path, flags.encryption.Encryption, flags.confirm, flags.allowExec)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFlags.ptype, "ptype", "p", "", i18n.T("The ptype for which the config needs to be edited"))
+	cmd.Flags().StringVarP(&configFlags.group, "group", "g", "", i18n.T("The group for which the config needs to be edited"))
+	cmd.Flags().StringVar(&flags.path, "path", ".env", i18n.T("A path leading to an encrypted environment file"))
+	cmd.Flags().StringVar(&flags.confirm, "confirm", "", i18n.T("To proceed, type 'yes'"))
+	cmd.Flags().BoolVar(&flags.allowExec, "allow-exec", false, i18n.T("Resolve the secret references edited running programs, such as ref+exec"))
+	flags.encryption.addFlags(cmd, "encrypted")
 	cmd.Flags().SortFlags = false
 	cmd.MarkFlagsMutuallyExclusive("ptype", "group")
 
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"github.com/drycc/workflow-cli/internal/commands"
+	"github.com/drycc/workflow-cli/internal/completion"
+	"github.com/drycc/workflow-cli/internal/template"
+	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// NewContextsCommand creates a command for managing named contexts.
+func NewContextsCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "contexts",
+		Short: i18n.T("Manage the controllers you are logged in to"),
+		Long:  i18n.T("Manage the named contexts of the client configuration, each context holds the controller, token and workspace of one login"),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmdr.ContextsList()
+		},
+	}
+	cmd.AddCommand(contextsListCommand(cmdr))
+	cmd.AddCommand(contextsUseCommand(cmdr))
+	cmd.AddCommand(contextsRenameCommand(cmdr))
+	cmd.AddCommand(contextsDeleteCommand(cmdr))
+	return cmd
+}
+
+func contextsListCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: i18n.T("List the contexts of the client configuration"),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmdr.ContextsList()
+		},
+	}
+
+	return cmd
+}
+
+func contextsUseCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	contextCompletion := completion.ContextCompletion{ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use: "use <name>",
+		Example: template.CustomExample(
+			"drycc contexts use staging",
+			map[string]string{
+				"<name>": i18n.T("The name of the context"),
+			},
+		),
+		Args:              cobra.ExactArgs(1),
+		Short:             i18n.T("Switch the current context"),
+		ValidArgsFunction: contextCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.ContextsUse(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func contextsRenameCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	contextCompletion := completion.ContextCompletion{ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use: "rename <name> <new-name>",
+		Example: template.CustomExample(
+			"drycc contexts rename drycc.example.com production",
+			map[string]string{
+				"<name>":     i18n.T("The name of the context"),
+				"<new-name>": i18n.T("The new name of the context"),
+			},
+		),
+		Args:              cobra.ExactArgs(2),
+		Short:             i18n.T("Rename a context"),
+		ValidArgsFunction: contextCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.ContextsRename(args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func contextsDeleteCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		confirm string
+	}
+	contextCompletion := completion.ContextCompletion{ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use: "delete <name>",
+		Example: template.CustomExample(
+			"drycc contexts delete staging --confirm staging",
+			map[string]string{
+				"<name>": i18n.T("The name of the context"),
+			},
+		),
+		Args:              cobra.ExactArgs(1),
+		Short:             i18n.T("Delete a context and its token"),
+		ValidArgsFunction: contextCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.ContextsDelete(args[0], flags.confirm)
+		},
+	}
+	cmd.Flags().StringVar(&flags.confirm, "confirm", "", i18n.T("Skips the prompt for the context name"))
+
+	return cmd
+}
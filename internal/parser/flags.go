@@ -1,7 +1,93 @@
 package parser
 
+import (
+	"time"
+
+	"github.com/drycc/workflow-cli/internal/commands"
+	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/drycc/workflow-cli/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
 var (
 	app     string
 	version int
 	limit   int
 )
+
+// waitFlags are the flags of the commands that can wait for a rollout.
+type waitFlags struct {
+	wait  bool
+	limit time.Duration
+}
+
+func (w *waitFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&w.wait, "wait", false, i18n.T("Wait until the process types are rolled out and fail if they are not"))
+	cmd.Flags().DurationVar(&w.limit, "timeout", 5*time.Minute, i18n.T("How long to wait for the rollout with --wait"))
+}
+
+// timeout returns how long to wait for the rollout, 0 when not waiting.
+func (w *waitFlags) timeout() time.Duration {
+	if !w.wait {
+		return 0
+	}
+	return w.limit
+}
+
+// watchFlags are the flags of the commands that can watch for changes.
+type watchFlags struct {
+	watch bool
+	every time.Duration
+}
+
+func (w *watchFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&w.watch, "watch", "w", false, i18n.T("Watch for changes until interrupted"))
+	cmd.Flags().DurationVar(&w.every, "interval", 2*time.Second, i18n.T("How often to poll for changes with --watch"))
+}
+
+// interval returns how often to poll for changes, 0 when not watching.
+func (w *watchFlags) interval() time.Duration {
+	if !w.watch {
+		return 0
+	}
+	return max(w.every, time.Second)
+}
+
+// batchFlags are the flags of the commands that can run on many apps, see
+// commands.Batch. They are added after the --confirm flag of the command.
+type batchFlags struct {
+	commands.Batch
+}
+
+func (b *batchFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&b.Selector, "selector", nil, i18n.T("Run on the apps with these labels instead of --app, comma separated key=value pairs"))
+	cmd.Flags().BoolVar(&b.All, "all-apps", false, i18n.T("Run on all the apps of the workspace instead of --app"))
+	cmd.Flags().IntVar(&b.Parallel, "parallel", commands.DefaultParallel, i18n.T("How many apps to run on at once with --selector or --all-apps"))
+	if cmd.Flags().Lookup("confirm") == nil {
+		cmd.Flags().StringVar(&b.Confirm, "confirm", "", i18n.T(`To proceed, type "yes"`))
+	}
+}
+
+// encryptionFlags are the flags of the age keys of the config files whose
+// values are encrypted, see commands.Encryption.
+type encryptionFlags struct {
+	commands.Encryption
+}
+
+// addFlags adds the identity flag, and the flag named encrypt encrypting the
+// values with the recipients flag unless it is empty.
+func (e *encryptionFlags) addFlags(cmd *cobra.Command, encrypt string) {
+	if encrypt != "" {
+		cmd.Flags().BoolVar(&e.Encrypt, encrypt, false, i18n.T("Encrypt the values with age for the public keys of --recipients"))
+		cmd.Flags().StringVar(&e.Recipients, "recipients", commands.DefaultRecipients, i18n.T("The file of the age public keys to encrypt the values for, one per line"))
+	}
+	cmd.Flags().StringVar(&e.Identity, "identity", "", i18n.T("The age identity file decrypting the values, $DRYCC_AGE_IDENTITY or ~/.drycc/age.key by default"))
+}
+
+// addLogFlags adds the flags formatting, filtering and sending log lines.
+func addLogFlags(cmd *cobra.Command, options *logging.Options) {
+	cmd.Flags().StringVar(&options.Format, "format", "raw", i18n.T("The format of the log lines. One of: raw|json|logfmt"))
+	cmd.Flags().StringSliceVar(&options.Fields, "fields", nil, i18n.T("Comma separated fields of the log lines to print, ex: level,msg"))
+	cmd.Flags().StringArrayVar(&options.Filters, "filter", nil, i18n.T("Print only the log lines matching this expression, can be repeated, ex: level>=warn"))
+	cmd.Flags().StringArrayVar(&options.Sinks, "sink", nil, i18n.T("Write the logs to this sink instead of printing them, can be repeated"))
+}
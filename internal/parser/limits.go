@@ -55,6 +55,7 @@ func limitsListCommand(cmdr *commands.DryccCmd) *cobra.Command {
 func limitSetCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
 		limits []string
+		batch  batchFlags
 	}
 	limitSetPlanCompletion := completion.LimitSetPlanCompletion{AppID: &app, ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
 	cmd := &cobra.Command{
@@ -75,9 +76,15 @@ restrictions through Kubernetes.`),
 		ValidArgsFunction: limitSetPlanCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			flags.limits = args
+			if flags.batch.Enabled() {
+				return cmdr.BatchApps(app, flags.batch.Batch, "set the limits", func(d *commands.DryccCmd, appID string) error {
+					return d.LimitsSet(appID, flags.limits)
+				})
+			}
 			return cmdr.LimitsSet(app, flags.limits)
 		},
 	}
+	flags.batch.addFlags(cmd)
 
 	return cmd
 }
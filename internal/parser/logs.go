@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"github.com/drycc/workflow-cli/internal/commands"
+	"github.com/drycc/workflow-cli/internal/completion"
+	"github.com/drycc/workflow-cli/internal/template"
+	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/drycc/workflow-cli/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+// NewLogsCommand creates the logs command
+func NewLogsCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		lines   int
+		follow  bool
+		ptypes  []string
+		release string
+		options logging.Options
+	}
+
+	cmd := &cobra.Command{
+		Use:  "logs",
+		Args: cobra.NoArgs,
+		Example: template.CustomExample(
+			"drycc logs -a <app> -f --ptype <ptype> --release <release>",
+			map[string]string{
+				"<app>":     i18n.T("The uniquely identifiable name for the application"),
+				"<ptype>":   i18n.T("The process type whose logs are printed, ex: web"),
+				"<release>": i18n.T("The release whose logs are printed, ex: v3"),
+			},
+		),
+		Short: i18n.T("Print the logs of an app"),
+		Long: i18n.T(`Print the logs of every pod of an app, merged and ordered by their time and
+prefixed by the name of the pod, limited to the pods of the process types
+given with --ptype and of the release given with --release.
+
+With --follow the pods started later, such as those of a new release or of a
+scale, are followed too and the pods that stop are dropped, until interrupted.
+The lines are held for a second so that those of all the pods can be ordered.
+
+The logs are parsed, filtered, formatted and written to sinks as with
+drycc ps logs, see its help for the details of --filter, --format and --sink.`),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if flags.lines < 0 {
+				flags.lines = -1
+			}
+			return cmdr.AppLogs(app, flags.lines, flags.follow, flags.ptypes, flags.release, flags.options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
+	cmd.Flags().IntVarP(&flags.lines, "lines", "l", 300, i18n.T("The number of lines to display for each pod, -1 showing all log lines"))
+	cmd.Flags().BoolVarP(&flags.follow, "follow", "f", false, i18n.T("Specify if the logs should be streamed"))
+	cmd.Flags().StringSliceVar(&flags.ptypes, "ptype", nil, i18n.T("Comma separated process types whose logs are printed"))
+	cmd.Flags().StringVar(&flags.release, "release", "", i18n.T("The release whose logs are printed, ex: v3"))
+	addLogFlags(cmd, &flags.options)
+	cmd.Flags().SortFlags = false
+
+	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
+	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
+	ptypeCompletion := completion.PtsCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile, AppID: &app}
+	cmd.RegisterFlagCompletionFunc("ptype", ptypeCompletion.CompletionFunc)
+	releaseCompletion := completion.ReleaseCompletion{ConfigFile: &cmdr.ConfigFile, AppID: &app}
+	cmd.RegisterFlagCompletionFunc("release", releaseCompletion.CompletionFunc)
+
+	return cmd
+}
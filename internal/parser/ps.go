@@ -5,20 +5,25 @@ import (
 	"github.com/drycc/workflow-cli/internal/completion"
 	"github.com/drycc/workflow-cli/internal/template"
 	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/drycc/workflow-cli/pkg/logging"
 	"github.com/spf13/cobra"
 )
 
 // NewPsCommand creates a command for managing processes.
 func NewPsCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		watch watchFlags
+	}
 	cmd := &cobra.Command{
 		Use:   "ps",
 		Short: i18n.T("Manage processes inside an app"),
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return cmdr.PsList(app, 1000)
+			return cmdr.PsList(app, 1000, flags.watch.interval())
 		},
 	}
 
 	cmd.PersistentFlags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
+	flags.watch.addFlags(cmd)
 
 	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
 	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
@@ -26,22 +31,29 @@ func NewPsCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.AddCommand(psListCommand(cmdr))
 	cmd.AddCommand(psLogsCommand(cmdr))
 	cmd.AddCommand(psExecCommand(cmdr))
+	cmd.AddCommand(psPortForwardCommand(cmdr))
+	cmd.AddCommand(psCopyCommand(cmdr))
 	cmd.AddCommand(psDescribeCommand(cmdr))
 	cmd.AddCommand(psDeleteCommand(cmdr))
 	return cmd
 }
 
 func psListCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		watch watchFlags
+	}
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: i18n.T("List application pods"),
 		Long:  i18n.T("Lists processes servicing an application"),
 		Args:  cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return cmdr.PsList(app, 1000)
+			return cmdr.PsList(app, 1000, flags.watch.interval())
 		},
 	}
 
+	flags.watch.addFlags(cmd)
+
 	return cmd
 }
 
@@ -52,23 +64,50 @@ func psLogsCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		follow    bool
 		container string
 		previous  bool
+		options   logging.Options
 	}
 
 	psCompletion := completion.PsCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
 
 	cmd := &cobra.Command{
-		Use:               "logs <pod>",
-		Args:              cobra.ExactArgs(1),
-		Example:           "drycc ps logs my-pod",
-		Short:             i18n.T("Print the logs for a container"),
-		Long:              i18n.T("Print the logs for a container in a pod or specified resource"),
+		Use:  "logs <pod>",
+		Args: cobra.ExactArgs(1),
+		Example: template.CustomExample(
+			"drycc ps logs <pod> -f --format json --filter 'level>=warn' --sink <sink>",
+			map[string]string{
+				"<pod>":  i18n.T("The pod name"),
+				"<sink>": i18n.T("Where to write the logs, ex: stdout, file:///var/log/app.log, syslog+tcp://host:601 or otlp://host:4318"),
+			},
+		),
+		Short: i18n.T("Print the logs for a container"),
+		Long: i18n.T(`Print the logs for a container in a pod or specified resource.
+
+JSON and logfmt lines are parsed, so that --filter can keep the lines matching
+expressions on their fields, such as level>=warn, status!=200 or msg=~timeout,
+and --format can print them as raw lines, one JSON object per line (NDJSON)
+or logfmt, limited to the fields given with --fields.
+
+With --sink the logs are written to one or more sinks instead of being printed:
+
+  stdout                  print the logs, as without --sink
+  file:///path            write to a file rotated by size or age, with the query
+                          parameters max-size (100MB), max-age, max-backups (5)
+                          and compress (true) to gzip rotated files
+  syslog[+udp|+tcp]://host:port
+                          send RFC5424 messages, with the query parameters
+                          facility (user) and app-name
+  otlp[+http|+https]://host:port/path
+                          export to an OpenTelemetry collector over OTLP/HTTP,
+                          with the query parameters batch-size (512),
+                          flush-interval (5s), queue-size (4096), timeout (10s),
+                          retries (3) and header=key=value`),
 		ValidArgsFunction: psCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			podID := args[0]
 			if flags.lines < 0 {
 				flags.lines = -1
 			}
-			return cmdr.PsLogs(app, podID, flags.lines, flags.follow, flags.container, flags.previous)
+			return cmdr.PsLogs(app, podID, flags.lines, flags.follow, flags.container, flags.previous, flags.options)
 		},
 	}
 
@@ -76,6 +115,7 @@ func psLogsCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.Flags().BoolVarP(&flags.follow, "follow", "f", false, i18n.T("Specify if the logs should be streamed"))
 	cmd.Flags().StringVar(&flags.container, "container", "", i18n.T("Print the logs of this container"))
 	cmd.Flags().BoolVarP(&flags.previous, "previous", "p", false, i18n.T("Print the logs for the previous instance of the container in a pod if it exists"))
+	addLogFlags(cmd, &flags.options)
 	cmd.Flags().SortFlags = false
 
 	return cmd
@@ -119,7 +159,64 @@ func psExecCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	return cmd
 }
 
+func psPortForwardCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		address string
+	}
+	psCompletion := completion.PsCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use:  "port-forward <pod> [<local>:]<remote>...",
+		Args: cobra.MinimumNArgs(2),
+		Example: template.CustomExample(
+			"drycc ps port-forward my-pod 5432 8000:80",
+			map[string]string{
+				"<pod>":    i18n.T("The pod name for the application"),
+				"<local>":  i18n.T("The local port to listen on, the same as <remote> when omitted, chosen by the system when empty"),
+				"<remote>": i18n.T("The port of the pod to forward connections to"),
+			},
+		),
+		Short: i18n.T("Forward local ports to a pod"),
+		Long: i18n.T(`Listens on local ports and forwards each connection to a port of a pod, until
+interrupted with Ctrl+C. The connections are tunnelled through an exec of socat,
+or nc when socat is missing, so the pod needs one of them and sh.`),
+		ValidArgsFunction: psCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.PsPortForward(app, args[0], args[1:], flags.address)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.address, "address", "127.0.0.1", i18n.T("The local address to listen on"))
+
+	return cmd
+}
+
+func psCopyCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "cp <src> <dst>",
+		Args: cobra.ExactArgs(2),
+		Example: template.CustomExample(
+			"drycc ps cp my-pod:/tmp/heap.hprof ./heap.hprof",
+			map[string]string{
+				"<src>": i18n.T("The local path or <pod>:<path> to copy from"),
+				"<dst>": i18n.T("The local path or <pod>:<path> to copy to, a path ending with / receives the source under its name"),
+			},
+		),
+		Short: i18n.T("Copy files and directories to and from a pod"),
+		Long: i18n.T(`Copies files and directories between the local filesystem and a pod, keeping
+their modes. The files are streamed as a tar archive through exec, so the
+container needs the tar and sh commands.`),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.PsCopy(app, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
 func psDescribeCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		watch watchFlags
+	}
 	psCompletion := completion.PsCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
 	cmd := &cobra.Command{
 		Use:  "describe <pod>",
@@ -134,10 +231,12 @@ func psDescribeCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		ValidArgsFunction: psCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			podID := args[0]
-			return cmdr.PsDescribe(app, podID)
+			return cmdr.PsDescribe(app, podID, flags.watch.interval())
 		},
 	}
 
+	flags.watch.addFlags(cmd)
+
 	return cmd
 }
 
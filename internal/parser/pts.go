@@ -10,15 +10,19 @@ import (
 
 // NewPtsCommand creates a command for managing process types.
 func NewPtsCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		watch watchFlags
+	}
 	cmd := &cobra.Command{
 		Use:   "pts",
 		Short: i18n.T("Manage process types inside an app"),
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return cmdr.PtsList(app, 1000)
+			return cmdr.PtsList(app, 1000, flags.watch.interval())
 		},
 	}
 
 	cmd.PersistentFlags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
+	flags.watch.addFlags(cmd)
 
 	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
 	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
@@ -33,15 +37,20 @@ func NewPtsCommand(cmdr *commands.DryccCmd) *cobra.Command {
 
 // ptsListCommand
 func ptsListCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		watch watchFlags
+	}
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: i18n.T("List application process types"),
 		Long:  i18n.T("Lists process types servicing an application"),
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return cmdr.PtsList(app, 1000)
+			return cmdr.PtsList(app, 1000, flags.watch.interval())
 		},
 	}
 
+	flags.watch.addFlags(cmd)
+
 	return cmd
 }
 
@@ -49,6 +58,7 @@ func ptsListCommand(cmdr *commands.DryccCmd) *cobra.Command {
 func ptsDescribeCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
 		ptype string
+		watch watchFlags
 	}
 	ptsArgsCompletion := completion.PtsArgsCompletion{
 		PtsCompletion: &completion.PtsCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile},
@@ -67,10 +77,12 @@ func ptsDescribeCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		ValidArgsFunction: ptsArgsCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			flags.ptype = args[0]
-			return cmdr.PtsDescribe(app, flags.ptype)
+			return cmdr.PtsDescribe(app, flags.ptype, flags.watch.interval())
 		},
 	}
 
+	flags.watch.addFlags(cmd)
+
 	return cmd
 }
 
@@ -79,6 +91,8 @@ func ptsRestartCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
 		ptypes  []string
 		confirm string
+		wait    waitFlags
+		batch   batchFlags
 	}
 	ptsArgsCompletion := completion.PtsArgsCompletion{
 		PtsCompletion: &completion.PtsCompletion{AppID: &app, ArgsLen: -1, ConfigFile: &cmdr.ConfigFile},
@@ -92,11 +106,19 @@ func ptsRestartCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		ValidArgsFunction: ptsArgsCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			flags.ptypes = args
-			return cmdr.PtsRestart(app, flags.ptypes, flags.confirm)
+			if flags.batch.Enabled() {
+				flags.batch.Confirm = flags.confirm
+				return cmdr.BatchApps(app, flags.batch.Batch, "restart the process types", func(d *commands.DryccCmd, appID string) error {
+					return d.PtsRestart(appID, flags.ptypes, "yes", flags.wait.timeout())
+				})
+			}
+			return cmdr.PtsRestart(app, flags.ptypes, flags.confirm, flags.wait.timeout())
 		},
 	}
 
 	cmd.Flags().StringVar(&flags.confirm, "confirm", "", i18n.T(`To proceed, type "yes"`))
+	flags.wait.addFlags(cmd)
+	flags.batch.addFlags(cmd)
 
 	return cmd
 }
@@ -106,6 +128,7 @@ func ptsScaleCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
 		app   string
 		scale []string // format like "web=5"
+		wait  waitFlags
 	}
 
 	ptsSetArgsCompletion := completion.PtsSetArgsCompletion{
@@ -126,10 +149,12 @@ func ptsScaleCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		ValidArgsFunction: ptsSetArgsCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			flags.scale = args
-			return cmdr.PtsScale(app, flags.scale)
+			return cmdr.PtsScale(app, flags.scale, flags.wait.timeout())
 		},
 	}
 
+	flags.wait.addFlags(cmd)
+
 	// shortcuts scale has not app flag
 	cmd.Flags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
 
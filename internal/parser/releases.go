@@ -18,6 +18,9 @@ func NewReleasesCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		PtsCompletion: &completion.PtsCompletion{AppID: &app, ArgsLen: -1, ConfigFile: &cmdr.ConfigFile},
 	}
 
+	var flags struct {
+		watch watchFlags
+	}
 	cmd := &cobra.Command{
 		Use:               "releases [<ptype>...]",
 		Example:           "drycc releases ptype1 ptype2",
@@ -25,12 +28,13 @@ func NewReleasesCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		ValidArgsFunction: ptsArgsCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			results, _ := commands.ResponseLimit(limit)
-			return cmdr.ReleasesList(app, args, results)
+			return cmdr.ReleasesList(app, args, results, flags.watch.interval())
 		},
 	}
 
 	cmd.PersistentFlags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
 	cmd.Flags().IntVarP(&limit, "limit", "l", 0, i18n.T("The maximum number of results to display"))
+	flags.watch.addFlags(cmd)
 
 	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
 	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
@@ -39,6 +43,7 @@ func NewReleasesCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.AddCommand(releasesInfoCommand(cmdr))
 	cmd.AddCommand(releasesDeployCommand(cmdr))
 	cmd.AddCommand(releasesRollbackCommand(cmdr))
+	cmd.AddCommand(releasesDiffCommand(cmdr))
 	return cmd
 }
 
@@ -47,6 +52,9 @@ func releasesListCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		PtsCompletion: &completion.PtsCompletion{AppID: &app, ArgsLen: -1, ConfigFile: &cmdr.ConfigFile},
 	}
 
+	var flags struct {
+		watch watchFlags
+	}
 	cmd := &cobra.Command{
 		Use:               "list [<ptype>...]",
 		Example:           "drycc releases list ptype1 ptype2",
@@ -55,11 +63,12 @@ func releasesListCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		ValidArgsFunction: ptsArgsCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			results, _ := commands.ResponseLimit(limit)
-			return cmdr.ReleasesList(app, args, results)
+			return cmdr.ReleasesList(app, args, results, flags.watch.interval())
 		},
 	}
 
 	cmd.Flags().IntVarP(&limit, "limit", "l", 0, i18n.T("The maximum number of results to display"))
+	flags.watch.addFlags(cmd)
 	cmd.Flags().SortFlags = false
 
 	return cmd
@@ -97,6 +106,8 @@ func releasesDeployCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		app     string
 		force   bool
 		confirm string
+		wait    waitFlags
+		batch   batchFlags
 	}
 
 	ptsArgsCompletion := completion.PtsArgsCompletion{
@@ -109,18 +120,29 @@ func releasesDeployCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		ValidArgsFunction: ptsArgsCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			ptypes := args
-			return cmdr.ReleasesDeploy(app, ptypes, flags.force, flags.confirm)
+			if flags.batch.Enabled() {
+				flags.batch.Confirm = flags.confirm
+				return cmdr.BatchApps(app, flags.batch.Batch, "deploy the latest release", func(d *commands.DryccCmd, appID string) error {
+					return d.ReleasesDeploy(appID, ptypes, flags.force, "yes", flags.wait.timeout())
+				})
+			}
+			return cmdr.ReleasesDeploy(app, ptypes, flags.force, flags.confirm, flags.wait.timeout())
 		},
 	}
 
 	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, i18n.T("Force deploy"))
 	cmd.Flags().StringVar(&flags.confirm, "confirm", "", i18n.T(`To proceed, type "yes"`))
+	flags.wait.addFlags(cmd)
+	flags.batch.addFlags(cmd)
 	cmd.Flags().SortFlags = false
 
 	return cmd
 }
 
 func releasesRollbackCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		wait waitFlags
+	}
 	ptsArgsCompletion := completion.PtsArgsCompletion{
 		PtsCompletion: &completion.PtsCompletion{AppID: &app, ArgsLen: -1, ConfigFile: &cmdr.ConfigFile},
 	}
@@ -152,7 +174,40 @@ func releasesRollbackCommand(cmdr *commands.DryccCmd) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return cmdr.ReleasesRollback(app, ptypes, version)
+			return cmdr.ReleasesRollback(app, ptypes, version, flags.wait.timeout())
+		},
+	}
+
+	flags.wait.addFlags(cmd)
+
+	return cmd
+}
+
+func releasesDiffCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	releaseCompletion := completion.ReleaseCompletion{AppID: &app, ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use:  "diff <version> <version>",
+		Args: cobra.ExactArgs(2),
+		Example: template.CustomExample(
+			"drycc releases diff v12 v15",
+			map[string]string{
+				"<version>": i18n.T(`The release of the application, such as 'v1'`),
+			},
+		),
+		Short: i18n.T("Compare two releases"),
+		Long: i18n.T(`Compares the config values, limits, timeouts, tags, healthchecks and build
+of two releases as a unified diff, use --output json for the changed fields`),
+		ValidArgsFunction: releaseCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			from, err := versionFromString(args[0])
+			if err != nil {
+				return err
+			}
+			to, err := versionFromString(args[1])
+			if err != nil {
+				return err
+			}
+			return cmdr.ReleasesDiff(app, from, to)
 		},
 	}
 
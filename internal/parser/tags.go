@@ -70,6 +70,7 @@ func tagsListCommand(cmdr *commands.DryccCmd) *cobra.Command {
 func tagsSetCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	var flags struct {
 		ptype string
+		batch batchFlags
 	}
 	ptsArgsCompletion := completion.PtsArgsCompletion{
 		PtsCompletion: &completion.PtsCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile},
@@ -93,11 +94,17 @@ scheduler-configured metadata.`),
 		ValidArgsFunction: ptsArgsCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			tags := args[0:]
+			if flags.batch.Enabled() {
+				return cmdr.BatchApps(app, flags.batch.Batch, "set the tags", func(d *commands.DryccCmd, appID string) error {
+					return d.TagsSet(appID, flags.ptype, tags)
+				})
+			}
 			return cmdr.TagsSet(app, flags.ptype, tags)
 		},
 	}
 
 	cmd.Flags().StringVarP(&flags.ptype, "ptype", "p", "", i18n.T("The process name as defined in your Procfile"))
+	flags.batch.addFlags(cmd)
 	cmd.MarkFlagRequired("ptype")
 
 	ptypeCompletion := completion.PtsCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile, AppID: &app}
@@ -46,6 +46,9 @@ func tlsInfoCommand(cmdr *commands.DryccCmd) *cobra.Command {
 }
 
 func tlsForceCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		batch batchFlags
+	}
 	TLSActionCompletion := completion.TLSActionCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
 	cmd := &cobra.Command{
 		Use: "force <action>",
@@ -61,15 +64,22 @@ func tlsForceCommand(cmdr *commands.DryccCmd) *cobra.Command {
 		ValidArgsFunction: TLSActionCompletion.CompletionFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
 			action := args[0]
-			switch action {
-			case "enable":
-				return cmdr.TLSForceEnable(app)
-			case "disable":
-				return cmdr.TLSForceDisable(app)
+			if action != "enable" && action != "disable" {
+				return fmt.Errorf("invalid action: %s, please use 'enable' or 'disable'", action)
 			}
-			return fmt.Errorf("invalid action: %s, please use 'enable' or 'disable'", action)
+			force := func(d *commands.DryccCmd, appID string) error {
+				if action == "enable" {
+					return d.TLSForceEnable(appID)
+				}
+				return d.TLSForceDisable(appID)
+			}
+			if flags.batch.Enabled() {
+				return cmdr.BatchApps(app, flags.batch.Batch, action+" https-only requests", force)
+			}
+			return force(cmdr, app)
 		},
 	}
+	flags.batch.addFlags(cmd)
 
 	return cmd
 }
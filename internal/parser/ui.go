@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"time"
+
+	"github.com/drycc/workflow-cli/internal/commands"
+	"github.com/drycc/workflow-cli/internal/completion"
+	"github.com/drycc/workflow-cli/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// NewUICommand creates the ui command
+func NewUICommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		interval time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Args:  cobra.NoArgs,
+		Short: i18n.T("Open a terminal dashboard of an app"),
+		Long: i18n.T(`Open a full-screen dashboard of an app with its processes, process types,
+releases, the events of the selected process type and the logs of the
+selected pod, refreshed automatically.
+
+Keys:
+  tab, shift+tab  focus the next or the previous pane
+  up, down, j, k  select a row of the pane with the focus
+  l, enter        tail the logs of the selected pod
+  e               exec a shell in the selected pod
+  s               scale the selected process type
+  r               restart the selected process type
+  b               roll back to the selected release
+  R               refresh now
+  q               quit`),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cmdr.UI(app, flags.interval)
+		},
+	}
+
+	cmd.Flags().StringVarP(&app, "app", "a", "", i18n.T("The uniquely identifiable name for the application"))
+	cmd.Flags().DurationVar(&flags.interval, "interval", 5*time.Second, i18n.T("How often to refresh the dashboard"))
+
+	appCompletion := completion.AppCompletion{ArgsLen: -1, ConfigFile: &cmdr.ConfigFile}
+	cmd.RegisterFlagCompletionFunc("app", appCompletion.CompletionFunc)
+
+	return cmd
+}
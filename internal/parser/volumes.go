@@ -31,6 +31,9 @@ func NewVolumesCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	cmd.AddCommand(volumesInfoCommand(cmdr))
 	cmd.AddCommand(volumesRemoveCommand(cmdr))
 	cmd.AddCommand(volumesServeCommand(cmdr))
+	cmd.AddCommand(volumesClientCommand(cmdr))
+	cmd.AddCommand(volumesBackupCommand(cmdr))
+	cmd.AddCommand(volumesRestoreCommand(cmdr))
 	cmd.AddCommand(volumesMountCommand(cmdr))
 	cmd.AddCommand(volumesUnmountCommand(cmdr))
 	return cmd
@@ -223,6 +226,115 @@ func volumesServeCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	return cmd
 }
 
+func volumesClientCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		recursive bool
+		checksum  bool
+		prune     bool
+		dryRun    bool
+	}
+	volumeCompletion := completion.VolumeCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use:   "client <name> <command> [<args>...]",
+		Short: i18n.T("Access the files of the volume"),
+		Long: i18n.T(`Access the files of the volume through its WebDAV service, which is started
+for the command and stopped when it is done, so that it can be used in scripts.
+
+Commands:
+  ls [<path>]          list a directory, recursively with -r
+  cat <path>...        print files
+  cp <src> <dst>       copy a file, or a directory with -r
+  rm <path>...         remove files, or directories with -r
+  mkdir <path>...      create directories and their parents
+  sync <src> <dst>     copy the files missing or changed in the destination,
+                       those whose size differ or, with --checksum, whose
+                       content differ, else those modified later
+
+The paths of the volume given to cp and sync start with a colon, the other
+paths are local.`),
+		Example: template.CustomExample(
+			"drycc volumes client myvolume sync --delete ./site :/www",
+			map[string]string{
+				"<name>": i18n.T("The volume name"),
+			},
+		),
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: volumeCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.VolumesClient(app, args[0], args[1], args[2:], flags.recursive, flags.checksum, flags.prune, flags.dryRun)
+		},
+	}
+	cmd.Flags().BoolVarP(&flags.recursive, "recursive", "r", false, i18n.T("List, copy or remove directories recursively"))
+	cmd.Flags().BoolVarP(&flags.checksum, "checksum", "c", false, i18n.T("Compare the content of the files to sync instead of their modification time"))
+	cmd.Flags().BoolVar(&flags.prune, "delete", false, i18n.T("Delete the files of the destination missing in the source when syncing"))
+	cmd.Flags().BoolVarP(&flags.dryRun, "dry-run", "n", false, i18n.T("Print the files to sync without changing them"))
+	return cmd
+}
+
+func volumesBackupCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		file        string
+		incremental string
+	}
+	volumeCompletion := completion.VolumeCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use:   "backup <name>",
+		Short: i18n.T("Back up the files of the volume to a local archive"),
+		Long: i18n.T(`Back up the files of the volume to a gzipped tar archive, with a manifest of
+the checksums of the files checked on restore.
+
+With --incremental, only the files whose size or modification time changed
+since a previous backup are stored, the others are listed in the manifest as
+stored in the previous backup.`),
+		Example: template.CustomExample(
+			"drycc volumes backup myvolume -f vol.tar.gz --incremental full.tar.gz",
+			map[string]string{
+				"<name>": i18n.T("The volume name"),
+			},
+		),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: volumeCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.VolumesBackup(app, args[0], flags.file, flags.incremental)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.file, "file", "f", "", i18n.T("The archive to write, ex: vol.tar.gz"))
+	cmd.Flags().StringVar(&flags.incremental, "incremental", "", i18n.T("A previous backup, only the files changed since are stored"))
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func volumesRestoreCommand(cmdr *commands.DryccCmd) *cobra.Command {
+	var flags struct {
+		files []string
+	}
+	volumeCompletion := completion.VolumeCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
+	cmd := &cobra.Command{
+		Use:   "restore <name>",
+		Short: i18n.T("Restore the files of the volume from a local archive"),
+		Long: i18n.T(`Restore the files of the volume from a backup archive, overwriting the files
+with the same path. The checksums of the files are checked before the volume
+is written.
+
+An incremental backup is restored with the backups it is based on, given
+with more --file after it.`),
+		Example: template.CustomExample(
+			"drycc volumes restore myvolume -f vol.tar.gz -f full.tar.gz",
+			map[string]string{
+				"<name>": i18n.T("The volume name"),
+			},
+		),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: volumeCompletion.CompletionFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmdr.VolumesRestore(app, args[0], flags.files)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&flags.files, "file", "f", nil, i18n.T("The archive to restore, then the archives it is based on"))
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
 func volumesMountCommand(cmdr *commands.DryccCmd) *cobra.Command {
 	volumesMountCompletion := completion.VolumesMountCompletion{AppID: &app, ArgsLen: 0, ConfigFile: &cmdr.ConfigFile}
 	cmd := &cobra.Command{
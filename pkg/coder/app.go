@@ -0,0 +1,104 @@
+package coder
+
+import (
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// AppCoder implements Coder for App resources.
+//
+// Decode field mapping:
+//
+//	Metadata.name     → App
+//	spec.label        → Label
+//	spec.autodeploy   → Autodeploy
+//	spec.autorollback → Autorollback
+//	spec.routable     → Routable
+//	spec.allowlist    → Allowlist
+//
+// Encode uses the same mapping in reverse.
+type AppCoder struct {
+	Request api.AppSettings
+	Info    api.AppSettings
+}
+
+// Decode unmarshals JSON data into the app settings request.
+func (c *AppCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "App")
+	if err != nil {
+		return err
+	}
+
+	c.Request = api.AppSettings{}
+	if err := convert(env.Spec, &c.Request); err != nil {
+		return err
+	}
+	c.Request.App = env.Metadata.Name
+	return nil
+}
+
+// Encode marshals the app settings into a YAML manifest.
+func (c *AppCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the app settings into a manifest.
+func (c *AppCoder) Manifest() Manifest {
+	spec := make(map[string]any)
+	if len(c.Info.Label) > 0 {
+		spec["label"] = normalize(c.Info.Label)
+	}
+	if c.Info.Autodeploy != nil {
+		spec["autodeploy"] = *c.Info.Autodeploy
+	}
+	if c.Info.Autorollback != nil {
+		spec["autorollback"] = *c.Info.Autorollback
+	}
+	if c.Info.Routable != nil {
+		spec["routable"] = *c.Info.Routable
+	}
+	if len(c.Info.Allowlist) > 0 {
+		spec["allowlist"] = normalize(c.Info.Allowlist)
+	}
+	return newManifest("App", c.Info.App, spec)
+}
+
+// AutoscaleCoder implements Coder for the Autoscale resource of a process
+// type, named after the process type.
+//
+// Decode field mapping:
+//
+//	Metadata.name    → Autoscale key
+//	spec.min         → Autoscale[ptype].Min
+//	spec.max         → Autoscale[ptype].Max
+//	spec.cpu_percent → Autoscale[ptype].CPUPercent
+type AutoscaleCoder struct {
+	Request api.AppSettings
+	Info    api.AppSettings
+	Ptype   string
+}
+
+// Decode unmarshals JSON data into the app settings request.
+func (c *AutoscaleCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Autoscale")
+	if err != nil {
+		return err
+	}
+
+	autoscale := &api.Autoscale{}
+	if err := convert(env.Spec, autoscale); err != nil {
+		return err
+	}
+	c.Ptype = env.Metadata.Name
+	c.Request = api.AppSettings{Autoscale: map[string]*api.Autoscale{c.Ptype: autoscale}}
+	return nil
+}
+
+// Encode marshals the autoscale of Ptype into a YAML manifest.
+func (c *AutoscaleCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the autoscale of Ptype into a manifest.
+func (c *AutoscaleCoder) Manifest() Manifest {
+	return newManifest("Autoscale", c.Ptype, normalizeMap(c.Info.Autoscale[c.Ptype]))
+}
@@ -0,0 +1,97 @@
+package coder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+func TestAppCoderDecode(t *testing.T) {
+	t.Parallel()
+
+	input := `{
+		"kind": "App",
+		"metadata": {"name": "example-go"},
+		"spec": {
+			"label": {"team": "core"},
+			"routable": false,
+			"allowlist": ["1.2.3.4"]
+		}
+	}`
+
+	c := &AppCoder{}
+	if err := c.Decode([]byte(input)); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if c.Request.App != "example-go" {
+		t.Errorf("expected App=example-go, got %s", c.Request.App)
+	}
+	if c.Request.Label["team"] != "core" {
+		t.Errorf("unexpected label: %v", c.Request.Label)
+	}
+	if c.Request.Routable == nil || *c.Request.Routable {
+		t.Errorf("expected Routable=false, got %v", c.Request.Routable)
+	}
+	if c.Request.Autodeploy != nil {
+		t.Errorf("expected Autodeploy to be unset, got %v", *c.Request.Autodeploy)
+	}
+	if len(c.Request.Allowlist) != 1 || c.Request.Allowlist[0] != "1.2.3.4" {
+		t.Errorf("unexpected allowlist: %v", c.Request.Allowlist)
+	}
+}
+
+func TestAppCoderEncode(t *testing.T) {
+	t.Parallel()
+
+	routable := true
+	c := &AppCoder{Info: api.AppSettings{
+		App:      "example-go",
+		Routable: &routable,
+		Label:    api.Labels{"team": "core"},
+	}}
+	data, err := c.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	expected := "apiVersion: " + APIVersion + `
+kind: App
+metadata:
+  name: example-go
+spec:
+  label:
+    team: core
+  routable: true
+`
+	if string(data) != expected {
+		t.Errorf("unexpected output:\n%s", data)
+	}
+}
+
+func TestAutoscaleCoder(t *testing.T) {
+	t.Parallel()
+
+	c := &AutoscaleCoder{Ptype: "web", Info: api.AppSettings{
+		Autoscale: map[string]*api.Autoscale{"web": {Min: 2, Max: 5, CPUPercent: 40}},
+	}}
+	data, err := c.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !strings.Contains(string(data), "cpu_percent: 40") || !strings.Contains(string(data), "name: web") {
+		t.Errorf("unexpected output:\n%s", data)
+	}
+
+	jsonData, err := c.Manifest().JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	decoded := &AutoscaleCoder{}
+	if err := decoded.Decode(jsonData); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	autoscale := decoded.Request.Autoscale["web"]
+	if decoded.Ptype != "web" || autoscale == nil || autoscale.Min != 2 || autoscale.Max != 5 || autoscale.CPUPercent != 40 {
+		t.Errorf("unexpected autoscale: %+v", decoded.Request.Autoscale)
+	}
+}
@@ -0,0 +1,49 @@
+package coder
+
+import (
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// BuildCoder implements Coder for the Build resource of an app, named after
+// the app.
+//
+// Decode field mapping:
+//
+//	spec.image     → Image
+//	spec.stack     → Stack
+//	spec.procfile  → Procfile
+//	spec.dryccfile → Dryccfile
+type BuildCoder struct {
+	Request api.CreateBuildRequest
+	Info    api.Build
+}
+
+// Decode unmarshals JSON data into the build request.
+func (c *BuildCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Build")
+	if err != nil {
+		return err
+	}
+	c.Request = api.CreateBuildRequest{}
+	return convert(env.Spec, &c.Request)
+}
+
+// Encode marshals the build into a YAML manifest.
+func (c *BuildCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the build into a manifest.
+func (c *BuildCoder) Manifest() Manifest {
+	spec := map[string]any{"image": c.Info.Image}
+	if c.Info.Stack != "" {
+		spec["stack"] = c.Info.Stack
+	}
+	if len(c.Info.Procfile) > 0 {
+		spec["procfile"] = normalize(c.Info.Procfile)
+	}
+	if len(c.Info.Dryccfile) > 0 {
+		spec["dryccfile"] = normalize(c.Info.Dryccfile)
+	}
+	return newManifest("Build", c.Info.App, spec)
+}
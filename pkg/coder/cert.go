@@ -0,0 +1,81 @@
+package coder
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// CertCoder implements Coder for Cert resources.
+//
+// Decode field mapping:
+//
+//	Metadata.name    → Name
+//	spec.certificate → Certificate
+//	spec.key         → Key
+//	spec.domains     → Domains
+//
+// The controller never returns the private key, so the manifest of an
+// existing cert only carries its domains in spec and the fingerprint of the
+// certificate in status.
+type CertCoder struct {
+	Request api.CertCreateRequest
+	Domains []string
+	Info    api.Cert
+}
+
+// Decode unmarshals JSON data into the cert request.
+func (c *CertCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Cert")
+	if err != nil {
+		return err
+	}
+
+	c.Request = api.CertCreateRequest{Name: env.Metadata.Name}
+	c.Request.Certificate, _ = env.Spec["certificate"].(string)
+	c.Request.Key, _ = env.Spec["key"].(string)
+	c.Domains = nil
+	if domains, ok := env.Spec["domains"]; ok {
+		if err := convert(domains, &c.Domains); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encode marshals the cert into a YAML manifest.
+func (c *CertCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the cert into a manifest.
+func (c *CertCoder) Manifest() Manifest {
+	spec := make(map[string]any)
+	if len(c.Info.Domains) > 0 {
+		spec["domains"] = normalize(c.Info.Domains)
+	}
+	env := newManifest("Cert", c.Info.Name, spec)
+	env.Status = map[string]any{
+		"common_name": c.Info.CommonName,
+		"fingerprint": c.Info.Fingerprint,
+	}
+	return env
+}
+
+// Fingerprint returns the SHA256 fingerprint of the requested certificate in
+// the format used by the controller, ex: AB:CD:...
+func (c *CertCoder) Fingerprint() (string, error) {
+	block, _ := pem.Decode([]byte(c.Request.Certificate))
+	if block == nil {
+		return "", fmt.Errorf("cert %s: invalid PEM certificate", c.Request.Name)
+	}
+	sum := sha256.Sum256(block.Bytes)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":"), nil
+}
@@ -0,0 +1,79 @@
+package coder
+
+import (
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+func TestCertCoderDecode(t *testing.T) {
+	t.Parallel()
+
+	input := `{
+		"kind": "Cert",
+		"metadata": {"name": "example-com"},
+		"spec": {
+			"certificate": "-----BEGIN CERTIFICATE-----\ndGVzdA==\n-----END CERTIFICATE-----\n",
+			"key": "secret",
+			"domains": ["example.com", "www.example.com"]
+		}
+	}`
+
+	c := &CertCoder{}
+	if err := c.Decode([]byte(input)); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if c.Request.Name != "example-com" || c.Request.Key != "secret" {
+		t.Errorf("unexpected request: %+v", c.Request)
+	}
+	if len(c.Domains) != 2 || c.Domains[1] != "www.example.com" {
+		t.Errorf("unexpected domains: %v", c.Domains)
+	}
+}
+
+func TestCertCoderFingerprint(t *testing.T) {
+	t.Parallel()
+
+	c := &CertCoder{Request: api.CertCreateRequest{
+		Name:        "example-com",
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("test")})),
+	}}
+	fingerprint, err := c.Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	expected := "9F:86:D0:81:88:4C:7D:65:9A:2F:EA:A0:C5:5A:D0:15:A3:BF:4F:1B:2B:0B:82:2C:D1:5D:6C:15:B0:F0:0A:08"
+	if fingerprint != expected {
+		t.Errorf("expected %s, got %s", expected, fingerprint)
+	}
+
+	c.Request.Certificate = "not a certificate"
+	if _, err := c.Fingerprint(); err == nil {
+		t.Error("expected error for invalid certificate")
+	}
+}
+
+func TestCertCoderEncode(t *testing.T) {
+	t.Parallel()
+
+	c := &CertCoder{Info: api.Cert{
+		Name:        "example-com",
+		CommonName:  "example.com",
+		Fingerprint: "AB:CD",
+		Domains:     []string{"example.com"},
+	}}
+	data, err := c.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	for _, expected := range []string{"kind: Cert", "name: example-com", "- example.com", "fingerprint: AB:CD"} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, data)
+		}
+	}
+	if strings.Contains(string(data), "key") {
+		t.Errorf("unexpected key in output:\n%s", data)
+	}
+}
@@ -1,5 +1,6 @@
-// Package coder provides K8s-style Manifest encoding/decoding for
-// Gateway API resources (HTTPRoute, Gateway) used by the Drycc CLI.
+// Package coder provides K8s-style Manifest encoding/decoding for the
+// resources of an app (Gateway API resources, config, builds, volumes...)
+// used by the Drycc CLI.
 //
 // A Coder converts between the flat API types used by controller-sdk-go
 // and the K8s Manifest format (kind/Metadata/spec/status) used in CLI YAML files.
@@ -8,7 +9,9 @@ package coder
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	drycc "github.com/drycc/controller-sdk-go"
@@ -73,3 +76,85 @@ type Manifest struct {
 type Metadata struct {
 	Name string `json:"name" yaml:"name"`
 }
+
+// EncodeManifests marshals manifests into a multi-document YAML stream.
+func EncodeManifests(manifests []Manifest) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, m := range manifests {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		data, err := marshalYAML(m)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeManifests splits a multi-document YAML (or JSON) stream into
+// manifests, empty documents are skipped.
+func DecodeManifests(data []byte) ([]Manifest, error) {
+	var manifests []Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc any
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		var m Manifest
+		if err := convert(doc, &m); err != nil {
+			return nil, err
+		}
+		if m.Kind == "" {
+			return nil, fmt.Errorf("invalid manifest #%d: missing kind", len(manifests)+1)
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// JSON returns the JSON encoding of the manifest, as accepted by the Decode
+// method of the coders.
+func (m Manifest) JSON() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// convert copies in into out through a JSON round-trip, it moves values
+// between the typed API structs and the untyped manifest specs.
+func convert(in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// decodeManifest unmarshals data into a manifest and checks its kind.
+func decodeManifest(data []byte, kind string) (Manifest, error) {
+	var env Manifest
+	if err := json.Unmarshal(data, &env); err != nil {
+		return env, err
+	}
+	if env.Kind != kind {
+		return env, fmt.Errorf("unexpected kind %s, expected %s", env.Kind, kind)
+	}
+	return env, nil
+}
+
+// newManifest returns a manifest of the current APIVersion.
+func newManifest(kind, name string, spec map[string]any) Manifest {
+	return Manifest{
+		APIVersion: APIVersion,
+		Kind:       kind,
+		Metadata:   Metadata{Name: name},
+		Spec:       spec,
+	}
+}
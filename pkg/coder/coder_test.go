@@ -0,0 +1,83 @@
+package coder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+func TestEncodeDecodeManifests(t *testing.T) {
+	t.Parallel()
+
+	autodeploy := true
+	manifests := []Manifest{
+		(&AppCoder{Info: api.AppSettings{App: "example-go", Autodeploy: &autodeploy}}).Manifest(),
+		(&ConfigCoder{Info: api.Config{App: "example-go", Values: []api.ConfigValue{
+			{Group: "global", ConfigVar: api.ConfigVar{Name: "FOO", Value: "bar"}},
+		}}}).Manifest(),
+		(&VolumeCoder{Info: api.Volume{Name: "myvolume", Size: "2G", Type: "csi"}}).Manifest(),
+	}
+
+	data, err := EncodeManifests(manifests)
+	if err != nil {
+		t.Fatalf("EncodeManifests failed: %v", err)
+	}
+	if strings.Count(string(data), "---\n") != 2 {
+		t.Errorf("expected 3 documents, got:\n%s", data)
+	}
+
+	decoded, err := DecodeManifests(data)
+	if err != nil {
+		t.Fatalf("DecodeManifests failed: %v", err)
+	}
+	if len(decoded) != 3 {
+		t.Fatalf("expected 3 manifests, got %d", len(decoded))
+	}
+	for i, kind := range []string{"App", "Config", "Volume"} {
+		if decoded[i].Kind != kind {
+			t.Errorf("expected manifest #%d to be %s, got %s", i, kind, decoded[i].Kind)
+		}
+	}
+
+	jsonData, err := decoded[0].JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	c := &AppCoder{}
+	if err := c.Decode(jsonData); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if c.Request.App != "example-go" || c.Request.Autodeploy == nil || !*c.Request.Autodeploy {
+		t.Errorf("unexpected app settings: %+v", c.Request)
+	}
+}
+
+func TestDecodeManifestsSkipsEmptyDocuments(t *testing.T) {
+	t.Parallel()
+
+	manifests, err := DecodeManifests([]byte("---\nkind: Tags\nmetadata:\n  name: foo\n---\n---\n"))
+	if err != nil {
+		t.Fatalf("DecodeManifests failed: %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Metadata.Name != "foo" {
+		t.Errorf("unexpected manifests: %+v", manifests)
+	}
+}
+
+func TestDecodeManifestsMissingKind(t *testing.T) {
+	t.Parallel()
+
+	if _, err := DecodeManifests([]byte("metadata:\n  name: foo\n")); err == nil {
+		t.Error("expected error for missing kind")
+	}
+}
+
+func TestDecodeUnexpectedKind(t *testing.T) {
+	t.Parallel()
+
+	c := &VolumeCoder{}
+	if err := c.Decode([]byte(`{"kind": "Domain", "metadata": {"name": "foo"}}`)); err == nil {
+		t.Error("expected error for unexpected kind")
+	}
+}
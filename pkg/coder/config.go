@@ -0,0 +1,199 @@
+package coder
+
+import (
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// ConfigCoder implements Coder for the Config resource of an app, named after
+// the app.
+//
+// Decode field mapping:
+//
+//	spec.values → Values
+//	spec.refs   → ValuesRefs
+type ConfigCoder struct {
+	Request api.Config
+	Info    api.Config
+}
+
+// Decode unmarshals JSON data into the config request.
+func (c *ConfigCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Config")
+	if err != nil {
+		return err
+	}
+
+	c.Request = api.Config{}
+	if values, ok := env.Spec["values"]; ok {
+		if err := convert(values, &c.Request.Values); err != nil {
+			return err
+		}
+	}
+	if refs, ok := env.Spec["refs"]; ok {
+		if err := convert(refs, &c.Request.ValuesRefs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encode marshals the config into a YAML manifest.
+func (c *ConfigCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the config into a manifest.
+func (c *ConfigCoder) Manifest() Manifest {
+	spec := make(map[string]any)
+	if len(c.Info.Values) > 0 {
+		spec["values"] = normalize(c.Info.Values)
+	}
+	if len(c.Info.ValuesRefs) > 0 {
+		spec["refs"] = normalize(c.Info.ValuesRefs)
+	}
+	return newManifest("Config", c.Info.App, spec)
+}
+
+// LimitsCoder implements Coder for the Limits resource of an app, a map of
+// process types to limit plans.
+//
+// Decode field mapping:
+//
+//	spec → Limits
+type LimitsCoder struct {
+	Request api.Config
+	Info    api.Config
+}
+
+// Decode unmarshals JSON data into the config request.
+func (c *LimitsCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Limits")
+	if err != nil {
+		return err
+	}
+	c.Request = api.Config{Limits: make(map[string]any)}
+	return convert(env.Spec, &c.Request.Limits)
+}
+
+// Encode marshals the limits into a YAML manifest.
+func (c *LimitsCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the limits into a manifest.
+func (c *LimitsCoder) Manifest() Manifest {
+	return newManifest("Limits", c.Info.App, c.Info.Limits)
+}
+
+// TimeoutsCoder implements Coder for the Timeouts resource of an app, a map
+// of process types to termination grace periods in seconds.
+//
+// Decode field mapping:
+//
+//	spec → Timeout
+type TimeoutsCoder struct {
+	Request api.Config
+	Info    api.Config
+}
+
+// Decode unmarshals JSON data into the config request.
+func (c *TimeoutsCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Timeouts")
+	if err != nil {
+		return err
+	}
+	c.Request = api.Config{Timeout: make(map[string]any)}
+	return convert(env.Spec, &c.Request.Timeout)
+}
+
+// Encode marshals the timeouts into a YAML manifest.
+func (c *TimeoutsCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the timeouts into a manifest.
+func (c *TimeoutsCoder) Manifest() Manifest {
+	return newManifest("Timeouts", c.Info.App, c.Info.Timeout)
+}
+
+// TagsCoder implements Coder for the Tags resource of an app, a map of
+// process types to node selector tags.
+//
+// Decode field mapping:
+//
+//	spec → Tags
+type TagsCoder struct {
+	Request api.Config
+	Info    api.Config
+}
+
+// Decode unmarshals JSON data into the config request.
+func (c *TagsCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Tags")
+	if err != nil {
+		return err
+	}
+	c.Request = api.Config{Tags: make(map[string]api.ConfigTags)}
+	return convert(env.Spec, &c.Request.Tags)
+}
+
+// Encode marshals the tags into a YAML manifest.
+func (c *TagsCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the tags into a manifest.
+func (c *TagsCoder) Manifest() Manifest {
+	return newManifest("Tags", c.Info.App, normalizeMap(c.Info.Tags))
+}
+
+// HealthcheckCoder implements Coder for the Healthcheck resource of a process
+// type, named after the process type.
+//
+// Decode field mapping:
+//
+//	Metadata.name       → Healthcheck key
+//	spec.startupProbe   → Healthcheck[ptype].StartupProbe
+//	spec.livenessProbe  → Healthcheck[ptype].LivenessProbe
+//	spec.readinessProbe → Healthcheck[ptype].ReadinessProbe
+type HealthcheckCoder struct {
+	Request api.Config
+	Info    api.Config
+	Ptype   string
+}
+
+// Decode unmarshals JSON data into the config request.
+func (c *HealthcheckCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Healthcheck")
+	if err != nil {
+		return err
+	}
+
+	healthcheck := &api.Healthcheck{}
+	if err := convert(env.Spec, healthcheck); err != nil {
+		return err
+	}
+	c.Ptype = env.Metadata.Name
+	c.Request = api.Config{Healthcheck: map[string]*api.Healthcheck{c.Ptype: healthcheck}}
+	return nil
+}
+
+// Encode marshals the healthcheck of Ptype into a YAML manifest.
+func (c *HealthcheckCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the healthcheck of Ptype into a manifest.
+func (c *HealthcheckCoder) Manifest() Manifest {
+	return newManifest("Healthcheck", c.Ptype, normalizeMap(c.Info.Healthcheck[c.Ptype]))
+}
+
+// normalizeMap converts a typed struct or map into a plain map[string]any,
+// nil values convert to a nil map.
+func normalizeMap(v any) map[string]any {
+	var result map[string]any
+	if err := convert(v, &result); err != nil {
+		return nil
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package coder
+
+import (
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+func TestConfigCoderDecode(t *testing.T) {
+	t.Parallel()
+
+	input := `{
+		"kind": "Config",
+		"metadata": {"name": "example-go"},
+		"spec": {
+			"values": [
+				{"name": "FOO", "value": "bar", "group": "global"},
+				{"name": "PORT", "value": "8000", "ptype": "web"}
+			]
+		}
+	}`
+
+	c := &ConfigCoder{}
+	if err := c.Decode([]byte(input)); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(c.Request.Values) != 2 {
+		t.Fatalf("expected 2 Values, got %d", len(c.Request.Values))
+	}
+	if c.Request.Values[0].Name != "FOO" || c.Request.Values[0].Value != "bar" || c.Request.Values[0].Group != "global" {
+		t.Errorf("unexpected value[0]: %+v", c.Request.Values[0])
+	}
+	if c.Request.Values[1].Ptype != "web" {
+		t.Errorf("unexpected value[1]: %+v", c.Request.Values[1])
+	}
+}
+
+func TestLimitsCoder(t *testing.T) {
+	t.Parallel()
+
+	c := &LimitsCoder{Info: api.Config{App: "example-go", Limits: map[string]any{"web": "std1.large.c1m1"}}}
+	data, err := c.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	expected := "apiVersion: " + APIVersion + `
+kind: Limits
+metadata:
+  name: example-go
+spec:
+  web: std1.large.c1m1
+`
+	if string(data) != expected {
+		t.Errorf("unexpected output:\n%s", data)
+	}
+
+	jsonData, err := c.Manifest().JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	decoded := &LimitsCoder{}
+	if err := decoded.Decode(jsonData); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if decoded.Request.Limits["web"] != "std1.large.c1m1" {
+		t.Errorf("unexpected limits: %v", decoded.Request.Limits)
+	}
+}
+
+func TestHealthcheckCoderDecode(t *testing.T) {
+	t.Parallel()
+
+	input := `{
+		"kind": "Healthcheck",
+		"metadata": {"name": "web"},
+		"spec": {
+			"livenessProbe": {
+				"httpGet": {"path": "/healthz", "port": 8000},
+				"initialDelaySeconds": 5
+			}
+		}
+	}`
+
+	c := &HealthcheckCoder{}
+	if err := c.Decode([]byte(input)); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	healthcheck := c.Request.Healthcheck["web"]
+	if c.Ptype != "web" || healthcheck == nil || healthcheck.LivenessProbe == nil {
+		t.Fatalf("unexpected healthcheck: %+v", c.Request.Healthcheck)
+	}
+	if (*healthcheck.LivenessProbe).HTTPGet == nil || (*healthcheck.LivenessProbe).HTTPGet.Path != "/healthz" {
+		t.Errorf("unexpected liveness probe: %+v", *healthcheck.LivenessProbe)
+	}
+	if (*healthcheck.LivenessProbe).InitialDelaySeconds != 5 {
+		t.Errorf("expected InitialDelaySeconds=5, got %d", (*healthcheck.LivenessProbe).InitialDelaySeconds)
+	}
+}
@@ -0,0 +1,39 @@
+package coder
+
+import (
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// DomainCoder implements Coder for Domain resources.
+//
+// Decode field mapping:
+//
+//	Metadata.name → Domain
+//	spec.ptype    → Ptype
+type DomainCoder struct {
+	Request api.DomainCreateRequest
+	Info    api.Domain
+}
+
+// Decode unmarshals JSON data into the domain request.
+func (c *DomainCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Domain")
+	if err != nil {
+		return err
+	}
+	c.Request = api.DomainCreateRequest{Domain: env.Metadata.Name}
+	if ptype, ok := env.Spec["ptype"].(string); ok {
+		c.Request.Ptype = ptype
+	}
+	return nil
+}
+
+// Encode marshals the domain into a YAML manifest.
+func (c *DomainCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the domain into a manifest.
+func (c *DomainCoder) Manifest() Manifest {
+	return newManifest("Domain", c.Info.Domain, map[string]any{"ptype": c.Info.Ptype})
+}
@@ -48,6 +48,11 @@ func (c *GatewayCoder) Decode(data []byte) error {
 
 // Encode marshals the Gateway info into a YAML manifest.
 func (c *GatewayCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the Gateway info into a manifest.
+func (c *GatewayCoder) Manifest() Manifest {
 	spec := map[string]any{"ports": normalize(c.Info.Ports)}
 
 	status := make(map[string]any)
@@ -55,13 +60,11 @@ func (c *GatewayCoder) Encode() ([]byte, error) {
 		status["addresses"] = normalize(c.Info.Addresses)
 	}
 
-	env := Manifest{
+	return Manifest{
 		APIVersion: APIVersion,
 		Kind:       "Gateway",
 		Metadata:   Metadata{Name: c.Info.Name},
 		Spec:       spec,
 		Status:     status,
 	}
-
-	return marshalYAML(env)
 }
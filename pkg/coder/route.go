@@ -69,6 +69,11 @@ func (c *RouteCoder) Decode(data []byte) error {
 
 // Encode marshals the Route info into a YAML manifest.
 func (c *RouteCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the Route info into a manifest.
+func (c *RouteCoder) Manifest() Manifest {
 	var rules []map[string]any
 	for _, r := range c.Info.Rules {
 		rule := map[string]any{}
@@ -87,13 +92,11 @@ func (c *RouteCoder) Encode() ([]byte, error) {
 		status["routable"] = c.Info.Routable
 	}
 
-	env := Manifest{
+	return Manifest{
 		APIVersion: APIVersion,
 		Kind:       c.Info.Kind,
 		Metadata:   Metadata{Name: c.Info.Name},
 		Spec:       spec,
 		Status:     status,
 	}
-
-	return marshalYAML(env)
 }
@@ -0,0 +1,51 @@
+package coder
+
+import (
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// ServiceCoder implements Coder for the Service resource of a process type,
+// named after the process type.
+//
+// Decode field mapping:
+//
+//	Metadata.name → Ptype
+//	spec.ports    → Ports
+//
+// Encode field mapping:
+//
+//	Ports  → spec.ports
+//	Domain → status.domain
+type ServiceCoder struct {
+	Request api.Service
+	Info    api.Service
+}
+
+// Decode unmarshals JSON data into the service request.
+func (c *ServiceCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Service")
+	if err != nil {
+		return err
+	}
+	c.Request = api.Service{Ptype: env.Metadata.Name}
+	if ports, ok := env.Spec["ports"]; ok {
+		if err := convert(ports, &c.Request.Ports); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encode marshals the service into a YAML manifest.
+func (c *ServiceCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the service into a manifest.
+func (c *ServiceCoder) Manifest() Manifest {
+	env := newManifest("Service", c.Info.Ptype, map[string]any{"ports": normalize(c.Info.Ports)})
+	if c.Info.Domain != "" {
+		env.Status = map[string]any{"domain": c.Info.Domain}
+	}
+	return env
+}
@@ -0,0 +1,50 @@
+package coder
+
+import (
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// VolumeCoder implements Coder for Volume resources.
+//
+// Decode field mapping:
+//
+//	Metadata.name   → Name
+//	spec.size       → Size
+//	spec.type       → Type
+//	spec.parameters → Parameters
+//	spec.path       → Path
+type VolumeCoder struct {
+	Request api.Volume
+	Info    api.Volume
+}
+
+// Decode unmarshals JSON data into the volume request.
+func (c *VolumeCoder) Decode(data []byte) error {
+	env, err := decodeManifest(data, "Volume")
+	if err != nil {
+		return err
+	}
+	c.Request = api.Volume{}
+	if err := convert(env.Spec, &c.Request); err != nil {
+		return err
+	}
+	c.Request.Name = env.Metadata.Name
+	return nil
+}
+
+// Encode marshals the volume into a YAML manifest.
+func (c *VolumeCoder) Encode() ([]byte, error) {
+	return marshalYAML(c.Manifest())
+}
+
+// Manifest converts the volume into a manifest.
+func (c *VolumeCoder) Manifest() Manifest {
+	spec := map[string]any{"size": c.Info.Size, "type": c.Info.Type}
+	if len(c.Info.Parameters) > 0 {
+		spec["parameters"] = normalize(c.Info.Parameters)
+	}
+	if len(c.Info.Path) > 0 {
+		spec["path"] = normalize(c.Info.Path)
+	}
+	return newManifest("Volume", c.Info.Name, spec)
+}
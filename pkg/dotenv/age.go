@@ -0,0 +1,114 @@
+package dotenv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// The values encrypted with age are written ENC[age,<base64>], the names of
+// the variables stay readable.
+const (
+	encryptedPrefix = "ENC[age,"
+	encryptedSuffix = "]"
+)
+
+// Encrypted tells whether a value is encrypted, see Encrypt.
+func Encrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) && strings.HasSuffix(value, encryptedSuffix)
+}
+
+// AnyEncrypted tells whether some of vars are encrypted.
+func AnyEncrypted(vars []Var) bool {
+	for _, v := range vars {
+		if v.File == "" && Encrypted(v.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadRecipients returns the age X25519 recipients of a file, one public key
+// per line, with blank lines and # comments.
+func ReadRecipients(path string) ([]age.Recipient, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("recipients: %w", err)
+	}
+	defer f.Close()
+	recipients, err := age.ParseRecipients(f)
+	if err != nil {
+		return nil, fmt.Errorf("recipients of %s: %w", path, err)
+	}
+	return recipients, nil
+}
+
+// ReadIdentities returns the age identities of a file, as written by
+// age-keygen.
+func ReadIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("identity: %w", err)
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("identity of %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// Encrypt encrypts the values of vars for recipients, the values already
+// encrypted and the references to files are kept.
+func Encrypt(vars []Var, recipients ...age.Recipient) error {
+	if len(recipients) == 0 {
+		return errors.New("no recipients to encrypt for")
+	}
+	for i, v := range vars {
+		if v.File != "" || Encrypted(v.Value) {
+			continue
+		}
+		var b bytes.Buffer
+		w, err := age.Encrypt(&b, recipients...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", v.Name, err)
+		}
+		if _, err := io.WriteString(w, v.Value); err != nil {
+			return fmt.Errorf("%s: %w", v.Name, err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("%s: %w", v.Name, err)
+		}
+		vars[i].Value = encryptedPrefix + base64.RawStdEncoding.EncodeToString(b.Bytes()) + encryptedSuffix
+	}
+	return nil
+}
+
+// Decrypt decrypts the encrypted values of vars with identities.
+func Decrypt(vars []Var, identities ...age.Identity) error {
+	for i, v := range vars {
+		if v.File != "" || !Encrypted(v.Value) {
+			continue
+		}
+		data, err := base64.RawStdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(v.Value, encryptedPrefix), encryptedSuffix))
+		if err != nil {
+			return fmt.Errorf("%s: %w", v.Name, err)
+		}
+		r, err := age.Decrypt(bytes.NewReader(data), identities...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", v.Name, err)
+		}
+		value, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("%s: %w", v.Name, err)
+		}
+		vars[i].Value = string(value)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypt(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+
+	vars := []Var{
+		{Name: "MODE", Value: "production"},
+		{Name: "KEY", Value: pem},
+		{Name: "EMPTY", Value: ""},
+		{Name: "TOKEN", File: "secrets/token"},
+	}
+	encrypted := append([]Var(nil), vars...)
+	assert.NoError(t, Encrypt(encrypted, identity.Recipient(), other.Recipient()))
+	assert.True(t, AnyEncrypted(encrypted))
+	for i, v := range encrypted[:3] {
+		assert.Equal(t, vars[i].Name, v.Name)
+		assert.True(t, Encrypted(v.Value), v.Name)
+		if vars[i].Value != "" {
+			assert.NotContains(t, v.Value, vars[i].Value)
+		}
+	}
+	assert.Equal(t, vars[3], encrypted[3])
+
+	// the values stay encrypted once encrypted, and are read back from a file
+	before := encrypted[0].Value
+	assert.NoError(t, Encrypt(encrypted, identity.Recipient()))
+	assert.Equal(t, before, encrypted[0].Value)
+	parsed, err := Parse([]byte(Format(encrypted)))
+	assert.NoError(t, err)
+	assert.Equal(t, encrypted, parsed)
+
+	assert.NoError(t, Decrypt(parsed, other))
+	assert.Equal(t, vars, parsed)
+	assert.False(t, AnyEncrypted(parsed))
+
+	stranger, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	err = Decrypt(encrypted, stranger)
+	assert.ErrorContains(t, err, "MODE: no identity matched any of the recipients")
+
+	assert.EqualError(t, Encrypt([]Var{{Name: "MODE", Value: "test"}}), "no recipients to encrypt for")
+}
+
+func TestReadKeys(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	dir := t.TempDir()
+
+	recipients := filepath.Join(dir, "recipients")
+	data := "# alice\n" + identity.Recipient().String() + "\n\n"
+	assert.NoError(t, os.WriteFile(recipients, []byte(data), 0o600))
+	keys, err := ReadRecipients(recipients)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	identities := filepath.Join(dir, "age.key")
+	data = "# created: 2026-10-17T00:00:00Z\n# public key: " + identity.Recipient().String() + "\n" + identity.String() + "\n"
+	assert.NoError(t, os.WriteFile(identities, []byte(data), 0o600))
+	ids, err := ReadIdentities(identities)
+	assert.NoError(t, err)
+	assert.Len(t, ids, 1)
+
+	assert.NoError(t, os.WriteFile(recipients, []byte("ssh-rsa AAAA\n"), 0o600))
+	_, err = ReadRecipients(recipients)
+	assert.ErrorContains(t, err, "recipients of "+recipients)
+	_, err = ReadIdentities(filepath.Join(dir, "missing"))
+	assert.True(t, strings.HasPrefix(err.Error(), "identity: "))
+}
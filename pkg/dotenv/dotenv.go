@@ -0,0 +1,312 @@
+// Package dotenv reads and writes the environment files of the config.
+//
+// A file holds a variable per line, as NAME=value, optionally prefixed by
+// export. Blank lines and the lines starting with # are ignored. The values
+// are:
+//
+//	unquoted       up to the end of the line or to a # following a space,
+//	               the surrounding spaces are trimmed
+//	'single'       as is, spanning lines until the closing quote
+//	"double"       spanning lines until the closing quote, with the escapes
+//	               \n, \r, \t, \", \\ and \$
+//
+// An unquoted value @path is read from the file at path, and - from the
+// standard input, see Resolve. The values may be encrypted with age, see
+// Encrypt.
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Stdin is the File of the values read from the standard input.
+const Stdin = "-"
+
+// Var is a variable of an environment file.
+type Var struct {
+	Name  string
+	Value string
+	// File is the path of the file the value is read from, or Stdin, see
+	// Resolve.
+	File string
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_\-\.]+$`)
+
+// Parse returns the variables of an environment file in their order. A
+// variable set again keeps its place with its last value.
+func Parse(data []byte) ([]Var, error) {
+	p := parser{input: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+	var vars []Var
+	index := make(map[string]int)
+	for {
+		p.skipBlank()
+		if p.done() {
+			return vars, nil
+		}
+		line := p.line
+		v, err := p.parseVar()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if i, ok := index[v.Name]; ok {
+			vars[i] = v
+			continue
+		}
+		index[v.Name] = len(vars)
+		vars = append(vars, v)
+	}
+}
+
+// ParseArg returns the variable of a NAME=value argument of the command
+// line, whose value is taken as is, quotes included, as the shell already
+// unquoted it. The values @path and - are read as in a file.
+func ParseArg(arg string) (Var, error) {
+	name, value, ok := strings.Cut(strings.TrimPrefix(arg, "export "), "=")
+	if !ok || !namePattern.MatchString(name) {
+		return Var{}, fmt.Errorf("'%s' does not match the pattern 'key=var', ex: MODE=test", arg)
+	}
+	return reference(Var{Name: name, Value: value}), nil
+}
+
+// Resolve reads the values of the variables from their files, the standard
+// input being read by stdin once. stdin is nil when it is not available.
+func Resolve(vars []Var, stdin io.Reader) error {
+	read := false
+	for i, v := range vars {
+		var data []byte
+		var err error
+		switch v.File {
+		case "":
+			continue
+		case Stdin:
+			if stdin == nil {
+				return fmt.Errorf("%s: the standard input is not available", v.Name)
+			}
+			if read {
+				return fmt.Errorf("%s: the standard input is read by another variable", v.Name)
+			}
+			read = true
+			data, err = io.ReadAll(stdin)
+		default:
+			data, err = os.ReadFile(v.File)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", v.Name, err)
+		}
+		vars[i].Value, vars[i].File = string(data), ""
+	}
+	return nil
+}
+
+// Format returns the environment file of vars, quoting the values that Parse
+// would not read back as they are. The variables of a file keep their
+// reference.
+func Format(vars []Var) string {
+	var b strings.Builder
+	for _, v := range vars {
+		b.WriteString(v.Name)
+		b.WriteByte('=')
+		switch v.File {
+		case "":
+			b.WriteString(Quote(v.Value))
+		case Stdin:
+			b.WriteString(Stdin)
+		default:
+			b.WriteString("@" + v.File)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Quote returns value as written in an environment file, double quoted when
+// it is not read back as is unquoted.
+func Quote(value string) string {
+	if !needsQuotes(value) {
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(value) + `"`
+}
+
+func needsQuotes(value string) bool {
+	if value == "" {
+		return false
+	}
+	if value == Stdin || value[0] == '@' || value[0] == '\'' || value[0] == '"' {
+		return true
+	}
+	if strings.TrimSpace(value) != value {
+		return true
+	}
+	return strings.ContainsAny(value, "\n\r\t\\\"") || strings.Contains(value, " #")
+}
+
+// reference sets the file of an unquoted value @path or -.
+func reference(v Var) Var {
+	switch {
+	case v.Value == Stdin:
+		v.Value, v.File = "", Stdin
+	case strings.HasPrefix(v.Value, "@") && len(v.Value) > 1:
+		v.Value, v.File = "", v.Value[1:]
+	}
+	return v
+}
+
+type parser struct {
+	input string
+	pos   int
+	line  int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	return p.input[p.pos]
+}
+
+// next returns the next byte, counting the lines.
+func (p *parser) next() byte {
+	c := p.input[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipSpaces skips the spaces and tabs of the line.
+func (p *parser) skipSpaces() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipBlank skips the blank lines and the comments.
+func (p *parser) skipBlank() {
+	for !p.done() {
+		switch p.peek() {
+		case ' ', '\t', '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) skipLine() {
+	for !p.done() && p.next() != '\n' {
+	}
+}
+
+func (p *parser) parseVar() (Var, error) {
+	start := p.pos
+	for !p.done() && p.peek() != '=' && p.peek() != '\n' {
+		p.pos++
+	}
+	if p.done() || p.peek() != '=' {
+		return Var{}, fmt.Errorf("'%s' does not match the pattern 'key=var', ex: MODE=test", p.input[start:p.pos])
+	}
+	name := strings.TrimSpace(p.input[start:p.pos])
+	if rest, ok := strings.CutPrefix(name, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		name = strings.TrimSpace(rest)
+	}
+	if !namePattern.MatchString(name) {
+		return Var{}, fmt.Errorf("invalid name '%s'", name)
+	}
+	p.pos++ // =
+	p.skipSpaces()
+
+	if p.done() || p.peek() == '\n' {
+		return Var{Name: name}, nil
+	}
+	switch p.peek() {
+	case '\'', '"':
+		value, err := p.parseQuoted()
+		if err != nil {
+			return Var{}, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := p.endLine(); err != nil {
+			return Var{}, fmt.Errorf("%s: %w", name, err)
+		}
+		return Var{Name: name, Value: value}, nil
+	}
+	return reference(Var{Name: name, Value: p.parseUnquoted()}), nil
+}
+
+// parseUnquoted reads a value up to the end of the line or a comment.
+func (p *parser) parseUnquoted() string {
+	start := p.pos
+	end := p.pos
+	for !p.done() && p.peek() != '\n' {
+		c := p.next()
+		if c == '#' && strings.ContainsRune(" \t", rune(p.input[p.pos-2])) {
+			p.skipLine()
+			break
+		}
+		end = p.pos
+	}
+	if !p.done() && p.peek() == '\n' {
+		p.next()
+	}
+	return strings.TrimSpace(p.input[start:end])
+}
+
+var errUnterminated = errors.New("unterminated quoted value")
+
+// parseQuoted reads a value up to its closing quote.
+func (p *parser) parseQuoted() (string, error) {
+	quote := p.next()
+	var b strings.Builder
+	for !p.done() {
+		c := p.next()
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && quote == '"' && !p.done():
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(e)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", errUnterminated
+}
+
+// endLine skips the spaces and the comment following a quoted value.
+func (p *parser) endLine() error {
+	p.skipSpaces()
+	if p.done() {
+		return nil
+	}
+	switch p.peek() {
+	case '\n':
+		p.next()
+		return nil
+	case '#':
+		p.skipLine()
+		return nil
+	}
+	return fmt.Errorf("unexpected '%c' after the quoted value", p.peek())
+}
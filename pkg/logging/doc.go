@@ -1,2 +1,4 @@
-// Package logging is used to print drycc application logs with colored output, when supported.
+// Package logging is used to print drycc application logs with colored output, when supported,
+// or to write them to sinks such as rotated files, syslog and OTLP collectors. JSON and logfmt
+// log lines are parsed so that they can be filtered by field and printed as NDJSON or logfmt.
 package logging
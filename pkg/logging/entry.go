@@ -0,0 +1,281 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Formats of log lines detected by ParseLine.
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+	FormatRaw    = "raw"
+)
+
+// aliases are the keys commonly used by logging libraries for the level, the
+// message and the time of a line.
+var aliases = map[string][]string{
+	"level": {"level", "lvl", "severity", "levelname", "log.level"},
+	"msg":   {"msg", "message", "log"},
+	"time":  {"time", "ts", "timestamp", "@timestamp"},
+}
+
+// levels ranks level names, the ranks are the OpenTelemetry severity numbers.
+var levels = map[string]int{
+	"trace": 1, "debug": 5, "info": 9, "notice": 10, "warn": 13, "warning": 13,
+	"err": 17, "error": 17, "crit": 21, "critical": 21, "fatal": 21, "panic": 21,
+}
+
+// numericLevels are the levels of libraries such as pino and bunyan.
+var numericLevels = map[string]string{
+	"10": "trace", "20": "debug", "30": "info", "40": "warn", "50": "error", "60": "fatal",
+}
+
+// Entry is a parsed log line.
+type Entry struct {
+	Line string
+	// Format is the format of Line, json, logfmt or raw when the line is not
+	// structured.
+	Format string
+	// Keys are the keys of Fields in the order of the line.
+	Keys   []string
+	Fields map[string]any
+}
+
+// ParseLine parses a JSON object or logfmt line, other lines are raw entries
+// with a msg field and a level field when the line starts with a level, ex:
+// "WARN disk is almost full".
+func ParseLine(line string) Entry {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		if keys, fields, err := parseJSONObject(trimmed); err == nil {
+			return Entry{Line: line, Format: FormatJSON, Keys: keys, Fields: fields}
+		}
+	}
+	if keys, fields, ok := parseLogfmt(trimmed); ok {
+		return Entry{Line: line, Format: FormatLogfmt, Keys: keys, Fields: fields}
+	}
+
+	entry := Entry{Line: line, Format: FormatRaw, Fields: make(map[string]any)}
+	if first, _, _ := strings.Cut(trimmed, " "); first != "" {
+		level := strings.ToLower(strings.Trim(first, "[]:"))
+		if _, ok := levels[level]; ok {
+			entry.Keys = append(entry.Keys, "level")
+			entry.Fields["level"] = level
+		}
+	}
+	entry.Keys = append(entry.Keys, "msg")
+	entry.Fields["msg"] = line
+	return entry
+}
+
+// Get returns the value of a field. Dotted names look into nested objects,
+// level, msg and time also match their usual aliases.
+func (e Entry) Get(name string) (any, bool) {
+	names := aliases[name]
+	if names == nil {
+		names = []string{name}
+	}
+	for _, name := range names {
+		if value, ok := e.Fields[name]; ok {
+			return value, true
+		}
+		if value, ok := lookupPath(e.Fields, name); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// Level returns the lower case level of the entry, or an empty string when
+// it has none.
+func (e Entry) Level() string {
+	value, ok := e.Get("level")
+	if !ok {
+		return ""
+	}
+	level := strings.ToLower(fieldString(value))
+	if name, ok := numericLevels[level]; ok {
+		return name
+	}
+	return level
+}
+
+// timeLayouts are the layouts of the times parsed by Time, after RFC3339.
+var timeLayouts = []string{"2006-01-02 15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"}
+
+// Time returns the time of the entry, from its time field or, for raw lines,
+// from a leading timestamp. Numbers are Unix times in seconds, milliseconds,
+// microseconds or nanoseconds.
+func (e Entry) Time() (time.Time, bool) {
+	if value, ok := e.Get("time"); ok {
+		return parseTime(fieldString(value), true)
+	}
+	if e.Format != FormatRaw {
+		return time.Time{}, false
+	}
+	// a timestamp of one or two words, ex: 2006-01-02 15:04:05
+	words := strings.Fields(e.Line)
+	for n := min(len(words), 2); n > 0; n-- {
+		if t, ok := parseTime(strings.Join(words[:n], " "), false); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseTime(text string, numeric bool) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return t, true
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	if !numeric {
+		return time.Time{}, false
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || number <= 0 {
+		return time.Time{}, false
+	}
+	switch {
+	case number >= 1e17:
+		number /= 1e9
+	case number >= 1e14:
+		number /= 1e6
+	case number >= 1e11:
+		number /= 1e3
+	}
+	seconds, fraction := math.Modf(number)
+	return time.Unix(int64(seconds), int64(fraction*1e9)).UTC(), true
+}
+
+func lookupPath(fields map[string]any, path string) (any, bool) {
+	key, rest, nested := strings.Cut(path, ".")
+	value, ok := fields[key]
+	if !ok || !nested {
+		return value, ok
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupPath(object, rest)
+}
+
+// fieldString returns a field value as text, strings are not quoted.
+func fieldString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	}
+	data, err := marshalJSON(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// parseJSONObject parses a JSON object and returns its keys in order.
+func parseJSONObject(line string) ([]string, map[string]any, error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, fmt.Errorf("not a JSON object")
+	}
+	var keys []string
+	fields := make(map[string]any)
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := token.(string)
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+		fields[key] = value
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	if dec.More() {
+		return nil, nil, fmt.Errorf("trailing data after JSON object")
+	}
+	return keys, fields, nil
+}
+
+// parseLogfmt parses key=value pairs separated by spaces, values may be
+// quoted. A line is only considered logfmt when every token is a pair and
+// there are at least two of them, so that prose with a '=' is not.
+func parseLogfmt(line string) ([]string, map[string]any, bool) {
+	var keys []string
+	fields := make(map[string]any)
+	for line != "" {
+		end := strings.IndexFunc(line, func(r rune) bool { return r == '=' || unicode.IsSpace(r) || r == '"' })
+		if end <= 0 || line[end] != '=' {
+			return nil, nil, false
+		}
+		key := line[:end]
+		line = line[end+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			quoted, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				return nil, nil, false
+			}
+			if value, err = strconv.Unquote(quoted); err != nil {
+				return nil, nil, false
+			}
+			line = line[len(quoted):]
+		} else {
+			end = strings.IndexFunc(line, unicode.IsSpace)
+			if end < 0 {
+				end = len(line)
+			}
+			value = line[:end]
+			if strings.Contains(value, `"`) {
+				return nil, nil, false
+			}
+			line = line[end:]
+		}
+		if line != "" && !unicode.IsSpace(rune(line[0])) {
+			return nil, nil, false
+		}
+		line = strings.TrimLeftFunc(line, unicode.IsSpace)
+
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+		fields[key] = value
+	}
+	return keys, fields, len(keys) >= 2
+}
+
+// marshalJSON encodes value without escaping HTML characters.
+func marshalJSON(value any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLine(t *testing.T) {
+	t.Parallel()
+
+	entry := ParseLine(`{"time":"2024-01-02T15:04:05Z","level":"warn","msg":"slow query","ms":1200,"db":{"name":"users"}}`)
+	assert.Equal(t, FormatJSON, entry.Format)
+	assert.Equal(t, []string{"time", "level", "msg", "ms", "db"}, entry.Keys)
+	assert.Equal(t, "warn", entry.Level())
+	value, ok := entry.Get("ms")
+	assert.True(t, ok)
+	assert.Equal(t, json.Number("1200"), value)
+	value, ok = entry.Get("db.name")
+	assert.True(t, ok)
+	assert.Equal(t, "users", value)
+
+	entry = ParseLine(`ts=2024-01-02T15:04:05Z lvl=ERROR message="connection refused" retry=3`)
+	assert.Equal(t, FormatLogfmt, entry.Format)
+	assert.Equal(t, []string{"ts", "lvl", "message", "retry"}, entry.Keys)
+	assert.Equal(t, "error", entry.Level())
+	value, _ = entry.Get("msg")
+	assert.Equal(t, "connection refused", value)
+	value, _ = entry.Get("time")
+	assert.Equal(t, "2024-01-02T15:04:05Z", value)
+
+	entry = ParseLine(`{"level":40,"msg":"pino"}`)
+	assert.Equal(t, "warn", entry.Level())
+
+	entry = ParseLine("[WARN] disk is almost full")
+	assert.Equal(t, FormatRaw, entry.Format)
+	assert.Equal(t, []string{"level", "msg"}, entry.Keys)
+	assert.Equal(t, "warn", entry.Level())
+	value, _ = entry.Get("msg")
+	assert.Equal(t, "[WARN] disk is almost full", value)
+
+	for _, line := range []string{
+		"a=b is not logfmt",
+		"only=one",
+		`{"truncated":`,
+		`{"a":1} {"b":2}`,
+		`key="unterminated`,
+		"",
+	} {
+		entry = ParseLine(line)
+		assert.Equal(t, FormatRaw, entry.Format, line)
+		assert.Equal(t, "", entry.Level(), line)
+	}
+}
+
+func TestEntryTime(t *testing.T) {
+	t.Parallel()
+
+	expected := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	for _, line := range []string{
+		`{"time":"2024-01-02T15:04:05Z","msg":"json"}`,
+		`{"ts":1704207845,"msg":"seconds"}`,
+		`{"ts":1704207845000,"msg":"milliseconds"}`,
+		`ts=1704207845000000000 msg=nanoseconds`,
+		"2024-01-02T15:04:05Z raw",
+		"2024-01-02 15:04:05 raw",
+	} {
+		value, ok := ParseLine(line).Time()
+		assert.True(t, ok, line)
+		assert.True(t, expected.Equal(value), line)
+	}
+
+	for _, line := range []string{
+		`{"msg":"no time"}`,
+		`{"time":"yesterday","msg":"bad time"}`,
+		"1704207845 is not a time of a raw line",
+		"",
+	} {
+		_, ok := ParseLine(line).Time()
+		assert.False(t, ok, line)
+	}
+}
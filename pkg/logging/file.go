@@ -0,0 +1,233 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "20060102T150405.000"
+
+// FileSink writes records to a file, which is rotated when it grows larger
+// than MaxSize or older than MaxAge. Rotated files are renamed with their
+// rotation time, ex: app.log.20240102T150405.000.gz, and only the MaxBackups
+// most recent ones are kept.
+//
+// The sink of file:///var/log/app.log?max-size=100MB&max-age=24h&max-backups=7&compress=false
+// rotates /var/log/app.log every 100MB or every day, keeps 7 plain backups.
+type FileSink struct {
+	Path string
+	// MaxSize is the size in bytes that triggers a rotation, 0 disables it.
+	MaxSize int64
+	// MaxAge is the age of the file that triggers a rotation, 0 disables it.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files to keep, 0 keeps them all.
+	MaxBackups int
+	// Compress gzips rotated files.
+	Compress bool
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	created time.Time
+	now     func() time.Time
+}
+
+func newFileSink(u *url.URL) (*FileSink, error) {
+	// file:///abs/path, file://relative/path and file:relative/path
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+	if path == "" {
+		return nil, errors.New("missing file path")
+	}
+	q := u.Query()
+	sink := &FileSink{Path: path, MaxSize: 100 << 20, MaxBackups: 5, Compress: true}
+	var err error
+	if q.Has("max-size") {
+		if sink.MaxSize, err = parseSize(q.Get("max-size")); err != nil {
+			return nil, err
+		}
+	}
+	if sink.MaxAge, err = queryDuration(q, "max-age", 0); err != nil {
+		return nil, err
+	}
+	if sink.MaxBackups, err = queryInt(q, "max-backups", sink.MaxBackups); err != nil {
+		return nil, err
+	}
+	if q.Has("compress") {
+		if sink.Compress, err = strconv.ParseBool(q.Get("compress")); err != nil {
+			return nil, fmt.Errorf("invalid compress %q", q.Get("compress"))
+		}
+	}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Write appends the message of rec to the file, rotating it first when
+// needed.
+func (f *FileSink) Write(rec Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	line := strings.TrimRight(rec.Message, "\n") + "\n"
+	if f.size > 0 && (f.MaxSize > 0 && f.size+int64(len(line)) > f.MaxSize ||
+		f.MaxAge > 0 && f.clock().Sub(f.created) >= f.MaxAge) {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := io.WriteString(f.file, line)
+	f.size += int64(n)
+	return err
+}
+
+// Close closes the file.
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *FileSink) clock() time.Time {
+	if f.now != nil {
+		return f.now()
+	}
+	return time.Now()
+}
+
+// open opens the file for appending, an existing file keeps its size and
+// age.
+func (f *FileSink) open() error {
+	if dir := filepath.Dir(f.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	f.created = f.clock()
+	if f.size > 0 {
+		f.created = info.ModTime()
+	}
+	return nil
+}
+
+// rotate renames the file with the current time, compresses it and removes
+// the oldest backups.
+func (f *FileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	backup := f.Path + "." + f.clock().Format(backupTimeFormat)
+	if err := os.Rename(f.Path, backup); err != nil {
+		return err
+	}
+	if f.Compress {
+		if err := compressFile(backup); err != nil {
+			return err
+		}
+	}
+	if err := f.removeBackups(); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// backups returns the rotated files from the oldest to the most recent.
+func (f *FileSink) backups() ([]string, error) {
+	matches, err := filepath.Glob(f.Path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, match := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(match, f.Path+"."), ".gz")
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	// the time format sorts as text
+	slices.Sort(backups)
+	return backups, nil
+}
+
+func (f *FileSink) removeBackups() error {
+	if f.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > f.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// compressFile replaces path with path.gz.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(path)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSinkRotateSize(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	sink := &FileSink{Path: path, MaxSize: 10, MaxBackups: 2, Compress: true, now: func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}}
+	for _, message := range []string{"line1", "line2", "line3", "line4"} {
+		assert.NoError(t, sink.Write(Record{Message: message}))
+	}
+	assert.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line4\n", string(data))
+
+	backups, err := sink.backups()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		path + ".20240102T150409.000.gz",
+		path + ".20240102T150411.000.gz",
+	}, backups)
+
+	f, err := os.Open(backups[1])
+	assert.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	data, err = io.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, "line3\n", string(data))
+}
+
+func TestFileSinkRotateAge(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	sink := &FileSink{Path: path, MaxAge: time.Hour, now: func() time.Time { return now }}
+	assert.NoError(t, sink.Write(Record{Message: "old"}))
+	now = now.Add(30 * time.Minute)
+	assert.NoError(t, sink.Write(Record{Message: "old again"}))
+	now = now.Add(30 * time.Minute)
+	assert.NoError(t, sink.Write(Record{Message: "new"}))
+	assert.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new\n", string(data))
+	data, err = os.ReadFile(path + ".20240102T160405.000")
+	assert.NoError(t, err)
+	assert.Equal(t, "old\nold again\n", string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, "app.log app.log.20240102T160405.000", strings.Join(names, " "))
+}
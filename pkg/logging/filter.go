@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// operators are tried in order at each position, so that >= is not read
+// as >.
+var operators = []string{">=", "<=", "!=", "!~", "=~", "==", ">", "<", "="}
+
+// Filter matches entries by the value of a field.
+type Filter struct {
+	Field    string
+	Operator string
+	Value    string
+
+	re *regexp.Regexp
+}
+
+// ParseFilter parses an expression such as level>=warn, status!=200 or
+// msg=~timeout. The operators are == (or =), !=, >, >=, <, <=, =~ and !~ for
+// regular expressions. Levels are compared by severity, numbers as numbers
+// and other values as text.
+func ParseFilter(expr string) (Filter, error) {
+	for i := range len(expr) {
+		for _, op := range operators {
+			if !strings.HasPrefix(expr[i:], op) {
+				continue
+			}
+			field := strings.TrimSpace(expr[:i])
+			value := strings.TrimSpace(expr[i+len(op):])
+			if field == "" {
+				return Filter{}, fmt.Errorf("invalid filter %s, expected <field><operator><value>, ex: level>=warn", expr)
+			}
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			if op == "=" {
+				op = "=="
+			}
+			filter := Filter{Field: field, Operator: op, Value: value}
+			if op == "=~" || op == "!~" {
+				re, err := regexp.Compile(value)
+				if err != nil {
+					return Filter{}, fmt.Errorf("invalid filter %s: %w", expr, err)
+				}
+				filter.re = re
+			}
+			return filter, nil
+		}
+	}
+	return Filter{}, fmt.Errorf("invalid filter %s, expected <field><operator><value>, ex: level>=warn", expr)
+}
+
+// Match reports whether the entry matches the filter. An entry without the
+// field only matches != and !~.
+func (f Filter) Match(e Entry) bool {
+	var actual string
+	var ok bool
+	if f.Field == "level" {
+		actual = e.Level()
+		ok = actual != ""
+	} else {
+		var value any
+		value, ok = e.Get(f.Field)
+		actual = fieldString(value)
+	}
+	if !ok {
+		return f.Operator == "!=" || f.Operator == "!~"
+	}
+
+	switch f.Operator {
+	case "=~":
+		return f.re.MatchString(actual)
+	case "!~":
+		return !f.re.MatchString(actual)
+	}
+	cmp := f.compare(actual)
+	switch f.Operator {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
+
+// compare compares actual with the value of the filter.
+func (f Filter) compare(actual string) int {
+	if f.Field == "level" {
+		a, okA := levels[actual]
+		b, okB := levels[strings.ToLower(f.Value)]
+		if okA && okB {
+			return a - b
+		}
+	}
+	a, errA := strconv.ParseFloat(actual, 64)
+	b, errB := strconv.ParseFloat(f.Value, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(actual, f.Value)
+}
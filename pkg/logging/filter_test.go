@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	for expr, expected := range map[string]Filter{
+		"level>=warn":     {Field: "level", Operator: ">=", Value: "warn"},
+		"status != 200":   {Field: "status", Operator: "!=", Value: "200"},
+		`msg="a b"`:       {Field: "msg", Operator: "==", Value: "a b"},
+		"user.id==42":     {Field: "user.id", Operator: "==", Value: "42"},
+		"latency<0.5":     {Field: "latency", Operator: "<", Value: "0.5"},
+		"path=~^/api/>=1": {Field: "path", Operator: "=~", Value: "^/api/>=1"},
+	} {
+		filter, err := ParseFilter(expr)
+		assert.NoError(t, err, expr)
+		filter.re = nil
+		assert.Equal(t, expected, filter, expr)
+	}
+
+	_, err := ParseFilter("level")
+	assert.EqualError(t, err, "invalid filter level, expected <field><operator><value>, ex: level>=warn")
+	_, err = ParseFilter(">=warn")
+	assert.Error(t, err)
+	_, err = ParseFilter("msg=~(")
+	assert.Error(t, err)
+}
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	entry := ParseLine(`{"level":"error","msg":"request timeout","status":504,"latency":1.5}`)
+	for expr, expected := range map[string]bool{
+		"level>=warn":   true,
+		"level>error":   false,
+		"level==ERROR":  true,
+		"level<info":    false,
+		"status!=200":   true,
+		"status>=500":   true,
+		"status<99":     false,
+		"latency>1.25":  true,
+		"msg=~timeout$": true,
+		"msg!~timeout":  false,
+		"missing==x":    false,
+		"missing!=x":    true,
+	} {
+		filter, err := ParseFilter(expr)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, filter.Match(entry), expr)
+	}
+
+	filter, _ := ParseFilter("level>=warn")
+	assert.True(t, filter.Match(ParseLine("WARN disk is almost full")))
+	assert.False(t, filter.Match(ParseLine("INFO started")))
+	assert.False(t, filter.Match(ParseLine("no level")))
+}
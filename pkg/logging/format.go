@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Formatter parses log lines, drops those that do not match its filters and
+// rewrites the others in its format:
+//
+//	raw     the line as is, or the values of Fields separated by spaces
+//	json    a JSON object per line (NDJSON) of the fields of the line, raw
+//	        lines are a msg field and their level
+//	logfmt  key=value pairs of the fields of the line
+//
+// Fields selects and orders the fields to print, app, pod and container are
+// the origin of the line unless the line has such fields.
+type Formatter struct {
+	Format  string
+	Fields  []string
+	Filters []Filter
+}
+
+// NewFormatter returns a formatter for a format and filter expressions, see
+// ParseFilter.
+func NewFormatter(format string, fields, filters []string) (*Formatter, error) {
+	if format == "" {
+		format = FormatRaw
+	}
+	if !slices.Contains([]string{FormatRaw, FormatJSON, FormatLogfmt}, format) {
+		return nil, fmt.Errorf("invalid log format %s, use raw, json or logfmt", format)
+	}
+	formatter := &Formatter{Format: format, Fields: fields}
+	for _, expr := range filters {
+		filter, err := ParseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		formatter.Filters = append(formatter.Filters, filter)
+	}
+	return formatter, nil
+}
+
+// Plain reports whether the lines must be printed without colors, which is
+// the case of structured formats.
+func (f *Formatter) Plain() bool {
+	return f.Format != FormatRaw
+}
+
+// Process returns rec with the level of its line and its message rewritten
+// in the format, or false when the line does not match the filters.
+func (f *Formatter) Process(rec Record) (Record, bool) {
+	entry := ParseLine(rec.Message)
+	for _, filter := range f.Filters {
+		if !filter.Match(entry) {
+			return rec, false
+		}
+	}
+	if level := entry.Level(); level != "" {
+		rec.Level = level
+	}
+	if f.Format == FormatRaw && len(f.Fields) == 0 {
+		return rec, true
+	}
+
+	keys := entry.Keys
+	values := make(map[string]any, len(entry.Fields))
+	if len(f.Fields) > 0 {
+		keys = f.Fields
+		for _, key := range keys {
+			if value, ok := f.field(rec, entry, key); ok {
+				values[key] = value
+			}
+		}
+	} else {
+		for _, key := range keys {
+			values[key] = entry.Fields[key]
+		}
+	}
+
+	switch f.Format {
+	case FormatJSON:
+		rec.Message = encodeJSON(keys, values)
+	case FormatLogfmt:
+		rec.Message = encodeLogfmt(keys, values)
+	default:
+		texts := make([]string, len(keys))
+		for i, key := range keys {
+			texts[i] = "-"
+			if value, ok := values[key]; ok {
+				texts[i] = fieldString(value)
+			}
+		}
+		rec.Message = strings.Join(texts, " ")
+	}
+	return rec, true
+}
+
+func (f *Formatter) field(rec Record, entry Entry, key string) (any, bool) {
+	if value, ok := entry.Get(key); ok {
+		return value, true
+	}
+	origin := map[string]string{"app": rec.App, "pod": rec.Pod, "container": rec.Container}
+	if value := origin[key]; value != "" {
+		return value, true
+	}
+	return nil, false
+}
+
+// encodeJSON encodes the values as a JSON object with the keys in order,
+// missing values are skipped.
+func encodeJSON(keys []string, values map[string]any) string {
+	var b strings.Builder
+	b.WriteString("{")
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		data, err := marshalJSON(value)
+		if err != nil {
+			data, _ = marshalJSON(fmt.Sprint(value))
+		}
+		if b.Len() > 1 {
+			b.WriteString(",")
+		}
+		name, _ := marshalJSON(key)
+		b.Write(name)
+		b.WriteString(":")
+		b.Write(data)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// encodeLogfmt encodes the values as key=value pairs, values with spaces,
+// quotes or equal signs are quoted, missing values are skipped.
+func encodeLogfmt(keys []string, values map[string]any) string {
+	var pairs []string
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		text := fieldString(value)
+		if text == "" || strings.ContainsAny(text, " =\"\t\n\r") {
+			text = strconv.Quote(text)
+		}
+		pairs = append(pairs, key+"="+text)
+	}
+	return strings.Join(pairs, " ")
+}
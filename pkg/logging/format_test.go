@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatter(t *testing.T) {
+	t.Parallel()
+
+	rec := Record{App: "foo", Pod: "foo-web-111", Message: `{"level":"warn","msg":"slow \"query\"","ms":1200}`}
+	for _, test := range []struct {
+		format   string
+		fields   []string
+		expected string
+	}{
+		{FormatRaw, nil, rec.Message},
+		{FormatRaw, []string{"level", "pod", "msg", "missing"}, `warn foo-web-111 slow "query" -`},
+		{FormatJSON, nil, `{"level":"warn","msg":"slow \"query\"","ms":1200}`},
+		{FormatJSON, []string{"pod", "msg"}, `{"pod":"foo-web-111","msg":"slow \"query\""}`},
+		{FormatLogfmt, nil, `level=warn msg="slow \"query\"" ms=1200`},
+	} {
+		formatter, err := NewFormatter(test.format, test.fields, nil)
+		assert.NoError(t, err)
+		actual, ok := formatter.Process(rec)
+		assert.True(t, ok)
+		assert.Equal(t, test.expected, actual.Message, test.format)
+		assert.Equal(t, "warn", actual.Level)
+	}
+
+	formatter, err := NewFormatter(FormatJSON, nil, nil)
+	assert.NoError(t, err)
+	actual, _ := formatter.Process(Record{Message: "ERROR <boom> & co"})
+	assert.Equal(t, `{"level":"error","msg":"ERROR <boom> & co"}`, actual.Message)
+	actual, _ = formatter.Process(Record{Message: "a=1 b=\"x y\""})
+	assert.Equal(t, `{"a":"1","b":"x y"}`, actual.Message)
+
+	formatter, err = NewFormatter("", nil, []string{"level>=error"})
+	assert.NoError(t, err)
+	_, ok := formatter.Process(rec)
+	assert.False(t, ok)
+
+	_, err = NewFormatter("xml", nil, nil)
+	assert.EqualError(t, err, "invalid log format xml, use raw, json or logfmt")
+	_, err = NewFormatter("json", nil, []string{"level"})
+	assert.Error(t, err)
+}
+
+func TestOptionsNewSink(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	sink, err := Options{Format: FormatJSON, Fields: []string{"msg"}, Filters: []string{"level>=warn"}}.NewSink(&b)
+	assert.NoError(t, err)
+	for _, line := range []string{
+		`{"level":"info","msg":"started"}`,
+		`{"level":"error","msg":"failed"}`,
+		"WARN plain",
+	} {
+		assert.NoError(t, sink.Write(Record{Message: line}))
+	}
+	assert.NoError(t, sink.Close())
+	assert.Equal(t, "{\"msg\":\"failed\"}\n{\"msg\":\"WARN plain\"}\n", b.String())
+}
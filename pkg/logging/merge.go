@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Merger is a sink ordering the records of several streams by time before
+// writing them to another sink. A record is held for Window after it is
+// received, so that the records of slower streams can be written before it.
+// Without a window the records are held until Close.
+type Merger struct {
+	Sink   Sink
+	Window time.Duration
+
+	mu      sync.Mutex
+	pending []pendingRecord
+	err     error
+	done    chan struct{}
+	stopped chan struct{}
+	// now is replaced by tests.
+	now func() time.Time
+}
+
+type pendingRecord struct {
+	rec      Record
+	received time.Time
+}
+
+// NewMerger returns a merger writing to sink, which flushes the records held
+// for window in the background when window is not 0.
+func NewMerger(sink Sink, window time.Duration) *Merger {
+	m := &Merger{Sink: sink, Window: window, now: time.Now, done: make(chan struct{}), stopped: make(chan struct{})}
+	if window <= 0 {
+		close(m.stopped)
+		return m
+	}
+	go func() {
+		defer close(m.stopped)
+		ticker := time.NewTicker(max(window/4, 10*time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.flush(false)
+			case <-m.done:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+// Write holds rec until it can be written in order, it returns the error of
+// the sink since the previous call, if any.
+func (m *Merger) Write(rec Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending = append(m.pending, pendingRecord{rec: rec, received: m.now()})
+	err := m.err
+	m.err = nil
+	return err
+}
+
+// Close writes the records held in order and closes the sink.
+func (m *Merger) Close() error {
+	close(m.done)
+	<-m.stopped
+	m.flush(true)
+	m.mu.Lock()
+	err := m.err
+	m.mu.Unlock()
+	if closeErr := m.Sink.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// flush writes the oldest records received more than a window ago, or all of
+// them with all. A recent record stops the flush, so that no later record is
+// written before it.
+func (m *Merger) flush(all bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	slices.SortStableFunc(m.pending, func(a, b pendingRecord) int {
+		return a.rec.Time.Compare(b.rec.Time)
+	})
+	deadline := m.now().Add(-m.Window)
+	n := 0
+	for ; n < len(m.pending); n++ {
+		if !all && m.pending[n].received.After(deadline) {
+			break
+		}
+		if err := m.Sink.Write(m.pending[n].rec); err != nil {
+			m.err = err
+		}
+	}
+	m.pending = slices.Delete(m.pending, 0, n)
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordSink struct {
+	mu      sync.Mutex
+	records []string
+	closed  bool
+}
+
+func (s *recordSink) Write(rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec.Message)
+	return nil
+}
+
+func (s *recordSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestMerger(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	var sink recordSink
+	merger := NewMerger(&sink, 0)
+	assert.NoError(t, merger.Write(Record{Time: start.Add(2 * time.Second), Message: "c"}))
+	assert.NoError(t, merger.Write(Record{Time: start, Message: "a"}))
+	assert.NoError(t, merger.Write(Record{Time: start.Add(time.Second), Message: "b1"}))
+	assert.NoError(t, merger.Write(Record{Time: start.Add(time.Second), Message: "b2"}))
+	assert.Empty(t, sink.records)
+	assert.NoError(t, merger.Close())
+	assert.Equal(t, []string{"a", "b1", "b2", "c"}, sink.records)
+	assert.True(t, sink.closed)
+}
+
+func TestMergerWindow(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	now := start
+	var sink recordSink
+	merger := &Merger{Sink: &sink, Window: time.Second, now: func() time.Time { return now }}
+	merger.Write(Record{Time: start.Add(time.Second), Message: "b"})
+	now = start.Add(500 * time.Millisecond)
+	merger.Write(Record{Time: start, Message: "a"})
+	merger.Write(Record{Time: start.Add(3 * time.Second), Message: "d"})
+	now = start.Add(1200 * time.Millisecond)
+	merger.Write(Record{Time: start.Add(2 * time.Second), Message: "c"})
+
+	// b waited for the window but a, which comes before, did not
+	merger.flush(false)
+	assert.Empty(t, sink.records)
+	now = start.Add(1600 * time.Millisecond)
+	merger.flush(false)
+	assert.Equal(t, []string{"a", "b"}, sink.records)
+	merger.flush(true)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, sink.records)
+}
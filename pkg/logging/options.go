@@ -0,0 +1,49 @@
+package logging
+
+import "io"
+
+// Options are the options of the commands streaming logs.
+type Options struct {
+	// Format is raw, json or logfmt, see Formatter.
+	Format  string
+	Fields  []string
+	Filters []string
+	// Sinks are the specs of the sinks, see NewSink.
+	Sinks []string
+	// Origin prints the pod of the raw lines printed to stdout.
+	Origin bool
+}
+
+// NewSink returns a sink formatting records before writing them to the sinks
+// of the options, stdout prints to out.
+func (o Options) NewSink(out io.Writer) (Sink, error) {
+	formatter, err := NewFormatter(o.Format, o.Fields, o.Filters)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := NewSinks(o.Sinks, &WriterSink{Out: out, Plain: formatter.Plain(), Origin: o.Origin})
+	if err != nil {
+		return nil, err
+	}
+	return &formatSink{formatter: formatter, sink: sink}, nil
+}
+
+type formatSink struct {
+	formatter *Formatter
+	sink      Sink
+}
+
+// Write writes rec once formatted, records dropped by the filters are not
+// written.
+func (f *formatSink) Write(rec Record) error {
+	rec, ok := f.formatter.Process(rec)
+	if !ok {
+		return nil
+	}
+	return f.sink.Write(rec)
+}
+
+// Close closes the sinks.
+func (f *formatSink) Close() error {
+	return f.sink.Close()
+}
@@ -0,0 +1,318 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OTLPSink exports records to an OpenTelemetry collector with the OTLP/HTTP
+// JSON encoding. Records are queued and sent in batches of BatchSize, or
+// every FlushInterval. Write blocks while the queue is full, so a slow
+// collector slows down the reading of logs instead of dropping them.
+//
+// The sink of otlp://collector:4318?batch-size=100&header=Authorization=Bearer%20x
+// posts batches of 100 records to http://collector:4318/v1/logs with the
+// given header, otlp+https:// uses https.
+type OTLPSink struct {
+	Endpoint      string
+	Headers       http.Header
+	BatchSize     int
+	FlushInterval time.Duration
+	// Retries is the number of times a batch is sent again when the
+	// collector is unavailable.
+	Retries int
+	Client  *http.Client
+
+	backoff time.Duration
+	queue   chan Record
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	errMu   sync.Mutex
+	err     error
+}
+
+func newOTLPSink(u *url.URL) (*OTLPSink, error) {
+	if u.Host == "" {
+		return nil, errors.New("missing collector host")
+	}
+	endpoint := url.URL{Scheme: "http", Host: u.Host, Path: u.Path}
+	if u.Scheme == "otlp+https" {
+		endpoint.Scheme = "https"
+	} else if u.Port() == "" {
+		endpoint.Host = net.JoinHostPort(u.Hostname(), "4318")
+	}
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = "/v1/logs"
+	}
+
+	q := u.Query()
+	headers := make(http.Header)
+	for _, header := range q["header"] {
+		key, value, ok := strings.Cut(header, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid header %q, use key=value", header)
+		}
+		headers.Add(key, value)
+	}
+	batchSize, err := queryInt(q, "batch-size", 512)
+	if err != nil {
+		return nil, err
+	}
+	queueSize, err := queryInt(q, "queue-size", 4096)
+	if err != nil {
+		return nil, err
+	}
+	flushInterval, err := queryDuration(q, "flush-interval", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := queryDuration(q, "timeout", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	retries, err := queryInt(q, "retries", 3)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &OTLPSink{
+		Endpoint:      endpoint.String(),
+		Headers:       headers,
+		BatchSize:     batchSize,
+		FlushInterval: flushInterval,
+		Retries:       retries,
+		Client:        &http.Client{Timeout: timeout},
+	}
+	sink.Start(queueSize)
+	return sink, nil
+}
+
+// Start starts the export of records with a queue of queueSize records.
+func (o *OTLPSink) Start(queueSize int) {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = 5 * time.Second
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+	if o.backoff == 0 {
+		o.backoff = time.Second
+	}
+	o.queue = make(chan Record, queueSize)
+	o.done = make(chan struct{})
+	go o.run()
+}
+
+// Write queues rec, it returns the error of the last failed export, if any.
+func (o *OTLPSink) Write(rec Record) error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.closed {
+		return errors.New("otlp sink is closed")
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	o.queue <- rec
+	return o.takeErr()
+}
+
+// Close exports the queued records and stops the export.
+func (o *OTLPSink) Close() error {
+	o.mu.Lock()
+	if !o.closed {
+		o.closed = true
+		close(o.queue)
+	}
+	o.mu.Unlock()
+
+	<-o.done
+	return o.takeErr()
+}
+
+func (o *OTLPSink) run() {
+	defer close(o.done)
+
+	ticker := time.NewTicker(o.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]Record, 0, o.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			o.setErr(o.export(batch))
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case rec, ok := <-o.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, rec)
+			if len(batch) >= o.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// export sends a batch, it is retried with an exponential backoff when the
+// collector is unreachable or asks to retry later.
+func (o *OTLPSink) export(batch []Record) error {
+	body, err := json.Marshal(otlpRequest(batch))
+	if err != nil {
+		return err
+	}
+	backoff := o.backoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := o.post(body)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt >= o.Retries {
+			return fmt.Errorf("otlp export of %d records: %w", len(batch), err)
+		}
+		if retryAfter == 0 {
+			retryAfter = backoff
+			backoff *= 2
+		}
+		time.Sleep(retryAfter)
+	}
+}
+
+// post sends body once, it returns the delay before a retry, 0 for the
+// default backoff or -1 when the request should not be retried.
+func (o *OTLPSink) post(body []byte) (time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPost, o.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	for key, values := range o.Headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := o.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return 0, nil
+	}
+	err = fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(message)))
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if seconds, parseErr := strconv.Atoi(res.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second, err
+		}
+		return 0, err
+	}
+	return -1, err
+}
+
+func (o *OTLPSink) setErr(err error) {
+	if err == nil {
+		return
+	}
+	o.errMu.Lock()
+	o.err = err
+	o.errMu.Unlock()
+}
+
+func (o *OTLPSink) takeErr() error {
+	o.errMu.Lock()
+	defer o.errMu.Unlock()
+	err := o.err
+	o.err = nil
+	return err
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string    `json:"timeUnixNano"`
+	ObservedTimeUnixNano string    `json:"observedTimeUnixNano"`
+	SeverityNumber       int       `json:"severityNumber,omitempty"`
+	SeverityText         string    `json:"severityText,omitempty"`
+	Body                 otlpValue `json:"body"`
+}
+
+type otlpScopeLogs struct {
+	Scope      map[string]string `json:"scope"`
+	LogRecords []otlpLogRecord   `json:"logRecords"`
+}
+
+type otlpResourceLogs struct {
+	Resource  map[string][]otlpAttribute `json:"resource"`
+	ScopeLogs []otlpScopeLogs            `json:"scopeLogs"`
+}
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+// otlpRequest groups a batch by app, pod and container, the resource of
+// their records.
+func otlpRequest(batch []Record) otlpLogsRequest {
+	var request otlpLogsRequest
+	index := make(map[[3]string]int)
+	for _, rec := range batch {
+		key := [3]string{rec.App, rec.Pod, rec.Container}
+		i, ok := index[key]
+		if !ok {
+			var attributes []otlpAttribute
+			for _, attr := range [][2]string{
+				{"service.name", rec.App},
+				{"k8s.pod.name", rec.Pod},
+				{"k8s.container.name", rec.Container},
+			} {
+				if attr[1] != "" {
+					attributes = append(attributes, otlpAttribute{attr[0], otlpValue{attr[1]}})
+				}
+			}
+			i = len(request.ResourceLogs)
+			index[key] = i
+			request.ResourceLogs = append(request.ResourceLogs, otlpResourceLogs{
+				Resource:  map[string][]otlpAttribute{"attributes": attributes},
+				ScopeLogs: []otlpScopeLogs{{Scope: map[string]string{"name": "drycc"}}},
+			})
+		}
+		level := strings.ToLower(rec.Level)
+		scope := &request.ResourceLogs[i].ScopeLogs[0]
+		scope.LogRecords = append(scope.LogRecords, otlpLogRecord{
+			TimeUnixNano:         strconv.FormatInt(rec.Time.UnixNano(), 10),
+			ObservedTimeUnixNano: strconv.FormatInt(rec.Time.UnixNano(), 10),
+			SeverityNumber:       levels[level],
+			SeverityText:         strings.ToUpper(rec.Level),
+			Body:                 otlpValue{strings.TrimRight(rec.Message, "\n")},
+		})
+	}
+	return request
+}
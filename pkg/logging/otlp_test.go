@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTLPSink(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var requests []otlpLogsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+		var request otlpLogsRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink, err := NewSink("otlp"+server.URL[len("http"):]+"?batch-size=2&flush-interval=1h&header=X-Token=secret", nil)
+	assert.NoError(t, err)
+	now := time.Unix(1704207845, 0)
+	for _, rec := range []Record{
+		{Time: now, App: "foo", Pod: "foo-web-111", Container: "web", Level: "warn", Message: "one\n"},
+		{Time: now, App: "foo", Pod: "foo-web-222", Message: "two"},
+		{Time: now, App: "foo", Pod: "foo-web-111", Container: "web", Message: "three"},
+	} {
+		assert.NoError(t, sink.Write(rec))
+	}
+	assert.NoError(t, sink.Close())
+
+	// a full batch, then the rest flushed by Close
+	assert.Len(t, requests, 2)
+	first := requests[0].ResourceLogs
+	assert.Len(t, first, 2)
+	assert.Equal(t, []otlpAttribute{
+		{"service.name", otlpValue{"foo"}},
+		{"k8s.pod.name", otlpValue{"foo-web-111"}},
+		{"k8s.container.name", otlpValue{"web"}},
+	}, first[0].Resource["attributes"])
+	assert.Equal(t, []otlpLogRecord{{
+		TimeUnixNano:         "1704207845000000000",
+		ObservedTimeUnixNano: "1704207845000000000",
+		SeverityNumber:       13,
+		SeverityText:         "WARN",
+		Body:                 otlpValue{"one"},
+	}}, first[0].ScopeLogs[0].LogRecords)
+	assert.Equal(t, "two", first[1].ScopeLogs[0].LogRecords[0].Body.StringValue)
+	assert.Equal(t, "three", requests[1].ResourceLogs[0].ScopeLogs[0].LogRecords[0].Body.StringValue)
+}
+
+func TestOTLPSinkRetry(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink := &OTLPSink{Endpoint: server.URL, BatchSize: 1, Retries: 2, backoff: time.Millisecond}
+	sink.Start(1)
+	assert.NoError(t, sink.Write(Record{Message: "hello"}))
+	assert.NoError(t, sink.Close())
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	sink = &OTLPSink{Endpoint: server.URL, BatchSize: 1, Retries: 1, backoff: time.Millisecond}
+	sink.Start(1)
+	assert.NoError(t, sink.Write(Record{Message: "hello"}))
+	assert.EqualError(t, sink.Close(), "otlp export of 1 records: 503 Service Unavailable: ")
+	assert.Error(t, sink.Write(Record{Message: "closed"}))
+}
+
+func TestOTLPSinkBackpressure(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	sink := &OTLPSink{Endpoint: server.URL, BatchSize: 1}
+	sink.Start(1)
+	// the first record is being exported and the second fills the queue
+	assert.NoError(t, sink.Write(Record{Message: "one"}))
+	assert.NoError(t, sink.Write(Record{Message: "two"}))
+	written := make(chan struct{})
+	go func() {
+		sink.Write(Record{Message: "three"})
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("write should block while the queue is full")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	<-written
+	assert.NoError(t, sink.Close())
+}
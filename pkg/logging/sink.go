@@ -0,0 +1,184 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a log line and what is known about where it comes from.
+type Record struct {
+	Time      time.Time
+	App       string
+	Pod       string
+	Container string
+	// Level is the severity of the line when known, such as info or error.
+	Level   string
+	Message string
+}
+
+// Sink receives log records. Write may block to apply backpressure when the
+// sink can not keep up, Close flushes buffered records.
+type Sink interface {
+	Write(rec Record) error
+	Close() error
+}
+
+// NewSink returns the sink described by spec:
+//
+//	stdout                                   write to stdout, the default
+//	file:///var/log/app.log?max-size=100MB   rotated files, see FileSink
+//	syslog://host:514, syslog+tcp://host:601 RFC5424 syslog, see SyslogSink
+//	otlp://host:4318, otlp+https://host      OTLP/HTTP log export, see OTLPSink
+func NewSink(spec string, stdout Sink) (Sink, error) {
+	if spec == "stdout" || spec == "-" {
+		return stdout, nil
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink %s: %w", spec, err)
+	}
+
+	var sink Sink
+	switch u.Scheme {
+	case "file":
+		sink, err = newFileSink(u)
+	case "syslog", "syslog+udp", "syslog+tcp":
+		sink, err = newSyslogSink(u)
+	case "otlp", "otlp+http", "otlp+https":
+		sink, err = newOTLPSink(u)
+	default:
+		return nil, fmt.Errorf("invalid sink %s: unsupported scheme %q, use stdout, file, syslog or otlp", spec, u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink %s: %w", spec, err)
+	}
+	return sink, nil
+}
+
+// NewSinks returns a sink writing to every sink of specs, or to stdout when
+// specs is empty.
+func NewSinks(specs []string, stdout Sink) (Sink, error) {
+	if len(specs) == 0 {
+		return stdout, nil
+	}
+	var sinks MultiSink
+	for _, spec := range specs {
+		sink, err := NewSink(spec, stdout)
+		if err != nil {
+			sinks.Close()
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return sinks, nil
+}
+
+// WriterSink prints records with PrintLog, or as is when Plain is set.
+// With Origin the records printed with PrintLog start with their pod, which
+// also chooses their color.
+type WriterSink struct {
+	Out    io.Writer
+	Plain  bool
+	Origin bool
+}
+
+// Write prints the message of rec.
+func (w *WriterSink) Write(rec Record) error {
+	if w.Plain {
+		_, err := fmt.Fprintln(w.Out, rec.Message)
+		return err
+	}
+	if w.Origin && rec.Pod != "" {
+		PrintLog(w.Out, rec.Pod+" | "+rec.Message)
+		return nil
+	}
+	PrintLog(w.Out, rec.Message)
+	return nil
+}
+
+// Close does nothing.
+func (w *WriterSink) Close() error {
+	return nil
+}
+
+// MultiSink writes records to several sinks.
+type MultiSink []Sink
+
+// Write writes rec to every sink, an error of a sink does not prevent the
+// others from receiving rec.
+func (m MultiSink) Write(rec Record) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Write(rec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink.
+func (m MultiSink) Close() error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseSize parses a size such as 512, 10KB, 100MB or 1G into bytes.
+func parseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "IB"), "B")
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSuffix(value, unit.suffix)
+			multiplier = unit.size
+			break
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return size * multiplier, nil
+}
+
+// queryInt returns the integer value of a query parameter, or def when it
+// is not set.
+func queryInt(q url.Values, key string, def int) (int, error) {
+	if !q.Has(key) {
+		return def, nil
+	}
+	value, err := strconv.Atoi(q.Get(key))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, q.Get(key))
+	}
+	return value, nil
+}
+
+// queryDuration returns the duration value of a query parameter, or def
+// when it is not set.
+func queryDuration(q url.Values, key string, def time.Duration) (time.Duration, error) {
+	if !q.Has(key) {
+		return def, nil
+	}
+	value, err := time.ParseDuration(q.Get(key))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, q.Get(key))
+	}
+	return value, nil
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSink(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	stdout := &WriterSink{Out: &b, Plain: true}
+	sink, err := NewSink("stdout", stdout)
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(Record{Message: "hello"}))
+	assert.Equal(t, "hello\n", b.String())
+
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	sink, err = NewSink("file://"+path+"?max-size=1KB&max-age=1h&max-backups=2&compress=false", stdout)
+	assert.NoError(t, err)
+	fileSink := sink.(*FileSink)
+	assert.Equal(t, path, fileSink.Path)
+	assert.Equal(t, int64(1024), fileSink.MaxSize)
+	assert.Equal(t, time.Hour, fileSink.MaxAge)
+	assert.Equal(t, 2, fileSink.MaxBackups)
+	assert.False(t, fileSink.Compress)
+	assert.NoError(t, sink.Close())
+
+	sink, err = NewSink("otlp://localhost?batch-size=10&header=Authorization=Bearer%20x", stdout)
+	assert.NoError(t, err)
+	otlpSink := sink.(*OTLPSink)
+	assert.Equal(t, "http://localhost:4318/v1/logs", otlpSink.Endpoint)
+	assert.Equal(t, 10, otlpSink.BatchSize)
+	assert.Equal(t, "Bearer x", otlpSink.Headers.Get("Authorization"))
+	assert.NoError(t, sink.Close())
+
+	for spec, msg := range map[string]string{
+		"ftp://localhost":                 `invalid sink ftp://localhost: unsupported scheme "ftp", use stdout, file, syslog or otlp`,
+		"file://?max-size=1":              "invalid sink file://?max-size=1: missing file path",
+		"file:///tmp/a.log?max-size=big":  `invalid sink file:///tmp/a.log?max-size=big: invalid size "big"`,
+		"syslog://localhost?facility=foo": `invalid sink syslog://localhost?facility=foo: invalid facility "foo"`,
+		"otlp://localhost?header=foo":     `invalid sink otlp://localhost?header=foo: invalid header "foo", use key=value`,
+	} {
+		_, err := NewSink(spec, stdout)
+		assert.EqualError(t, err, msg, spec)
+	}
+}
+
+func TestNewSinks(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	stdout := &WriterSink{Out: &b}
+	sink, err := NewSinks(nil, stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, stdout, sink)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink, err = NewSinks([]string{"stdout", "file://" + path}, stdout)
+	assert.NoError(t, err)
+	assert.Len(t, sink, 2)
+	assert.NoError(t, sink.Write(Record{Message: "hello"}))
+	assert.NoError(t, sink.Close())
+	assert.Contains(t, b.String(), "hello")
+	assert.FileExists(t, path)
+
+	_, err = NewSinks([]string{"stdout", "bad://"}, stdout)
+	assert.Error(t, err)
+}
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]int64{
+		"512":   512,
+		"10KB":  10 << 10,
+		"100mb": 100 << 20,
+		"1G":    1 << 30,
+		"2GiB":  2 << 30,
+	} {
+		size, err := parseSize(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, size, input)
+	}
+	_, err := parseSize("-1")
+	assert.Error(t, err)
+}
+
+func TestWriterSinkOrigin(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	sink := &WriterSink{Out: &b, Origin: true}
+	assert.NoError(t, sink.Write(Record{Pod: "foo-web-1", Message: "hello"}))
+	assert.NoError(t, sink.Write(Record{Message: "world"}))
+	assert.Contains(t, b.String(), "foo-web-1 | hello")
+	assert.NotContains(t, b.String(), " | world")
+}
@@ -0,0 +1,165 @@
+package logging
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var syslogSeverities = map[string]int{
+	"emerg": 0, "panic": 0, "alert": 1, "crit": 2, "critical": 2, "fatal": 2,
+	"err": 3, "error": 3, "warn": 4, "warning": 4, "notice": 5,
+	"info": 6, "debug": 7, "trace": 7,
+}
+
+// SyslogSink sends records as RFC5424 messages over UDP, one message per
+// datagram, or over TCP with the octet counting framing of RFC6587.
+//
+// The sink of syslog+tcp://logs.example.com:601?facility=local0 sends the
+// records to logs.example.com over TCP with the local0 facility. The app,
+// the pod and the container of a record are its APP-NAME, HOSTNAME and
+// PROCID.
+type SyslogSink struct {
+	Network  string
+	Address  string
+	Facility int
+	// AppName replaces the app of records as APP-NAME when set.
+	AppName string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogSink(u *url.URL) (*SyslogSink, error) {
+	sink := &SyslogSink{Network: "udp", Address: u.Host, Facility: syslogFacilities["user"]}
+	if u.Scheme == "syslog+tcp" {
+		sink.Network = "tcp"
+	}
+	if sink.Address == "" {
+		return nil, fmt.Errorf("missing syslog host")
+	}
+	if u.Port() == "" {
+		port := "514"
+		if sink.Network == "tcp" {
+			port = "601"
+		}
+		sink.Address = net.JoinHostPort(u.Hostname(), port)
+	}
+	q := u.Query()
+	if facility := q.Get("facility"); facility != "" {
+		value, ok := syslogFacilities[strings.ToLower(facility)]
+		if !ok {
+			var err error
+			if value, err = strconv.Atoi(facility); err != nil || value < 0 || value > 23 {
+				return nil, fmt.Errorf("invalid facility %q", facility)
+			}
+		}
+		sink.Facility = value
+	}
+	sink.AppName = q.Get("app-name")
+	if err := sink.dial(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Write sends rec, the connection is opened again once when it was lost.
+func (s *SyslogSink) Write(rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.Format(rec)
+	if s.Network == "tcp" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+	var err error
+	for range 2 {
+		if s.conn == nil {
+			if err = s.dial(); err != nil {
+				continue
+			}
+		}
+		if _, err = s.conn.Write([]byte(msg)); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+// Close closes the connection.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// Format returns the RFC5424 message of rec.
+func (s *SyslogSink) Format(rec Record) string {
+	severity, ok := syslogSeverities[strings.ToLower(rec.Level)]
+	if !ok {
+		severity = syslogSeverities["info"]
+	}
+	timestamp := rec.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	appName := s.AppName
+	if appName == "" {
+		appName = rec.App
+	}
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	return fmt.Sprintf("<%d>1 %s %s %s %s - - %s",
+		s.Facility*8+severity,
+		timestamp.UTC().Format(time.RFC3339Nano),
+		syslogField(rec.Pod, 255),
+		syslogField(appName, 48),
+		syslogField(rec.Container, 128),
+		strings.TrimRight(rec.Message, "\n"),
+	)
+}
+
+func (s *SyslogSink) dial() error {
+	conn, err := net.DialTimeout(s.Network, s.Address, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+// syslogField returns value as a header field of at most size printable
+// ASCII characters, or the nil value "-".
+func syslogField(value string, size int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if len(field) > size {
+		field = field[:size]
+	}
+	if field == "" {
+		return "-"
+	}
+	return field
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var syslogRecord = Record{
+	Time:      time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+	App:       "foo",
+	Pod:       "foo-web-111",
+	Container: "web",
+	Level:     "error",
+	Message:   "boom\n",
+}
+
+func TestSyslogFormat(t *testing.T) {
+	t.Parallel()
+
+	sink := &SyslogSink{Facility: syslogFacilities["local0"]}
+	assert.Equal(t, "<131>1 2024-01-02T15:04:05Z foo-web-111 foo web - - boom", sink.Format(syslogRecord))
+
+	sink = &SyslogSink{Facility: syslogFacilities["user"], AppName: "my app"}
+	assert.Equal(t, "<14>1 2024-01-02T15:04:05Z - myapp - - - hello", sink.Format(Record{Time: syslogRecord.Time, Message: "hello"}))
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewSink("syslog://"+conn.LocalAddr().String()+"?facility=local0", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(syslogRecord))
+	assert.NoError(t, sink.Close())
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "<131>1 2024-01-02T15:04:05Z foo-web-111 foo web - - boom", string(buf[:n]))
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var messages []string
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			messages = append(messages, line)
+			if err != nil {
+				break
+			}
+		}
+		received <- strings.Join(messages, "")
+	}()
+
+	sink, err := NewSink("syslog+tcp://"+listener.Addr().String(), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(syslogRecord))
+	assert.NoError(t, sink.Write(Record{Time: syslogRecord.Time, App: "foo", Message: "done"}))
+	assert.NoError(t, sink.Close())
+
+	assert.Equal(t,
+		"55 <11>1 2024-01-02T15:04:05Z foo-web-111 foo web - - boom"+
+			"43 <14>1 2024-01-02T15:04:05Z - foo - - - done",
+		<-received)
+}
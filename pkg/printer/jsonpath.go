@@ -0,0 +1,321 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPathPrinter prints the fields selected by a kubectl-style JSONPath
+// template, ex: {range .[*]}{.id}{"\t"}{.workspace}{"\n"}{end}.
+//
+// The supported subset covers what scripts usually need:
+//
+//	{.field.sub}       child fields
+//	{['field']}        bracket notation for names with special characters
+//	{.items[0]}        array index, negative values count from the end
+//	{.items[*]}, {.*}  wildcard over array items or map values
+//	{..field}          recursive descent
+//	{range x}...{end}  iterate, paths inside are relative to each item
+//	{"\n"}             quoted string literal
+//
+// Multiple results of a single expression are separated by a space.
+type JSONPathPrinter struct {
+	nodes []jsonPathNode
+}
+
+type jsonPathNode struct {
+	text     string
+	path     []jsonPathSegment
+	isPath   bool
+	children []jsonPathNode
+	isRange  bool
+}
+
+type jsonPathSegment struct {
+	name      string
+	index     int
+	wildcard  bool
+	isIndex   bool
+	recursive bool
+}
+
+// NewJSONPathPrinter parses a JSONPath template and returns a JSONPathPrinter.
+func NewJSONPathPrinter(text string) (*JSONPathPrinter, error) {
+	nodes, _, err := parseJSONPathNodes(text, false)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jsonpath %s: %w", text, err)
+	}
+	return &JSONPathPrinter{nodes: nodes}, nil
+}
+
+// Print evaluates the template against obj and writes the result to w.
+func (p *JSONPathPrinter) Print(w io.Writer, obj any) error {
+	data, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := executeJSONPath(&buf, p.nodes, data); err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// parseJSONPathNodes parses text until the end of input or, inside a range,
+// until the matching {end}. It returns the unparsed remainder.
+func parseJSONPathNodes(text string, inRange bool) ([]jsonPathNode, string, error) {
+	var nodes []jsonPathNode
+	for len(text) > 0 {
+		start := strings.Index(text, "{")
+		if start < 0 {
+			nodes = append(nodes, jsonPathNode{text: text})
+			break
+		}
+		if start > 0 {
+			nodes = append(nodes, jsonPathNode{text: text[:start]})
+		}
+		end := closingBrace(text[start:])
+		if end < 0 {
+			return nil, "", fmt.Errorf("unclosed action")
+		}
+		action := strings.TrimSpace(text[start+1 : start+end])
+		text = text[start+end+1:]
+
+		switch {
+		case action == "end":
+			if !inRange {
+				return nil, "", fmt.Errorf("not in range, nothing to end")
+			}
+			return nodes, text, nil
+		case strings.HasPrefix(action, "range "):
+			path, err := parseJSONPath(strings.TrimSpace(strings.TrimPrefix(action, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			children, rest, err := parseJSONPathNodes(text, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: path, isRange: true, children: children})
+			text = rest
+		case strings.HasPrefix(action, `"`):
+			literal, err := strconv.Unquote(action)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid string literal %s", action)
+			}
+			nodes = append(nodes, jsonPathNode{text: literal})
+		default:
+			path, err := parseJSONPath(action)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: path, isPath: true})
+		}
+	}
+	if inRange {
+		return nil, "", fmt.Errorf("range is not closed with {end}")
+	}
+	return nodes, "", nil
+}
+
+// closingBrace returns the index of the brace closing the action that starts
+// at text[0], skipping braces inside quoted literals.
+func closingBrace(text string) int {
+	quoted := false
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case '}':
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseJSONPath parses a path expression such as .items[*].name into segments.
+func parseJSONPath(expr string) ([]jsonPathSegment, error) {
+	expr = strings.TrimPrefix(strings.TrimPrefix(expr, "$"), "@")
+	var segments []jsonPathSegment
+	for len(expr) > 0 {
+		switch {
+		case strings.HasPrefix(expr, ".."):
+			name, rest := readJSONPathName(expr[2:])
+			if name == "" {
+				return nil, fmt.Errorf("missing field name after '..'")
+			}
+			segments = append(segments, jsonPathSegment{name: name, recursive: true})
+			expr = rest
+		case expr[0] == '.':
+			name, rest := readJSONPathName(expr[1:])
+			switch name {
+			case "":
+			case "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			default:
+				segments = append(segments, jsonPathSegment{name: name})
+			}
+			expr = rest
+		case expr[0] == '[':
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated array notation in %s", expr)
+			}
+			inner := strings.TrimSpace(expr[1:end])
+			expr = expr[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case strings.HasPrefix(inner, "'") && strings.HasSuffix(inner, "'") && len(inner) >= 2:
+				segments = append(segments, jsonPathSegment{name: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid array index %s", inner)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("unrecognized character in path: %s", expr)
+		}
+	}
+	return segments, nil
+}
+
+func readJSONPathName(expr string) (string, string) {
+	end := strings.IndexAny(expr, ".[")
+	if end < 0 {
+		return expr, ""
+	}
+	return expr[:end], expr[end:]
+}
+
+func executeJSONPath(buf *bytes.Buffer, nodes []jsonPathNode, data any) error {
+	for _, node := range nodes {
+		switch {
+		case node.isRange:
+			results := evalJSONPath(node.path, data)
+			if len(results) == 1 {
+				if items, ok := results[0].([]any); ok {
+					results = items
+				}
+			}
+			for _, item := range results {
+				if err := executeJSONPath(buf, node.children, item); err != nil {
+					return err
+				}
+			}
+		case node.isPath:
+			results := evalJSONPath(node.path, data)
+			for i, result := range results {
+				if i > 0 {
+					buf.WriteString(" ")
+				}
+				text, err := formatJSONPathValue(result)
+				if err != nil {
+					return err
+				}
+				buf.WriteString(text)
+			}
+		default:
+			buf.WriteString(node.text)
+		}
+	}
+	return nil
+}
+
+func evalJSONPath(segments []jsonPathSegment, data any) []any {
+	results := []any{data}
+	for _, segment := range segments {
+		var next []any
+		for _, value := range results {
+			switch {
+			case segment.recursive:
+				next = append(next, findRecursive(segment.name, value)...)
+			case segment.wildcard:
+				next = append(next, children(value)...)
+			case segment.isIndex:
+				if items, ok := value.([]any); ok {
+					index := segment.index
+					if index < 0 {
+						index += len(items)
+					}
+					if index >= 0 && index < len(items) {
+						next = append(next, items[index])
+					}
+				}
+			default:
+				if fields, ok := value.(map[string]any); ok {
+					if field, ok := fields[segment.name]; ok {
+						next = append(next, field)
+					}
+				}
+			}
+		}
+		results = next
+	}
+	return results
+}
+
+// children returns array items, or map values ordered by key.
+func children(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]any, 0, len(keys))
+		for _, k := range keys {
+			items = append(items, v[k])
+		}
+		return items
+	}
+	return nil
+}
+
+func findRecursive(name string, value any) []any {
+	var results []any
+	if fields, ok := value.(map[string]any); ok {
+		if field, ok := fields[name]; ok {
+			results = append(results, field)
+		}
+	}
+	for _, child := range children(value) {
+		results = append(results, findRecursive(name, child)...)
+	}
+	return results
+}
+
+func formatJSONPathValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,75 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonPathCase struct {
+	Template string
+	Expected string
+}
+
+func TestJSONPathPrinter(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{
+		"app": "foo",
+		"items": []any{
+			map[string]any{"name": "web", "replicas": 2, "ready": true},
+			map[string]any{"name": "worker", "replicas": 1, "ready": false},
+		},
+		"labels": map[string]any{"team": "core", "env": "prod", "dotted.key": "x"},
+		"nested": map[string]any{"inner": map[string]any{"name": "deep"}},
+	}
+
+	cases := []jsonPathCase{
+		{"{.app}", "foo"},
+		{"{$.app}", "foo"},
+		{"app={.app}", "app=foo"},
+		{"{.items[0].name}", "web"},
+		{"{.items[-1].name}", "worker"},
+		{"{.items[5].name}", ""},
+		{"{.items[*].name}", "web worker"},
+		{"{.items[*].replicas}", "2 1"},
+		{"{.items[1].ready}", "false"},
+		{"{.labels.*}", "x prod core"},
+		{"{.labels['dotted.key']}", "x"},
+		{"{..name}", "web worker deep"},
+		{"{.nested}", `{"inner":{"name":"deep"}}`},
+		{"{.missing}", ""},
+		{`{range .items[*]}{.name}{"\t"}{.replicas}{"\n"}{end}`, "web\t2\nworker\t1\n"},
+		{`{range .items}{@.name}{","}{end}`, "web,worker,"},
+		{`{"}"}`, "}"},
+	}
+
+	for _, check := range cases {
+		p, err := NewJSONPathPrinter(check.Template)
+		if !assert.NoError(t, err, check.Template) {
+			continue
+		}
+		var b bytes.Buffer
+		assert.NoError(t, p.Print(&b, data), check.Template)
+		assert.Equal(t, check.Expected, b.String(), check.Template)
+	}
+}
+
+func TestJSONPathPrinterErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, template := range []string{
+		"{.app",
+		"{range .items[*]}{.name}",
+		"{end}",
+		"{.items[x]}",
+		"{.items[0}",
+		"{..}",
+		"{app}",
+		`{"\q"}`,
+	} {
+		_, err := NewJSONPathPrinter(template)
+		assert.Error(t, err, template)
+	}
+}
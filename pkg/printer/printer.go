@@ -0,0 +1,143 @@
+// Package printer renders controller API objects in machine-readable formats
+// such as json, yaml, jsonpath and go-template for the Drycc CLI.
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Formats lists the output formats accepted by New.
+var Formats = []string{"json", "yaml", "jsonpath=", "go-template=", "go-template-file="}
+
+// Printer writes an object to an output writer.
+type Printer interface {
+	Print(w io.Writer, obj any) error
+}
+
+// New returns a Printer for the given output format. Formats that take an
+// argument use the kubectl convention, for example `jsonpath={.items[*].id}`.
+func New(output string) (Printer, error) {
+	format, arg, _ := strings.Cut(output, "=")
+	switch format {
+	case "json":
+		return &JSONPrinter{}, nil
+	case "yaml":
+		return &YAMLPrinter{}, nil
+	case "jsonpath":
+		if arg == "" {
+			return nil, fmt.Errorf("jsonpath output format requires a template, ex: jsonpath={.id}")
+		}
+		return NewJSONPathPrinter(arg)
+	case "go-template":
+		if arg == "" {
+			return nil, fmt.Errorf("go-template output format requires a template, ex: go-template={{.id}}")
+		}
+		return NewGoTemplatePrinter(arg)
+	case "go-template-file":
+		if arg == "" {
+			return nil, fmt.Errorf("go-template-file output format requires a file path")
+		}
+		contents, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return NewGoTemplatePrinter(string(contents))
+	}
+	return nil, fmt.Errorf("unsupported output format %q, allowed formats are: %s", output, strings.Join(Formats, ", "))
+}
+
+// JSONPrinter prints objects as indented JSON.
+type JSONPrinter struct{}
+
+// Print writes obj to w as indented JSON.
+func (p *JSONPrinter) Print(w io.Writer, obj any) error {
+	data, err := json.MarshalIndent(emptyIfNil(obj), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// YAMLPrinter prints objects as YAML using their JSON field names.
+type YAMLPrinter struct{}
+
+// Print writes obj to w as YAML.
+func (p *YAMLPrinter) Print(w io.Writer, obj any) error {
+	data, err := yaml.Marshal(emptyIfNil(obj))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// GoTemplatePrinter prints objects through a text/template. The template is
+// evaluated against the JSON representation of the object, so fields are
+// addressed by their API names, ex: {{range .}}{{.id}}{{"\n"}}{{end}}.
+type GoTemplatePrinter struct {
+	tmpl *template.Template
+}
+
+// NewGoTemplatePrinter parses text and returns a GoTemplatePrinter.
+func NewGoTemplatePrinter(text string) (*GoTemplatePrinter, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing go-template: %w", err)
+	}
+	return &GoTemplatePrinter{tmpl: tmpl}, nil
+}
+
+// Print executes the template against obj and writes the result to w.
+func (p *GoTemplatePrinter) Print(w io.Writer, obj any) error {
+	data, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing go-template: %w", err)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// toUnstructured converts a typed value into plain map[string]any / []any
+// via a JSON round-trip so templates see the API field names.
+func toUnstructured(obj any) (any, error) {
+	data, err := json.Marshal(emptyIfNil(obj))
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// emptyIfNil replaces nil slices and maps with empty ones so that an empty
+// result renders as [] or {} instead of null.
+func emptyIfNil(obj any) any {
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.MakeMap(v.Type()).Interface()
+		}
+	}
+	return obj
+}
@@ -0,0 +1,99 @@
+package printer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/stretchr/testify/assert"
+)
+
+var testApps = api.Apps{
+	{ID: "lorem-ipsum", Workspace: "dolar", UUID: "c4aed81c", Created: "2016-08-22T17:40:16Z", Updated: "2016-08-22T17:40:16Z"},
+	{ID: "consectetur", Workspace: "adipiscing", UUID: "d1ca4ff1", Created: "2016-08-22T17:40:16Z", Updated: "2016-08-22T17:40:16Z"},
+}
+
+func TestNewUnsupported(t *testing.T) {
+	t.Parallel()
+
+	for _, output := range []string{"wide", "jsonpath", "go-template=", "go-template-file="} {
+		_, err := New(output)
+		assert.Error(t, err, output)
+	}
+}
+
+func TestJSONPrinter(t *testing.T) {
+	t.Parallel()
+
+	p, err := New("json")
+	assert.NoError(t, err)
+	var b bytes.Buffer
+	assert.NoError(t, p.Print(&b, api.App{ID: "foo", Workspace: "bar", UID: 1000}))
+	assert.Equal(t, `{
+  "created": "",
+  "id": "foo",
+  "uid": 1000,
+  "workspace": "bar",
+  "updated": "",
+  "uuid": ""
+}
+`, b.String())
+
+	b.Reset()
+	var empty api.Apps
+	assert.NoError(t, p.Print(&b, empty))
+	assert.Equal(t, "[]\n", b.String())
+}
+
+func TestYAMLPrinter(t *testing.T) {
+	t.Parallel()
+
+	p, err := New("yaml")
+	assert.NoError(t, err)
+	var b bytes.Buffer
+	assert.NoError(t, p.Print(&b, testApps[:1]))
+	assert.Equal(t, `- created: "2016-08-22T17:40:16Z"
+  id: lorem-ipsum
+  uid: 0
+  updated: "2016-08-22T17:40:16Z"
+  uuid: c4aed81c
+  workspace: dolar
+`, b.String())
+}
+
+func TestGoTemplatePrinter(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(`go-template={{range .}}{{.id}}:{{.workspace}}{{"\n"}}{{end}}`)
+	assert.NoError(t, err)
+	var b bytes.Buffer
+	assert.NoError(t, p.Print(&b, testApps))
+	assert.Equal(t, "lorem-ipsum:dolar\nconsectetur:adipiscing\n", b.String())
+
+	_, err = New("go-template={{.id")
+	assert.Error(t, err)
+
+	p, err = New("go-template={{.missing}}")
+	assert.NoError(t, err)
+	assert.Error(t, p.Print(&b, api.App{}))
+}
+
+func TestGoTemplateFilePrinter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "apps.tmpl")
+	if err := os.WriteFile(file, []byte("{{len .}} apps\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := New("go-template-file=" + file)
+	assert.NoError(t, err)
+	var b bytes.Buffer
+	assert.NoError(t, p.Print(&b, testApps))
+	assert.Equal(t, "2 apps\n", b.String())
+
+	_, err = New("go-template-file=" + filepath.Join(dir, "missing.tmpl"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,216 @@
+// Package secret resolves the secret references of the config values, so that
+// the environment files hold where the secrets are rather than the secrets.
+//
+// A reference is written ref+<scheme>://<path>[#<field>]. The schemes are:
+//
+//	file    the content of the file at path, relative to the working
+//	        directory, as ref+file://secrets/db.txt#password
+//	env     the value of the environment variable path, as ref+env://DB_URL
+//	exec    the output of the command path run by the shell, as
+//	        ref+exec://pass show db
+//
+// The field selects a value of the secret read as a JSON object or an
+// environment file. A final newline of the secret is trimmed.
+//
+// The other schemes are resolved by the plugins of the providers, such as
+// Vault or 1Password, see Plugin.
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/drycc/workflow-cli/pkg/dotenv"
+)
+
+// Prefix is the prefix of the secret references.
+const Prefix = "ref+"
+
+var refPattern = regexp.MustCompile(`^ref\+([a-z][a-z0-9\-]*)://(.+)$`)
+
+// Ref is a secret reference.
+type Ref struct {
+	Scheme string
+	Path   string
+	// Field is the field of the secret selected, after the #.
+	Field string
+}
+
+// ParseRef returns the reference of a value, false when it is not one.
+func ParseRef(value string) (Ref, bool) {
+	m := refPattern.FindStringSubmatch(value)
+	if m == nil {
+		return Ref{}, false
+	}
+	path, field, _ := strings.Cut(m[2], "#")
+	return Ref{Scheme: m[1], Path: path, Field: field}, true
+}
+
+// IsRef tells whether a value is a secret reference.
+func IsRef(value string) bool {
+	_, ok := ParseRef(value)
+	return ok
+}
+
+func (r Ref) String() string {
+	s := Prefix + r.Scheme + "://" + r.Path
+	if r.Field != "" {
+		s += "#" + r.Field
+	}
+	return s
+}
+
+// Provider reads the secrets of a scheme.
+type Provider interface {
+	Get(ref Ref) (string, error)
+}
+
+// ProviderFunc is a function reading secrets as a Provider.
+type ProviderFunc func(ref Ref) (string, error)
+
+// Get calls f(ref).
+func (f ProviderFunc) Get(ref Ref) (string, error) {
+	return f(ref)
+}
+
+// Resolver resolves the secret references with the providers of their
+// schemes.
+type Resolver struct {
+	// Providers are the providers by scheme.
+	Providers map[string]Provider
+	// Lookup returns the provider of a scheme missing from Providers, if any.
+	Lookup func(scheme string) (Provider, bool)
+
+	cache map[string]string
+}
+
+// NewResolver returns a resolver of the schemes file, env and exec, and of
+// the schemes found by lookup when it is not nil.
+func NewResolver(lookup func(scheme string) (Provider, bool)) *Resolver {
+	return &Resolver{
+		Providers: map[string]Provider{
+			"file": ProviderFunc(readFile),
+			"env":  ProviderFunc(readEnv),
+			"exec": ProviderFunc(runCommand),
+		},
+		Lookup: lookup,
+	}
+}
+
+// Resolve returns the secret of a value that is a reference, or the value
+// itself. The secrets are read once by resolver.
+func (r *Resolver) Resolve(value string) (string, error) {
+	ref, ok := ParseRef(value)
+	if !ok {
+		return value, nil
+	}
+	if secret, ok := r.cache[value]; ok {
+		return secret, nil
+	}
+	provider, ok := r.Providers[ref.Scheme]
+	if !ok && r.Lookup != nil {
+		provider, ok = r.Lookup(ref.Scheme)
+	}
+	if !ok {
+		return "", fmt.Errorf("%s: no provider of the scheme %s", value, ref.Scheme)
+	}
+	secret, err := provider.Get(ref)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", value, err)
+	}
+	if r.cache == nil {
+		r.cache = make(map[string]string)
+	}
+	r.cache[value] = secret
+	return secret, nil
+}
+
+// Plugin returns the provider running the executable at path, with the
+// reference as its only argument. The plugin prints the secret to its
+// standard output, and the field selected when there is one.
+func Plugin(path string) Provider {
+	return ProviderFunc(func(ref Ref) (string, error) {
+		return output(exec.Command(path, ref.String()))
+	})
+}
+
+func readFile(ref Ref) (string, error) {
+	data, err := os.ReadFile(ref.Path)
+	if err != nil {
+		return "", err
+	}
+	return field(string(data), ref.Field)
+}
+
+func readEnv(ref Ref) (string, error) {
+	value, ok := os.LookupEnv(ref.Path)
+	if !ok {
+		return "", errors.New("the environment variable is not set")
+	}
+	return field(value, ref.Field)
+}
+
+func runCommand(ref Ref) (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	secret, err := output(exec.Command(shell, flag, ref.Path))
+	if err != nil {
+		return "", err
+	}
+	return field(secret, ref.Field)
+}
+
+// output returns the standard output of cmd, its standard error being that
+// of the client, for the providers asking for a password.
+func output(cmd *exec.Cmd) (string, error) {
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(out)), nil
+}
+
+// field returns the field name of a secret read as a JSON object or an
+// environment file, or the secret when name is empty.
+func field(secret, name string) (string, error) {
+	if name == "" {
+		return trimNewline(secret), nil
+	}
+	var object map[string]any
+	if err := json.Unmarshal([]byte(secret), &object); err == nil {
+		value, ok := object[name]
+		if !ok {
+			return "", fmt.Errorf("no field %s", name)
+		}
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		data, err := json.Marshal(value)
+		return string(bytes.TrimSpace(data)), err
+	}
+	vars, err := dotenv.Parse([]byte(secret))
+	if err != nil {
+		return "", fmt.Errorf("field %s: the secret is neither a JSON object nor an environment file", name)
+	}
+	for _, v := range vars {
+		if v.Name == name && v.File == "" {
+			return v.Value, nil
+		}
+	}
+	return "", fmt.Errorf("no field %s", name)
+}
+
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
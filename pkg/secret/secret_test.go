@@ -0,0 +1,102 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRef(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		value    string
+		expected Ref
+		ok       bool
+	}{
+		{"ref+file://secrets/db.txt#password", Ref{Scheme: "file", Path: "secrets/db.txt", Field: "password"}, true},
+		{"ref+env://DB_URL", Ref{Scheme: "env", Path: "DB_URL"}, true},
+		{"ref+exec://pass show db", Ref{Scheme: "exec", Path: "pass show db"}, true},
+		{"ref+1password://vault/item#otp", Ref{}, false},
+		{"ref+vault://", Ref{}, false},
+		{"ref+file:secrets", Ref{}, false},
+		{"production", Ref{}, false},
+	} {
+		actual, ok := ParseRef(test.value)
+		assert.Equal(t, test.ok, ok, test.value)
+		assert.Equal(t, test.expected, actual, test.value)
+		if ok {
+			assert.Equal(t, test.value, actual.String())
+		}
+	}
+	assert.True(t, IsRef("ref+op-connect://vault/item"))
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "db.txt"), []byte("s3cr3t\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "db.json"), []byte(`{"password": "s3cr3t", "port": 5432}`), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "db.env"), []byte("USER=app\nPASSWORD='s3cr3t'\n"), 0o600))
+	t.Setenv("TEST_SECRET", "from env")
+
+	var calls int
+	resolver := NewResolver(func(scheme string) (Provider, bool) {
+		if scheme != "vault" {
+			return nil, false
+		}
+		return ProviderFunc(func(ref Ref) (string, error) {
+			calls++
+			return ref.Path + " " + ref.Field, nil
+		}), true
+	})
+
+	for _, test := range []struct {
+		value    string
+		expected string
+	}{
+		{"production", "production"},
+		{"ref+file://" + filepath.Join(dir, "db.txt"), "s3cr3t"},
+		{"ref+file://" + filepath.Join(dir, "db.json") + "#password", "s3cr3t"},
+		{"ref+file://" + filepath.Join(dir, "db.json") + "#port", "5432"},
+		{"ref+file://" + filepath.Join(dir, "db.env") + "#PASSWORD", "s3cr3t"},
+		{"ref+env://TEST_SECRET", "from env"},
+		{"ref+exec://echo from exec", "from exec"},
+		{"ref+vault://secret/app#token", "secret/app token"},
+		{"ref+vault://secret/app#token", "secret/app token"},
+	} {
+		actual, err := resolver.Resolve(test.value)
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.expected, actual, test.value)
+	}
+	assert.Equal(t, 1, calls, "the secrets are read once")
+
+	for _, test := range []struct {
+		value    string
+		expected string
+	}{
+		{"ref+env://TEST_MISSING", "ref+env://TEST_MISSING: the environment variable is not set"},
+		{"ref+file://" + filepath.Join(dir, "db.json") + "#user", "ref+file://" + filepath.Join(dir, "db.json") + "#user: no field user"},
+		{"ref+exec://exit 3", "ref+exec://exit 3: exit status 3"},
+		{"ref+op://vault/item", "ref+op://vault/item: no provider of the scheme op"},
+	} {
+		_, err := resolver.Resolve(test.value)
+		assert.EqualError(t, err, test.expected, test.value)
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "drycc-secret-vault")
+	script := "#!/bin/sh\ntest \"$1\" = ref+vault://secret/app#token || exit 1\nprintf 'from vault\\n'\n"
+	assert.NoError(t, os.WriteFile(path, []byte(script), 0o700))
+
+	secret, err := Plugin(path).Get(Ref{Scheme: "vault", Path: "secret/app", Field: "token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "from vault", secret)
+
+	_, err = Plugin(path).Get(Ref{Scheme: "vault", Path: "secret/other"})
+	assert.EqualError(t, err, "exit status 1")
+}
@@ -0,0 +1,394 @@
+package settings
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+const (
+	// FileCredentialStore is the name of the built-in encrypted file keyring.
+	FileCredentialStore = "file"
+	// CredentialStoreEnv selects the credential store used by new logins.
+	CredentialStoreEnv = "DRYCC_CREDENTIAL_STORE"
+	// CredentialPassphraseEnv holds the passphrase of the file keyring.
+	CredentialPassphraseEnv = "DRYCC_CREDENTIAL_PASSPHRASE"
+	// CredentialKeyFileEnv holds the path of the key file of the file keyring,
+	// it is used when no passphrase is set.
+	CredentialKeyFileEnv = "DRYCC_CREDENTIAL_KEY_FILE"
+
+	// legacyKeyFile is the key file former versions generated next to the
+	// file keyring, it is only read to open their keyrings.
+	legacyKeyFile = "credentials.key"
+)
+
+// UnprotectedWarning warns that the file keyring keeps its key next to the
+// credentials, see UnprotectedCredentialStore.
+const UnprotectedWarning = "Warning: the token is encrypted with the key file " + legacyKeyFile + " next to it, " +
+	"anyone able to read the configuration directory can decrypt it. Remove credentials.enc and " + legacyKeyFile +
+	", set " + CredentialPassphraseEnv + " or " + CredentialKeyFileEnv + " and log in again, " +
+	"or log in with --credential-store <name> to use the drycc-credential-<name> helper of the OS keyring"
+
+// PassphrasePrompt asks for the passphrase of the file keyring at path when
+// neither a passphrase nor a key file is set, create tells that the keyring
+// is new. It reads the terminal and fails without one.
+var PassphrasePrompt = promptPassphrase
+
+var (
+	passphrasesMu sync.Mutex
+	// passphrases are the passphrases prompted by keyring path, so that they
+	// are asked once by process.
+	passphrases = make(map[string]string)
+)
+
+// ErrCredentialsNotFound is returned when a credential store holds no
+// credentials for a server.
+var ErrCredentialsNotFound = errors.New("credentials not found in credential store")
+
+// Credentials are the credentials of a user for a controller.
+//
+// The field names follow the docker credential helper protocol so existing
+// helpers can be wrapped with little effort.
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// CredentialStore keeps tokens out of the settings file.
+type CredentialStore interface {
+	// Get returns the credentials stored for serverURL.
+	Get(serverURL string) (*Credentials, error)
+	// Store saves credentials, replacing any stored for the same server.
+	Store(creds *Credentials) error
+	// Erase removes the credentials stored for serverURL.
+	Erase(serverURL string) error
+}
+
+// NewCredentialStore returns the credential store called name. "file" is the
+// encrypted keyring kept in dir, any other name is run as the credential
+// helper drycc-credential-<name> found in PATH.
+//
+// Without a passphrase or a key file set, the passphrase of the file keyring
+// is asked with PassphrasePrompt. A key file kept in dir protects nothing, so
+// it is only read for the keyrings former versions wrote with one.
+func NewCredentialStore(name, dir string) (CredentialStore, error) {
+	if name == FileCredentialStore {
+		keyring := &FileKeyring{
+			Path:       filepath.Join(dir, "credentials.enc"),
+			Passphrase: os.Getenv(CredentialPassphraseEnv),
+			KeyFile:    os.Getenv(CredentialKeyFileEnv),
+			Prompt:     PassphrasePrompt,
+		}
+		if keyring.Passphrase == "" && keyring.KeyFile == "" && legacyKeyring(dir) {
+			keyring.KeyFile = filepath.Join(dir, legacyKeyFile)
+		}
+		return keyring, nil
+	}
+	program, err := exec.LookPath("drycc-credential-" + name)
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %s not found: %w", name, err)
+	}
+	return &CredentialHelper{Program: program}, nil
+}
+
+// UnprotectedCredentialStore tells whether the credential store called name
+// in dir is a file keyring of a former version, whose key file is next to
+// the credentials.
+func UnprotectedCredentialStore(name, dir string) bool {
+	return name == FileCredentialStore && os.Getenv(CredentialPassphraseEnv) == "" &&
+		os.Getenv(CredentialKeyFileEnv) == "" && legacyKeyring(dir)
+}
+
+// legacyKeyring tells whether dir holds a file keyring and its key file.
+func legacyKeyring(dir string) bool {
+	for _, name := range []string{"credentials.enc", legacyKeyFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// promptPassphrase asks for the passphrase of the keyring at path on the
+// terminal, twice when the keyring is created.
+func promptPassphrase(path string, create bool) (string, error) {
+	passphrasesMu.Lock()
+	defer passphrasesMu.Unlock()
+	if passphrase, ok := passphrases[path]; ok {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("the keyring %s requires a passphrase, set %s or %s, or use a credential helper with --credential-store",
+			path, CredentialPassphraseEnv, CredentialKeyFileEnv)
+	}
+	read := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(data), err
+	}
+	passphrase, err := read(fmt.Sprintf("Passphrase of %s: ", path))
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("the passphrase must not be empty")
+	}
+	if create {
+		again, err := read("Repeat the passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("the passphrases do not match")
+		}
+	}
+	passphrases[path] = passphrase
+	return passphrase, nil
+}
+
+// credentialKey is the key under which the token of a context is stored,
+// the controller URL with the username as user info.
+func credentialKey(sF *settingsFile) string {
+	if i := strings.Index(sF.Controller, "://"); i >= 0 && sF.Username != "" {
+		return sF.Controller[:i+3] + sF.Username + "@" + sF.Controller[i+3:]
+	}
+	return sF.Controller
+}
+
+// FileKeyring stores credentials in a file encrypted with AES-256-GCM. The
+// encryption key is derived with scrypt from a passphrase or, when the
+// passphrase is empty, from the contents of a key file that is generated
+// on first use. Without both the passphrase is asked with Prompt.
+type FileKeyring struct {
+	Path       string
+	Passphrase string
+	KeyFile    string
+	Prompt     func(path string, create bool) (string, error)
+}
+
+type keyringFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// Get returns the credentials stored for serverURL.
+func (k *FileKeyring) Get(serverURL string) (*Credentials, error) {
+	entries, err := k.load()
+	if err != nil {
+		return nil, err
+	}
+	creds, ok := entries[serverURL]
+	if !ok {
+		return nil, ErrCredentialsNotFound
+	}
+	return creds, nil
+}
+
+// Store saves credentials, replacing any stored for the same server.
+func (k *FileKeyring) Store(creds *Credentials) error {
+	entries, err := k.load()
+	if err != nil {
+		return err
+	}
+	entries[creds.ServerURL] = creds
+	return k.save(entries)
+}
+
+// Erase removes the credentials stored for serverURL.
+func (k *FileKeyring) Erase(serverURL string) error {
+	entries, err := k.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[serverURL]; !ok {
+		return nil
+	}
+	delete(entries, serverURL)
+	if len(entries) == 0 {
+		return os.Remove(k.Path)
+	}
+	return k.save(entries)
+}
+
+func (k *FileKeyring) load() (map[string]*Credentials, error) {
+	entries := make(map[string]*Credentials)
+	contents, err := os.ReadFile(k.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+
+	file := keyringFile{}
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("invalid keyring %s: %w", k.Path, err)
+	}
+	aead, err := k.cipher(file.Salt, false)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt keyring %s, wrong passphrase or key file", k.Path)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (k *FileKeyring) save(entries map[string]*Credentials) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	file := keyringFile{Salt: make([]byte, 16)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	aead, err := k.cipher(file.Salt, true)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = aead.Seal(nil, file.Nonce, data, nil)
+
+	contents, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(k.Path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(k.Path, contents, 0o600)
+}
+
+// cipher derives the keyring key from the salt. The key file is generated
+// only when the keyring is written.
+func (k *FileKeyring) cipher(salt []byte, create bool) (cipher.AEAD, error) {
+	if k.Passphrase == "" && k.KeyFile == "" && k.Prompt != nil {
+		_, err := os.Stat(k.Path)
+		if k.Passphrase, err = k.Prompt(k.Path, os.IsNotExist(err)); err != nil {
+			return nil, err
+		}
+	}
+	secret := []byte(k.Passphrase)
+	if len(secret) == 0 {
+		var err error
+		if secret, err = k.readKeyFile(create); err != nil {
+			return nil, err
+		}
+	}
+	key, err := scrypt.Key(secret, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (k *FileKeyring) readKeyFile(create bool) ([]byte, error) {
+	if k.KeyFile == "" {
+		return nil, fmt.Errorf("the file keyring requires a passphrase or a key file")
+	}
+	secret, err := os.ReadFile(k.KeyFile)
+	if os.IsNotExist(err) && create {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(k.KeyFile), 0o700); err != nil {
+			return nil, err
+		}
+		return secret, os.WriteFile(k.KeyFile, secret, 0o600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("key file %s is empty", k.KeyFile)
+	}
+	return secret, nil
+}
+
+// CredentialHelper runs an external program speaking the docker credential
+// helper protocol: the action (get, store or erase) is the only argument,
+// store reads the credentials as JSON on stdin, get and erase read the server
+// URL on stdin and get writes the credentials as JSON on stdout.
+type CredentialHelper struct {
+	Program string
+}
+
+// Get returns the credentials stored for serverURL.
+func (h *CredentialHelper) Get(serverURL string) (*Credentials, error) {
+	out, err := h.run("get", []byte(serverURL))
+	if err != nil {
+		return nil, err
+	}
+	creds := &Credentials{}
+	if err := json.Unmarshal(out, creds); err != nil {
+		return nil, fmt.Errorf("invalid output from credential helper %s: %w", h.Program, err)
+	}
+	return creds, nil
+}
+
+// Store saves credentials, replacing any stored for the same server.
+func (h *CredentialHelper) Store(creds *Credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	_, err = h.run("store", data)
+	return err
+}
+
+// Erase removes the credentials stored for serverURL.
+func (h *CredentialHelper) Erase(serverURL string) error {
+	_, err := h.run("erase", []byte(serverURL))
+	if errors.Is(err, ErrCredentialsNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (h *CredentialHelper) run(action string, input []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(h.Program, action)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stdout.String() + stderr.String())
+		if strings.Contains(strings.ToLower(message), "credentials not found") {
+			return nil, ErrCredentialsNotFound
+		}
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, fmt.Errorf("credential helper %s %s: %s", filepath.Base(h.Program), action, message)
+	}
+	return stdout.Bytes(), nil
+}
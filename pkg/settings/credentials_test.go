@@ -0,0 +1,210 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileKeyringPassphrase(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	keyring := &FileKeyring{Path: path, Passphrase: "s3cret"}
+
+	_, err := keyring.Get("http://foo.bar")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+
+	creds := &Credentials{ServerURL: "http://t@foo.bar", Username: "t", Secret: "token"}
+	assert.NoError(t, keyring.Store(creds))
+
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(contents), "token")
+
+	got, err := keyring.Get("http://t@foo.bar")
+	assert.NoError(t, err)
+	assert.Equal(t, creds, got)
+
+	_, err = (&FileKeyring{Path: path, Passphrase: "wrong"}).Get("http://t@foo.bar")
+	assert.ErrorContains(t, err, "unable to decrypt keyring")
+
+	assert.NoError(t, keyring.Erase("http://t@foo.bar"))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileKeyringKeyFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	keyring := &FileKeyring{Path: filepath.Join(dir, "credentials.enc"), KeyFile: filepath.Join(dir, "credentials.key")}
+	assert.NoError(t, keyring.Store(&Credentials{ServerURL: "a", Secret: "1"}))
+	assert.NoError(t, keyring.Store(&Credentials{ServerURL: "b", Secret: "2"}))
+
+	info, err := os.Stat(keyring.KeyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(32), info.Size())
+
+	got, err := keyring.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", got.Secret)
+
+	assert.NoError(t, keyring.Erase("a"))
+	_, err = keyring.Get("a")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+	got, err = keyring.Get("b")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", got.Secret)
+}
+
+// createCredentialHelper writes a credential helper that keeps a single
+// credential in a file next to itself.
+func createCredentialHelper(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper script requires a unix shell")
+	}
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "drycc-credential-test")
+	script := `#!/bin/sh
+store="$(dirname "$0")/store.json"
+case "$1" in
+store) cat > "$store" ;;
+get) if [ -f "$store" ]; then cat "$store"; else echo "credentials not found in native keychain"; exit 1; fi ;;
+erase) rm -f "$store" ;;
+*) echo "unknown action $1"; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(helper, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return helper
+}
+
+func TestCredentialHelper(t *testing.T) {
+	t.Parallel()
+
+	helper := &CredentialHelper{Program: createCredentialHelper(t)}
+
+	_, err := helper.Get("http://t@foo.bar")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+
+	creds := &Credentials{ServerURL: "http://t@foo.bar", Username: "t", Secret: "token"}
+	assert.NoError(t, helper.Store(creds))
+	got, err := helper.Get("http://t@foo.bar")
+	assert.NoError(t, err)
+	assert.Equal(t, creds, got)
+
+	assert.NoError(t, helper.Erase("http://t@foo.bar"))
+	assert.NoError(t, helper.Erase("http://t@foo.bar"))
+
+	_, err = (&CredentialHelper{Program: helper.Program + "-missing"}).Get("http://t@foo.bar")
+	assert.Error(t, err)
+}
+
+func TestSaveCredentialStore(t *testing.T) {
+	t.Setenv(CredentialPassphraseEnv, "s3cret")
+
+	file, err := createTempProfile(sFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Loading a token written in the file moves it to the file keyring.
+	s, err := LoadContext(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", s.Client.Token)
+	assert.Equal(t, FileCredentialStore, s.CredentialStore)
+
+	contents, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(contents), `"token"`), string(contents))
+	assert.Contains(t, string(contents), `"credential_store":"file"`)
+
+	_, err = s.Save(file)
+	assert.NoError(t, err)
+	s, err = LoadContext(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", s.Client.Token)
+	assert.Equal(t, FileCredentialStore, s.CredentialStore)
+
+	// Deleting the last context erases its token from the keyring.
+	assert.NoError(t, DeleteContext(file, "default"))
+	_, err = os.Stat(filepath.Join(filepath.Dir(file), "credentials.enc"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLoadWithoutPassphrase(t *testing.T) {
+	t.Setenv(CredentialPassphraseEnv, "")
+	t.Setenv(CredentialKeyFileEnv, "")
+
+	file, err := createTempProfile(sFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the token stays in the file until a passphrase is set
+	s, err := LoadContext(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", s.Client.Token)
+	contents, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(contents), `"token"`)
+	_, err = os.Stat(filepath.Join(filepath.Dir(file), "credentials.enc"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileKeyringPrompt(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	_, err := (&FileKeyring{Path: path}).Get("a")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound, "nothing to decrypt")
+	err = (&FileKeyring{Path: path}).Store(&Credentials{ServerURL: "a", Secret: "1"})
+	assert.EqualError(t, err, "the file keyring requires a passphrase or a key file")
+
+	var prompts []bool
+	prompt := func(p string, create bool) (string, error) {
+		assert.Equal(t, path, p)
+		prompts = append(prompts, create)
+		return "s3cret", nil
+	}
+	assert.NoError(t, (&FileKeyring{Path: path, Prompt: prompt}).Store(&Credentials{ServerURL: "a", Secret: "1"}))
+	got, err := (&FileKeyring{Path: path, Prompt: prompt}).Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", got.Secret)
+	assert.Equal(t, []bool{true, false}, prompts, "the passphrase is repeated when the keyring is created")
+
+	got, err = (&FileKeyring{Path: path, Passphrase: "s3cret"}).Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", got.Secret)
+}
+
+func TestUnprotectedCredentialStore(t *testing.T) {
+	t.Setenv(CredentialPassphraseEnv, "")
+	t.Setenv(CredentialKeyFileEnv, "")
+
+	dir := t.TempDir()
+	assert.False(t, UnprotectedCredentialStore(FileCredentialStore, dir), "no keyring")
+
+	// a keyring of a former version, with its key file next to it
+	keyring := &FileKeyring{Path: filepath.Join(dir, "credentials.enc"), KeyFile: filepath.Join(dir, "credentials.key")}
+	assert.NoError(t, keyring.Store(&Credentials{ServerURL: "a", Secret: "1"}))
+	assert.True(t, UnprotectedCredentialStore(FileCredentialStore, dir))
+	assert.False(t, UnprotectedCredentialStore("osxkeychain", dir))
+	store, err := NewCredentialStore(FileCredentialStore, dir)
+	assert.NoError(t, err)
+	got, err := store.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", got.Secret)
+
+	t.Setenv(CredentialPassphraseEnv, "s3cret")
+	assert.False(t, UnprotectedCredentialStore(FileCredentialStore, dir))
+	t.Setenv(CredentialPassphraseEnv, "")
+	t.Setenv(CredentialKeyFileEnv, "/media/usb/drycc.key")
+	assert.False(t, UnprotectedCredentialStore(FileCredentialStore, dir))
+}
@@ -1,13 +1,25 @@
 package settings
 
 import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 
 	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/workflow-cli/pkg/transport"
 	"github.com/drycc/workflow-cli/version"
+	"k8s.io/klog/v2"
 )
 
 // DefaultResponseLimit is the default number of responses to return on requests that can
@@ -17,25 +29,87 @@ const DefaultResponseLimit = 100
 // UserAgent is the user agent used by the CLI
 var UserAgent = "Drycc Client " + version.Version
 
+// ContextEnv is the environment variable that overrides the current context
+// of the settings file, it is also set by the --context flag.
+const ContextEnv = "DRYCC_CONTEXT"
+
+// RequestTimeoutEnv and RetriesEnv override the request timeout and the
+// retries of the context, they are also set by the --request-timeout and
+// --retries flags.
+const (
+	RequestTimeoutEnv = "DRYCC_REQUEST_TIMEOUT"
+	RetriesEnv        = "DRYCC_RETRIES"
+)
+
+// CachedEnv caches the read-only requests and serves them from the cache when
+// it is set, see transport.Cache. It is also set by the --cached flag.
+const CachedEnv = "DRYCC_CACHED"
+
+// HAREnv names a file the requests are recorded to, as a HTTP Archive. It is
+// also set by the --har flag.
+const HAREnv = "DRYCC_HAR"
+
+// defaultContext is the name given to the context of a legacy settings file.
+const defaultContext = "default"
+
 type settingsFile struct {
 	Username   string `json:"username"`
 	VerifySSL  bool   `json:"ssl_verify"`
 	Controller string `json:"controller"`
-	Token      string `json:"token"`
+	Token      string `json:"token,omitempty"`
 	Limit      int    `json:"response_limit"`
 	Workspace  string `json:"workspace"`
+	// RequestTimeout and Retries are only set by hand, see transport.Transport.
+	RequestTimeout string `json:"request_timeout,omitempty"`
+	Retries        *int   `json:"retries,omitempty"`
+}
+
+// configFile is the on-disk layout of the settings file, it holds any number
+// of named contexts, the name of the one in use and the credential store the
+// tokens are kept in. Tokens are stored inline when no store is set.
+type configFile struct {
+	CurrentContext  string                   `json:"current_context"`
+	CredentialStore string                   `json:"credential_store,omitempty"`
+	Contexts        map[string]*settingsFile `json:"contexts"`
 }
 
 // Settings is the settings object created from the settings file.
 type Settings struct {
-	Username  string
-	Limit     int
-	Client    *drycc.Client
-	Workspace string
+	Context string
+	// CredentialStore is the credential store of the settings file, see
+	// NewCredentialStore. An empty value keeps the current store, tokens
+	// are moved to the file keyring when the settings file has none. Save
+	// sets it to the store the tokens are kept in.
+	CredentialStore string
+	Username        string
+	Limit           int
+	Client          *drycc.Client
+	Workspace       string
+	// RequestTimeout and Retries are the policy of the requests of Client,
+	// from the context or the environment.
+	RequestTimeout time.Duration
+	Retries        int
 }
 
-// Load loads a new client from a settings file.
+// Context describes a named context of the settings file, without its token.
+type Context struct {
+	Name       string `json:"name"`
+	Current    bool   `json:"current"`
+	Username   string `json:"username"`
+	Controller string `json:"controller"`
+	VerifySSL  bool   `json:"ssl_verify"`
+	Workspace  string `json:"workspace"`
+}
+
+// Load loads a new client from a settings file. The context named by the
+// DRYCC_CONTEXT environment variable is used if set, otherwise the current one.
 func Load(cf string) (*Settings, error) {
+	return LoadContext(cf, os.Getenv(ContextEnv))
+}
+
+// LoadContext loads a new client from the named context of a settings file.
+// An empty name selects the current context.
+func LoadContext(cf, name string) (*Settings, error) {
 	filename := locateSettingsFile(cf)
 
 	if _, err := os.Stat(filename); err != nil {
@@ -47,17 +121,47 @@ Are you logged in? Use 'drycc login' or 'drycc register' to get started`, filena
 		return nil, err
 	}
 
-	contents, err := os.ReadFile(filename)
+	config, err := readConfigFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	sF := settingsFile{}
-	if err = json.Unmarshal(contents, &sF); err != nil {
-		return nil, err
+	if name == "" {
+		name = config.CurrentContext
+	}
+	sF, ok := config.Contexts[name]
+	if !ok {
+		if name == "" {
+			return nil, fmt.Errorf("no current context is set in %s, use 'drycc contexts use' to select one", filename)
+		}
+		return nil, fmt.Errorf("context %s not found in %s", name, filename)
 	}
 
-	c, err := drycc.New(sF.VerifySSL, sF.Controller, sF.Token)
+	token := sF.Token
+	if token != "" {
+		// the tokens written in the settings file by former versions are moved
+		// to the credential store
+		if err := migrateCredentials(config, filepath.Dir(filename), cmp.Or(config.CredentialStore, FileCredentialStore)); err != nil {
+			klog.Warningf("The token of %s is not encrypted, it can not be moved to the credential store: %v", filename, err)
+		} else if err := writeConfigFile(filename, config); err != nil {
+			return nil, err
+		}
+	} else if config.CredentialStore != "" {
+		store, err := NewCredentialStore(config.CredentialStore, filepath.Dir(filename))
+		if err != nil {
+			return nil, err
+		}
+		creds, err := store.Get(credentialKey(sF))
+		if err != nil {
+			if errors.Is(err, ErrCredentialsNotFound) {
+				return nil, fmt.Errorf("no token for context %s found in credential store %s, use 'drycc login' to log in again", name, config.CredentialStore)
+			}
+			return nil, err
+		}
+		token = creds.Secret
+	}
+
+	c, err := drycc.New(sF.VerifySSL, sF.Controller, token)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +169,20 @@ Are you logged in? Use 'drycc login' or 'drycc register' to get started`, filena
 	// Set a custom user agent
 	c.UserAgent = UserAgent
 
-	settings := Settings{}
+	timeout, retries, err := requestPolicy(sF)
+	if err != nil {
+		return nil, err
+	}
+	c.HTTPClient.Transport = &transport.Cache{
+		Base:    NewTransport(c.HTTPClient.Transport, timeout, retries),
+		Host:    c.ControllerURL.Host,
+		Dir:     cacheDir(filename, sF),
+		Enabled: os.Getenv(CachedEnv) != "",
+	}
+
+	settings := Settings{RequestTimeout: timeout, Retries: retries}
+	settings.Context = name
+	settings.CredentialStore = config.CredentialStore
 	settings.Username = sF.Username
 	settings.Client = c
 	settings.Workspace = sF.Workspace
@@ -80,26 +197,319 @@ Are you logged in? Use 'drycc login' or 'drycc register' to get started`, filena
 	return &settings, nil
 }
 
-// Save settings to a file
+// NewTransport wraps base in a transport.Transport with the timeout and the
+// retries given, reporting the attempts retried to stderr. Each attempt is
+// traced, see transport.Trace.
+func NewTransport(base http.RoundTripper, timeout time.Duration, retries int) *transport.Transport {
+	return &transport.Transport{
+		Base:    &transport.Trace{Base: base, HAR: har()},
+		Timeout: timeout, Retries: retries, Backoff: transport.DefaultBackoff, Out: os.Stderr,
+	}
+}
+
+// har records the requests of all the clients to the file named by HAREnv.
+var har = sync.OnceValue(func() *transport.HAR {
+	if path := os.Getenv(HAREnv); path != "" {
+		return transport.NewHAR(path)
+	}
+	return nil
+})
+
+// FlushHAR writes the requests recorded to the file named by HAREnv, before
+// the CLI exits.
+func FlushHAR() {
+	har().Flush()
+}
+
+// cacheDir returns the directory caching the responses of a context, next to
+// the settings file, keyed by the controller, the user and the workspace.
+func cacheDir(filename string, sF *settingsFile) string {
+	sum := sha256.Sum256([]byte(sF.Controller + "\n" + sF.Username + "\n" + sF.Workspace))
+	return filepath.Join(filepath.Dir(filename), "cache", hex.EncodeToString(sum[:8]))
+}
+
+// UseCache caches the read-only requests of the client and serves them from
+// the cache, see transport.Cache.
+func (s *Settings) UseCache() {
+	if cache, ok := s.Client.HTTPClient.Transport.(*transport.Cache); ok {
+		cache.Enabled = true
+	}
+}
+
+// RequestPolicy returns the request timeout and the retries set by the
+// environment, for the clients not loaded from a context.
+func RequestPolicy() (time.Duration, int, error) {
+	return requestPolicy(&settingsFile{})
+}
+
+// requestPolicy returns the request timeout and the retries of a context,
+// overridden by the environment.
+func requestPolicy(sF *settingsFile) (time.Duration, int, error) {
+	timeout, retries := transport.DefaultTimeout, transport.DefaultRetries
+	value, source := sF.RequestTimeout, "request_timeout"
+	if env, ok := os.LookupEnv(RequestTimeoutEnv); ok {
+		value, source = env, RequestTimeoutEnv
+	}
+	if value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, 0, fmt.Errorf("invalid %s %q, use a duration such as 30s", source, value)
+		}
+		timeout = d
+	}
+	if sF.Retries != nil {
+		retries = *sF.Retries
+	}
+	if env, ok := os.LookupEnv(RetriesEnv); ok {
+		n, err := strconv.Atoi(env)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid %s %q, use a number of retries", RetriesEnv, env)
+		}
+		retries = n
+	}
+	if retries < 0 {
+		return 0, 0, fmt.Errorf("invalid retries %d, use a number of retries", retries)
+	}
+	return timeout, retries, nil
+}
+
+// Save settings to a file. The settings are stored in the context named by
+// s.Context, other contexts of the file are left untouched. When s.Context is
+// empty the context named by DRYCC_CONTEXT or the controller host is used.
 func (s *Settings) Save(cf string) (string, error) {
-	settings := settingsFile{
+	filename := locateSettingsFile(cf)
+
+	config, err := readConfigFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if s.Context == "" {
+		s.Context = os.Getenv(ContextEnv)
+	}
+	if s.Context == "" {
+		s.Context = s.Client.ControllerURL.Host
+	}
+
+	storeName := s.CredentialStore
+	if storeName == "" && config.CredentialStore == "" && s.Client.Token != "" {
+		storeName = FileCredentialStore
+	}
+	if storeName != "" && storeName != config.CredentialStore {
+		if err := migrateCredentials(config, filepath.Dir(filename), storeName); err != nil {
+			return "", err
+		}
+	}
+
+	sF := &settingsFile{
 		Username: s.Username, VerifySSL: s.Client.VerifySSL,
 		Controller: s.Client.ControllerURL.String(), Token: s.Client.Token, Limit: s.Limit,
 		Workspace: s.Workspace,
 	}
+	// the request policy is only set by hand
+	if old, ok := config.Contexts[s.Context]; ok {
+		sF.RequestTimeout, sF.Retries = old.RequestTimeout, old.Retries
+	}
+	if config.CredentialStore != "" {
+		store, err := NewCredentialStore(config.CredentialStore, filepath.Dir(filename))
+		if err != nil {
+			return "", err
+		}
+		if err := store.Store(&Credentials{ServerURL: credentialKey(sF), Username: sF.Username, Secret: sF.Token}); err != nil {
+			return "", err
+		}
+		sF.Token = ""
+	}
+	config.Contexts[s.Context] = sF
+	s.CredentialStore = config.CredentialStore
+	if config.CurrentContext == "" {
+		config.CurrentContext = s.Context
+	}
 
-	settingsContents, err := json.Marshal(settings)
+	return filename, writeConfigFile(filename, config)
+}
+
+// ListContexts returns the contexts of a settings file ordered by name.
+func ListContexts(cf string) ([]Context, error) {
+	config, err := readConfigFile(locateSettingsFile(cf))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	contexts := make([]Context, 0, len(names))
+	for _, name := range names {
+		sF := config.Contexts[name]
+		contexts = append(contexts, Context{
+			Name:       name,
+			Current:    name == config.CurrentContext,
+			Username:   sF.Username,
+			Controller: sF.Controller,
+			VerifySSL:  sF.VerifySSL,
+			Workspace:  sF.Workspace,
+		})
+	}
+	return contexts, nil
+}
+
+// UseContext makes name the current context of a settings file.
+func UseContext(cf, name string) error {
 	filename := locateSettingsFile(cf)
+	config, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		return fmt.Errorf("context %s not found in %s", name, filename)
+	}
+	config.CurrentContext = name
+	return writeConfigFile(filename, config)
+}
+
+// RenameContext renames a context of a settings file.
+func RenameContext(cf, oldName, newName string) error {
+	filename := locateSettingsFile(cf)
+	config, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	sF, ok := config.Contexts[oldName]
+	if !ok {
+		return fmt.Errorf("context %s not found in %s", oldName, filename)
+	}
+	if _, ok := config.Contexts[newName]; ok {
+		return fmt.Errorf("context %s already exists in %s", newName, filename)
+	}
+	delete(config.Contexts, oldName)
+	config.Contexts[newName] = sF
+	if config.CurrentContext == oldName {
+		config.CurrentContext = newName
+	}
+	return writeConfigFile(filename, config)
+}
+
+// DeleteContext removes a context from a settings file. The file itself is
+// removed along with its last context.
+func DeleteContext(cf, name string) error {
+	filename := locateSettingsFile(cf)
+	config, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		return fmt.Errorf("context %s not found in %s", name, filename)
+	}
+	if err := eraseCredentials(config, filepath.Dir(filename), name); err != nil {
+		return err
+	}
+	delete(config.Contexts, name)
+	if len(config.Contexts) == 0 {
+		return os.Remove(filename)
+	}
+	if config.CurrentContext == name {
+		config.CurrentContext = ""
+	}
+	return writeConfigFile(filename, config)
+}
+
+// readConfigFile reads a settings file. Files written before contexts were
+// introduced hold a single settings object, which is read as the "default"
+// context. A missing file is reported along with an empty configuration.
+func readConfigFile(filename string) (*configFile, error) {
+	config := &configFile{}
+
+	contents, err := os.ReadFile(filename)
+	if err != nil || len(bytes.TrimSpace(contents)) == 0 {
+		config.Contexts = make(map[string]*settingsFile)
+		return config, err
+	}
+
+	if err := json.Unmarshal(contents, config); err != nil {
+		return nil, err
+	}
+	if config.Contexts == nil {
+		sF := &settingsFile{}
+		if err := json.Unmarshal(contents, sF); err != nil {
+			return nil, err
+		}
+		config.Contexts = map[string]*settingsFile{defaultContext: sF}
+		config.CurrentContext = defaultContext
+	}
+	return config, nil
+}
+
+// migrateCredentials moves the tokens of all contexts from the current
+// credential store of config to the store called name.
+func migrateCredentials(config *configFile, dir, name string) error {
+	store, err := NewCredentialStore(name, dir)
+	if err != nil {
+		return err
+	}
+	var current CredentialStore
+	if config.CredentialStore != "" {
+		if current, err = NewCredentialStore(config.CredentialStore, dir); err != nil {
+			return err
+		}
+	}
+	for _, sF := range config.Contexts {
+		token := sF.Token
+		if token == "" && current != nil {
+			creds, err := current.Get(credentialKey(sF))
+			if err != nil {
+				continue
+			}
+			token = creds.Secret
+		}
+		if token == "" {
+			continue
+		}
+		if err := store.Store(&Credentials{ServerURL: credentialKey(sF), Username: sF.Username, Secret: token}); err != nil {
+			return err
+		}
+		sF.Token = ""
+	}
+	config.CredentialStore = name
+	return nil
+}
+
+// eraseCredentials removes the token of the named context from the credential
+// store unless another context shares it.
+func eraseCredentials(config *configFile, dir, name string) error {
+	if config.CredentialStore == "" {
+		return nil
+	}
+	key := credentialKey(config.Contexts[name])
+	for other, sF := range config.Contexts {
+		if other != name && credentialKey(sF) == key {
+			return nil
+		}
+	}
+	store, err := NewCredentialStore(config.CredentialStore, dir)
+	if err != nil {
+		return err
+	}
+	if err := store.Erase(key); err != nil && !errors.Is(err, ErrCredentialsNotFound) {
+		return err
+	}
+	return nil
+}
+
+func writeConfigFile(filename string, config *configFile) error {
+	contents, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
 
 	if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
-		return "", err
+		return err
 	}
 
-	return filename, os.WriteFile(filename, settingsContents, 0o600)
+	return os.WriteFile(filename, contents, 0o600)
 }
 
 // DryccHome returns the path to the user's settings path.
@@ -115,11 +525,13 @@ func DryccHome() string {
 	return dryccHome
 }
 
-// Delete user's settings file.
+// Delete removes the context in use from the user's settings file, see Load.
+// The file is removed along with its last context.
 func Delete(cf string) error {
 	filename := locateSettingsFile(cf)
 
-	if _, err := os.Stat(filename); err != nil {
+	config, err := readConfigFile(filename)
+	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
@@ -127,5 +539,15 @@ func Delete(cf string) error {
 		return err
 	}
 
-	return os.Remove(filename)
+	name := os.Getenv(ContextEnv)
+	if name == "" {
+		name = config.CurrentContext
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		if len(config.Contexts) == 0 {
+			return os.Remove(filename)
+		}
+		return nil
+	}
+	return DeleteContext(cf, name)
 }
@@ -7,7 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/drycc/workflow-cli/pkg/transport"
 	"github.com/drycc/workflow-cli/version"
 	"github.com/stretchr/testify/assert"
 )
@@ -31,7 +33,8 @@ type comparison struct {
 }
 
 func TestLoadSave(t *testing.T) {
-	t.Parallel()
+	// the tokens are saved to the file keyring
+	t.Setenv(CredentialPassphraseEnv, "s3cret")
 	// Load profile from file and confirm it is correctly parsed.
 	file, err := createTempProfile(sFile)
 	if err != nil {
@@ -182,3 +185,203 @@ func TestNotLoggedIn(t *testing.T) {
 		t.Error("expected configuration error, Got:", err.Error())
 	}
 }
+
+const cFile string = `{"current_context":"staging","contexts":{` +
+	`"staging":{"username":"s","ssl_verify":false,"controller":"http://staging.bar","token":"a","workspace":"dev"},` +
+	`"production":{"username":"p","ssl_verify":true,"controller":"https://production.bar","token":"b","response_limit":20}}}`
+
+func TestLoadContext(t *testing.T) {
+	t.Parallel()
+
+	file, err := createTempProfile(cFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadContext(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", s.Context)
+	assert.Equal(t, "s", s.Username)
+	assert.Equal(t, "http://staging.bar", s.Client.ControllerURL.String())
+	assert.Equal(t, "dev", s.Workspace)
+	assert.Equal(t, DefaultResponseLimit, s.Limit)
+
+	s, err = LoadContext(file, "production")
+	assert.NoError(t, err)
+	assert.Equal(t, "production", s.Context)
+	assert.Equal(t, "b", s.Client.Token)
+	assert.True(t, s.Client.VerifySSL)
+	assert.Equal(t, 20, s.Limit)
+
+	_, err = LoadContext(file, "missing")
+	assert.ErrorContains(t, err, "context missing not found")
+}
+
+func TestLoadLegacyContext(t *testing.T) {
+	// the tokens are saved to the file keyring
+	t.Setenv(CredentialPassphraseEnv, "s3cret")
+
+	file, err := createTempProfile(sFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadContext(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "default", s.Context)
+
+	// Saving a legacy file upgrades it while keeping the context.
+	s.Workspace = "dev"
+	_, err = s.Save(file)
+	assert.NoError(t, err)
+	contexts, err := ListContexts(file)
+	assert.NoError(t, err)
+	assert.Equal(t, []Context{
+		{Name: "default", Current: true, Username: "t", Controller: "http://foo.bar", Workspace: "dev"},
+	}, contexts)
+}
+
+func TestSaveContext(t *testing.T) {
+	// the tokens are saved to the file keyring
+	t.Setenv(CredentialPassphraseEnv, "s3cret")
+
+	file, err := createTempProfile(cFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadContext(file, "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse("http://drycc.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Client.ControllerURL = u
+	s.Context = ""
+	if _, err := s.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "drycc.test", s.Context)
+
+	contexts, err := ListContexts(file)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"drycc.test", "production", "staging"}, contextNames(contexts))
+	assert.True(t, contexts[2].Current)
+}
+
+func TestManageContexts(t *testing.T) {
+	t.Parallel()
+
+	file, err := createTempProfile(cFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, UseContext(file, "production"))
+	s, err := LoadContext(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "production", s.Context)
+	assert.Error(t, UseContext(file, "missing"))
+
+	assert.NoError(t, RenameContext(file, "production", "prod"))
+	s, err = LoadContext(file, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", s.Context)
+	assert.Error(t, RenameContext(file, "prod", "staging"))
+	assert.Error(t, RenameContext(file, "missing", "other"))
+
+	assert.NoError(t, DeleteContext(file, "prod"))
+	_, err = LoadContext(file, "")
+	assert.ErrorContains(t, err, "no current context is set")
+	contexts, err := ListContexts(file)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"staging"}, contextNames(contexts))
+	assert.Error(t, DeleteContext(file, "prod"))
+
+	assert.NoError(t, DeleteContext(file, "staging"))
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("File %s exists, supposed to have been deleted.", file)
+	}
+}
+
+func contextNames(contexts []Context) []string {
+	var names []string
+	for _, context := range contexts {
+		names = append(names, context.Name)
+	}
+	return names
+}
+
+func TestRequestPolicy(t *testing.T) {
+	timeout, retries, err := requestPolicy(&settingsFile{})
+	assert.NoError(t, err)
+	assert.Equal(t, transport.DefaultTimeout, timeout)
+	assert.Equal(t, transport.DefaultRetries, retries)
+
+	five := 5
+	timeout, retries, err = requestPolicy(&settingsFile{RequestTimeout: "30s", Retries: &five})
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+	assert.Equal(t, 5, retries)
+
+	t.Setenv(RequestTimeoutEnv, "1m")
+	t.Setenv(RetriesEnv, "0")
+	timeout, retries, err = requestPolicy(&settingsFile{RequestTimeout: "30s", Retries: &five})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, timeout)
+	assert.Equal(t, 0, retries)
+
+	t.Setenv(RetriesEnv, "many")
+	_, _, err = RequestPolicy()
+	assert.EqualError(t, err, `invalid DRYCC_RETRIES "many", use a number of retries`)
+
+	t.Setenv(RequestTimeoutEnv, "-1s")
+	_, _, err = RequestPolicy()
+	assert.EqualError(t, err, `invalid DRYCC_REQUEST_TIMEOUT "-1s", use a duration such as 30s`)
+}
+
+func TestSaveRequestPolicy(t *testing.T) {
+	// the tokens are saved to the file keyring
+	t.Setenv(CredentialPassphraseEnv, "s3cret")
+	file, err := createTempProfile(`{"current_context":"default","contexts":{"default":{"username":"t","controller":"http://foo.bar","token":"a","request_timeout":"10s","retries":1}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, s.RequestTimeout)
+	assert.Equal(t, 1, s.Retries)
+
+	s.Limit = 50
+	_, err = s.Save(file)
+	assert.NoError(t, err)
+	s, err = Load(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, s.RequestTimeout)
+	assert.Equal(t, 1, s.Retries)
+}
+
+func TestUseCache(t *testing.T) {
+	t.Parallel()
+	file, err := createTempProfile(sFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(file)
+	assert.NoError(t, err)
+	cache, ok := s.Client.HTTPClient.Transport.(*transport.Cache)
+	if !ok {
+		t.Fatalf("unexpected transport %T", s.Client.HTTPClient.Transport)
+	}
+	assert.Equal(t, filepath.Join(filepath.Dir(file), "cache"), filepath.Dir(cache.Dir))
+	assert.Equal(t, s.Client.ControllerURL.Host, cache.Host)
+	assert.False(t, cache.Enabled)
+	s.UseCache()
+	assert.True(t, cache.Enabled)
+
+	// the responses of each workspace are cached apart
+	other := cacheDir(file, &settingsFile{Controller: "http://foo.bar", Username: "t", Workspace: "other"})
+	assert.NotEqual(t, cache.Dir, other)
+}
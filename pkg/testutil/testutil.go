@@ -89,15 +89,20 @@ func AssertBody(t *testing.T, expected any, req *http.Request) {
 
 // StripProgress strips the output from the progress method
 func StripProgress(input string) string {
-	first := strings.Index(input, "\b")
-	// If \b charecter not part of string
-	if first == -1 {
-		return input
+	for {
+		first := strings.Index(input, "\b")
+		// If \b charecter not part of string
+		if first == -1 {
+			return input
+		}
+		last := first
+		for last+1 < len(input) && input[last+1] == '\b' {
+			last++
+		}
+
+		// strip the run of \b and the characters it deletes.
+		input = input[:first-(last-first+1)] + input[last+1:]
 	}
-	last := strings.LastIndex(input, "\b")
-
-	// return string without \b or the characters it deletes.
-	return input[:first-(last-first+1)] + input[last+1:]
 }
 
 // SetHeaders sets standard headers for requests
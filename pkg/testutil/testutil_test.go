@@ -25,6 +25,9 @@ func TestStripProgress(t *testing.T) {
 
 	testInput = "Lorem ipsum dolar sit amet...\b\b\b"
 	assert.Equal(t, StripProgress(testInput), expectedOutput, "output")
+
+	testInput = "Lorem ...\b\b\bipsum dolar...\b\b\bo..\b\b\b sit amet"
+	assert.Equal(t, StripProgress(testInput), expectedOutput, "output")
 }
 
 // TestAssertBody ensures AssertBody correctly marshals into the interface.
@@ -0,0 +1,235 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// DefaultCacheTTL is how long a response cached is fresh, see CacheTTLs.
+const DefaultCacheTTL = 5 * time.Minute
+
+// CacheTTLs are how long the responses of the resources changing more often
+// than others are fresh, by the last element of their path.
+var CacheTTLs = map[string]time.Duration{
+	"pods":   30 * time.Second,
+	"events": 30 * time.Second,
+}
+
+// maxCacheBody is the size of the largest response cached.
+const maxCacheBody = 4 << 20
+
+// NoCache are the path elements of the resources holding secrets, such as
+// the config values or the credentials of a volume filer, never cached.
+var NoCache = []string{"config", "auth", "tokens", "filer"}
+
+// Cache is a http.RoundTripper storing the JSON responses of the GET requests
+// to the controller at Host to files of Dir, when Enabled. The requests are
+// then served from the files while they are fresh, and from stale files when
+// the controller can not be reached. The requests changing a resource remove
+// the responses of the resource and of the lists it is in. The requests to
+// other hosts and to the paths of NoCache are sent as they are.
+type Cache struct {
+	Base http.RoundTripper
+	// Host is the host of the controller.
+	Host string
+	// Dir holds the responses of a controller for a user and a workspace.
+	Dir string
+	// Enabled stores the responses and serves the requests from the cache.
+	Enabled bool
+}
+
+// cacheEntry is a response cached.
+type cacheEntry struct {
+	URL    string      `json:"url"`
+	Path   string      `json:"path"`
+	Stored time.Time   `json:"stored"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// RoundTrip sends req or serves it from the cache.
+func (c *Cache) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := c.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.URL.Host != c.Host {
+		return base.RoundTrip(req)
+	}
+	if req.Method != http.MethodGet {
+		res, err := base.RoundTrip(req)
+		if err == nil && res.StatusCode < 300 && !idempotentRead(req.Method) {
+			c.invalidate(req.URL.Path)
+		}
+		return res, err
+	}
+
+	if !c.Enabled || !cacheable(req.URL.Path) {
+		return base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	var cached *cacheEntry
+	if entry, err := c.load(key); err == nil {
+		if time.Since(entry.Stored) < cacheTTL(entry.Path) {
+			klog.V(TraceRequests).Infof("%s %s served from the cache of %s", req.Method, req.URL, entry.Stored.Format(time.RFC3339))
+			return entry.response(req), nil
+		}
+		cached = entry
+	}
+	res, err := base.RoundTrip(req)
+	if err != nil {
+		if cached != nil && req.Context().Err() == nil {
+			klog.V(TraceRequests).Infof("%s %s: %v, served from the stale cache of %s", req.Method, req.URL, err, cached.Stored.Format(time.RFC3339))
+			return cached.response(req), nil
+		}
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK || !strings.Contains(res.Header.Get("Content-Type"), "json") || res.ContentLength > maxCacheBody {
+		return res, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxCacheBody+1))
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) <= maxCacheBody {
+		entry := cacheEntry{
+			URL: req.URL.String(), Path: req.URL.Path, Stored: time.Now(), Status: res.StatusCode, Header: res.Header, Body: body,
+		}
+		if err := c.store(key, &entry); err != nil {
+			klog.V(TraceRequests).Infof("Failed to cache %s: %v", req.URL, err)
+		}
+	}
+	return res, nil
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// idempotentRead tells whether a method reads a resource without changing it.
+func idempotentRead(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND":
+		return true
+	}
+	return false
+}
+
+// cacheable tells whether the response of a path may be cached, see NoCache.
+func cacheable(path string) bool {
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if slices.Contains(NoCache, part) {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheTTL returns how long the response of a path is fresh.
+func cacheTTL(path string) time.Duration {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if ttl, ok := CacheTTLs[parts[len(parts)-1]]; ok {
+		return ttl
+	}
+	return DefaultCacheTTL
+}
+
+// cacheRoot returns the resource a path is under: an app, such as /v2/apps/foo/
+// for /v2/apps/foo/config/, or else a kind of resource, such as /v2/keys/.
+func cacheRoot(path string) string {
+	parts := strings.SplitAfter(path, "/")
+	n := 3
+	if len(parts) > 3 && parts[2] == "apps/" {
+		n = 4
+	}
+	return strings.Join(parts[:min(n, len(parts))], "")
+}
+
+// cacheKey returns the name of the file of the response of req.
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+func (c *Cache) load(key string) (*cacheEntry, error) {
+	data, err := os.ReadFile(filepath.Join(c.Dir, key))
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// store writes an entry to a temporary file renamed to key, so that the
+// entries read are complete.
+func (c *Cache) store(key string, entry *cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.Dir, ".entry-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(c.Dir, key))
+}
+
+// invalidate removes the responses under the resource of path, and those of
+// the lists path is in.
+func (c *Cache) invalidate(path string) {
+	files, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+	root := cacheRoot(path)
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entry, err := c.load(file.Name())
+		if err != nil || strings.HasPrefix(entry.Path, root) || strings.HasPrefix(path, entry.Path) {
+			os.Remove(filepath.Join(c.Dir, file.Name()))
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"path":"%s","call":%d}`, r.URL.Path, n)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func cacheGet(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	res, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	server, calls := newCacheServer(t)
+	cache := &Cache{Host: server.Listener.Addr().String(), Dir: t.TempDir()}
+	client := &http.Client{Transport: cache}
+
+	// the responses are only stored and served when enabled
+	assert.Equal(t, `{"path":"/v2/apps/","call":1}`, cacheGet(t, client, server.URL+"/v2/apps/"))
+	assert.Equal(t, `{"path":"/v2/apps/","call":2}`, cacheGet(t, client, server.URL+"/v2/apps/"))
+	files, err := os.ReadDir(cache.Dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+	cache.Enabled = true
+	assert.Equal(t, `{"path":"/v2/apps/","call":3}`, cacheGet(t, client, server.URL+"/v2/apps/"))
+	assert.Equal(t, `{"path":"/v2/apps/","call":3}`, cacheGet(t, client, server.URL+"/v2/apps/"))
+	assert.Equal(t, int32(3), calls.Load())
+
+	// a stale response is only served when the controller can not be reached
+	key := cacheKey(httptest.NewRequest("GET", server.URL+"/v2/apps/", nil))
+	entry, err := cache.load(key)
+	assert.NoError(t, err)
+	entry.Stored = time.Now().Add(-time.Hour)
+	assert.NoError(t, cache.store(key, entry))
+	assert.Equal(t, `{"path":"/v2/apps/","call":4}`, cacheGet(t, client, server.URL+"/v2/apps/"))
+	entry.Stored = time.Now().Add(-time.Hour)
+	assert.NoError(t, cache.store(key, entry))
+	server.Close()
+	assert.Equal(t, `{"path":"/v2/apps/","call":3}`, cacheGet(t, client, server.URL+"/v2/apps/"))
+	_, err = client.Get(server.URL + "/v2/keys/")
+	assert.Error(t, err)
+}
+
+func TestCacheSecrets(t *testing.T) {
+	t.Parallel()
+	server, calls := newCacheServer(t)
+	other, _ := newCacheServer(t)
+	cache := &Cache{Host: server.Listener.Addr().String(), Dir: t.TempDir(), Enabled: true}
+	client := &http.Client{Transport: cache}
+
+	// the config and the other hosts, such as a volume filer, are not cached
+	for _, url := range []string{server.URL + "/v2/apps/foo/config/", server.URL + "/v2/apps/foo/volumes/data/filer/", other.URL + "/v2/apps/"} {
+		cacheGet(t, client, url)
+		cacheGet(t, client, url)
+	}
+	assert.Equal(t, int32(4), calls.Load())
+	files, err := os.ReadDir(cache.Dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	t.Parallel()
+	server, _ := newCacheServer(t)
+	cache := &Cache{Host: server.Listener.Addr().String(), Dir: t.TempDir(), Enabled: true}
+	client := &http.Client{Transport: cache}
+	paths := []string{"/v2/apps/", "/v2/apps/foo/releases/", "/v2/apps/foo/pods/", "/v2/apps/bar/releases/", "/v2/keys/"}
+	for _, path := range paths {
+		cacheGet(t, client, server.URL+path)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v2/apps/foo/config/", nil)
+	assert.NoError(t, err)
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	var cached []string
+	for _, path := range paths {
+		key := cacheKey(httptest.NewRequest("GET", server.URL+path, nil))
+		if _, err := os.Stat(filepath.Join(cache.Dir, key)); err == nil {
+			cached = append(cached, path)
+		}
+	}
+	assert.Equal(t, []string{"/v2/apps/bar/releases/", "/v2/keys/"}, cached)
+}
+
+func TestCacheRoot(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "/v2/apps/foo/", cacheRoot("/v2/apps/foo/config/"))
+	assert.Equal(t, "/v2/apps/foo/", cacheRoot("/v2/apps/foo/"))
+	assert.Equal(t, "/v2/apps/", cacheRoot("/v2/apps/"))
+	assert.Equal(t, "/v2/keys/", cacheRoot("/v2/keys/bar/"))
+	assert.Equal(t, 30*time.Second, cacheTTL("/v2/apps/foo/pods/"))
+	assert.Equal(t, DefaultCacheTTL, cacheTTL("/v2/apps/foo/releases/"))
+	assert.False(t, cacheable("/v2/apps/foo/config/"))
+	assert.True(t, cacheable("/v2/apps/foo/releases/"))
+}
@@ -0,0 +1,227 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drycc/workflow-cli/version"
+	"k8s.io/klog/v2"
+)
+
+// harFlushEntries is the number of requests recorded between two writes of
+// the archive, which is written at last by Flush.
+const harFlushEntries = 20
+
+// HAR records the requests traced to a HTTP Archive file. The file is
+// written every harFlushEntries requests and by Flush, replaced at once so
+// that it is never truncated.
+type HAR struct {
+	path string
+
+	mu      sync.Mutex
+	entries []harEntry
+	written int
+	failed  bool
+}
+
+// NewHAR returns a recorder writing to the file at path.
+func NewHAR(path string) *HAR {
+	return &HAR{path: path}
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harTimings only splits the time to the response headers from the rest.
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// add records a request, res is nil when it failed. It does nothing on a nil
+// HAR.
+func (h *HAR) add(start time.Time, elapsed time.Duration, req *http.Request, reqBody []byte, res *http.Response, resBody []byte) {
+	if h == nil {
+		return
+	}
+	total := time.Since(start)
+	entry := harEntry{
+		StartedDateTime: start,
+		Time:            milliseconds(total),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Headers:     harHeaders(req.Header),
+			QueryString: []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    req.ContentLength,
+		},
+		Response: harResponse{
+			Headers: []harNameValue{}, Cookies: []harNameValue{}, HeadersSize: -1, BodySize: -1,
+		},
+		Timings: harTimings{Wait: milliseconds(elapsed), Receive: milliseconds(total - elapsed)},
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+	if reqBody != nil {
+		entry.Request.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: string(RedactBody(reqBody))}
+	}
+	if res != nil {
+		entry.Response.Status = res.StatusCode
+		entry.Response.StatusText = http.StatusText(res.StatusCode)
+		entry.Response.HTTPVersion = res.Proto
+		entry.Response.Headers = harHeaders(res.Header)
+		entry.Response.BodySize = res.ContentLength
+		entry.Response.RedirectURL = res.Header.Get("Location")
+		entry.Response.Content = harContent{
+			Size: res.ContentLength, MimeType: res.Header.Get("Content-Type"), Text: string(RedactBody(resBody)),
+		}
+		if resBody != nil && res.ContentLength < 0 {
+			entry.Response.Content.Size = int64(len(resBody))
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+	if len(h.entries)-h.written >= harFlushEntries {
+		h.save()
+	}
+}
+
+// Flush writes the requests recorded since the last write, the commands
+// call it before they exit. It does nothing on a nil HAR.
+func (h *HAR) Flush() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.written < len(h.entries) {
+		h.save()
+	}
+}
+
+// save writes the archive, warning once when it fails.
+func (h *HAR) save() {
+	if err := h.write(); err != nil && !h.failed {
+		h.failed = true
+		klog.Warningf("Failed to write %s: %v", h.path, err)
+	}
+}
+
+// write writes the archive to a temporary file renamed to the path.
+func (h *HAR) write() error {
+	var log harLog
+	log.Log.Version = "1.2"
+	log.Log.Creator = harCreator{Name: "drycc", Version: version.Version}
+	log.Log.Entries = h.entries
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(h.path), "."+filepath.Base(h.path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), h.path); err != nil {
+		return err
+	}
+	h.written = len(h.entries)
+	return nil
+}
+
+// harHeaders returns the redacted headers sorted by name.
+func harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			headers = append(headers, harNameValue{Name: name, Value: RedactHeader(name, value)})
+		}
+	}
+	slices.SortStableFunc(headers, func(a, b harNameValue) int { return strings.Compare(a.Name, b.Name) })
+	return headers
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
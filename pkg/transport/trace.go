@@ -0,0 +1,186 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// The levels of --v tracing the requests, as kubectl does.
+const (
+	// TraceRequests logs the method, URL, status and latency of the requests.
+	TraceRequests klog.Level = 6
+	// TraceHeaders also logs the headers.
+	TraceHeaders klog.Level = 7
+	// TraceBodies also logs the textual bodies.
+	TraceBodies klog.Level = 8
+)
+
+// maxTraceBody is the number of bytes of a body traced.
+const maxTraceBody = 64 << 10
+
+// Redacted replaces the secrets of the headers and the bodies traced.
+const Redacted = "REDACTED"
+
+// Trace is a http.RoundTripper logging the requests with klog, from the
+// level TraceRequests, and recording them to HAR when set. The secrets are
+// redacted, see RedactHeader and RedactBody.
+type Trace struct {
+	Base http.RoundTripper
+	HAR  *HAR
+}
+
+// RoundTrip sends req and traces it.
+func (t *Trace) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !klog.V(TraceRequests).Enabled() && t.HAR == nil {
+		return base.RoundTrip(req)
+	}
+	bodies := klog.V(TraceBodies).Enabled() || t.HAR != nil
+	var reqBody []byte
+	if bodies && req.GetBody != nil && textual(req.Header.Get("Content-Type")) {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(io.LimitReader(body, maxTraceBody))
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	res, err := base.RoundTrip(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		klog.V(TraceRequests).Infof("%s %s failed in %d milliseconds: %v", req.Method, req.URL, elapsed.Milliseconds(), err)
+		t.HAR.add(start, elapsed, req, reqBody, nil, nil)
+		return nil, err
+	}
+	klog.V(TraceRequests).Infof("%s %s %s in %d milliseconds", req.Method, req.URL, res.Status, elapsed.Milliseconds())
+	if klog.V(TraceHeaders).Enabled() {
+		klog.Infof("Request Headers:\n%s", formatHeaders(req.Header))
+		klog.Infof("Response Headers:\n%s", formatHeaders(res.Header))
+	}
+	if len(reqBody) > 0 {
+		klog.V(TraceBodies).Infof("Request Body: %s", RedactBody(reqBody))
+	}
+	if !bodies || !textual(res.Header.Get("Content-Type")) {
+		t.HAR.add(start, elapsed, req, reqBody, res, nil)
+		return res, nil
+	}
+	res.Body = &traceBody{ReadCloser: res.Body, done: func(body []byte) {
+		if len(body) > 0 {
+			klog.V(TraceBodies).Infof("Response Body: %s", RedactBody(body))
+		}
+		t.HAR.add(start, elapsed, req, reqBody, res, body)
+	}}
+	return res, nil
+}
+
+// traceBody keeps the first bytes of a response body read, calling done
+// with them at the end of the body or when it is closed.
+type traceBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+func (b *traceBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := maxTraceBody - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	if err != nil {
+		b.once.Do(func() { b.done(b.buf.Bytes()) })
+	}
+	return n, err
+}
+
+func (b *traceBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+	return err
+}
+
+// textual tells whether a body of contentType can be traced.
+func textual(contentType string) bool {
+	contentType, _, _ = strings.Cut(contentType, ";")
+	contentType = strings.TrimSpace(contentType)
+	return strings.HasPrefix(contentType, "text/") || strings.HasSuffix(contentType, "json") || strings.HasSuffix(contentType, "xml")
+}
+
+// formatHeaders returns the redacted headers, one per line sorted by name.
+func formatHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var b strings.Builder
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(&b, "    %s: %s\n", name, RedactHeader(name, value))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// RedactHeader returns the value of a header with its credentials redacted,
+// keeping the authentication scheme.
+func RedactHeader(name, value string) string {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Proxy-Authorization":
+		if scheme, _, ok := strings.Cut(value, " "); ok {
+			return scheme + " " + Redacted
+		}
+		return Redacted
+	case "Cookie", "Set-Cookie":
+		return Redacted
+	}
+	return value
+}
+
+// secretKeys are the keys of the JSON objects whose values are redacted: the
+// values of the config, the passwords, the tokens and the private keys.
+var secretKeys = []string{"value", "password", "token", "secret", "key", "private_key"}
+
+// RedactBody returns a JSON body with the values of secret keys redacted, see
+// secretKeys. Other bodies are returned as is.
+func RedactBody(body []byte) []byte {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	redacted, err := json.Marshal(redactJSON(v))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if slices.Contains(secretKeys, strings.ToLower(key)) && value != nil {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactJSON(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactJSON(value)
+		}
+	}
+	return v
+}
@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/klog/v2"
+)
+
+func TestRedactHeader(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "token REDACTED", RedactHeader("Authorization", "token abc"))
+	assert.Equal(t, "Basic REDACTED", RedactHeader("authorization", "Basic dXNlcjpwYXNz"))
+	assert.Equal(t, "REDACTED", RedactHeader("Authorization", "abc"))
+	assert.Equal(t, "REDACTED", RedactHeader("Cookie", "session=abc"))
+	assert.Equal(t, "application/json", RedactHeader("Content-Type", "application/json"))
+}
+
+func TestRedactBody(t *testing.T) {
+	t.Parallel()
+	body := `{"values":[{"name":"DATABASE_URL","value":"postgres://u:p@db"},{"name":"EMPTY","value":null}],"password":"p","token":"t","app":"foo"}`
+	expected := `{"app":"foo","password":"REDACTED","token":"REDACTED","values":[{"name":"DATABASE_URL","value":"REDACTED"},{"name":"EMPTY","value":null}]}`
+	assert.Equal(t, expected, string(RedactBody([]byte(body))))
+	assert.Equal(t, "not json", string(RedactBody([]byte("not json"))))
+}
+
+func TestTrace(t *testing.T) {
+	var logs bytes.Buffer
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	fs.Set("logtostderr", "false")
+	fs.Set("v", "8")
+	klog.SetOutput(&logs)
+	defer func() {
+		fs.Set("logtostderr", "true")
+		fs.Set("v", "0")
+		klog.SetOutput(os.Stderr)
+	}()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"values":[{"name":"SECRET","value":"s3cr3t"}]}`))
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "trace.har")
+	har := NewHAR(path)
+	client := &http.Client{Transport: &Trace{HAR: har}}
+
+	req, err := http.NewRequest("POST", server.URL+"/v2/apps/foo/config/", strings.NewReader(`{"values":[{"name":"SECRET","value":"s3cr3t"}]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "token abc")
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, `{"values":[{"name":"SECRET","value":"s3cr3t"}]}`, string(body))
+	klog.Flush()
+
+	out := logs.String()
+	assert.Contains(t, out, "POST "+server.URL+"/v2/apps/foo/config/ 201 Created in ")
+	assert.Contains(t, out, "Authorization: token REDACTED")
+	assert.Contains(t, out, `Request Body: {"values":[{"name":"SECRET","value":"REDACTED"}]}`)
+	assert.Contains(t, out, `Response Body: {"values":[{"name":"SECRET","value":"REDACTED"}]}`)
+	assert.NotContains(t, out, "s3cr3t")
+	assert.NotContains(t, out, "abc")
+
+	// the archive is written once flushed, replaced by a temporary file
+	assert.NoFileExists(t, path)
+	har.Flush()
+	files, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(files))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+	var archive harLog
+	assert.NoError(t, json.Unmarshal(data, &archive))
+	assert.Equal(t, "1.2", archive.Log.Version)
+	assert.Equal(t, 1, len(archive.Log.Entries))
+	entry := archive.Log.Entries[0]
+	assert.Equal(t, "POST", entry.Request.Method)
+	assert.Equal(t, `{"values":[{"name":"SECRET","value":"REDACTED"}]}`, entry.Request.PostData.Text)
+	assert.Equal(t, http.StatusCreated, entry.Response.Status)
+	assert.Equal(t, `{"values":[{"name":"SECRET","value":"REDACTED"}]}`, entry.Response.Content.Text)
+	assert.Contains(t, entry.Request.Headers, harNameValue{Name: "Authorization", Value: "token REDACTED"})
+}
+
+func TestHARFlushEntries(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "trace.har")
+	har := NewHAR(path)
+	req := httptest.NewRequest("GET", "http://foo.bar/v2/apps/", nil)
+	for range harFlushEntries - 1 {
+		har.add(time.Now(), 0, req, nil, nil, nil)
+	}
+	assert.NoFileExists(t, path)
+	har.add(time.Now(), 0, req, nil, nil, nil)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var archive harLog
+	assert.NoError(t, json.Unmarshal(data, &archive))
+	assert.Equal(t, harFlushEntries, len(archive.Log.Entries))
+}
@@ -0,0 +1,197 @@
+// Package transport retries the requests to the controller failing with
+// transient errors, with an exponential backoff and a timeout per attempt.
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetries is the number of times a request is retried.
+	DefaultRetries = 3
+	// DefaultTimeout is how long an attempt waits for a response.
+	DefaultTimeout = 2 * time.Minute
+)
+
+// DefaultBackoff is the backoff between the attempts of a request.
+var DefaultBackoff = Backoff{Min: 500 * time.Millisecond, Max: 30 * time.Second}
+
+// Backoff computes the delays between attempts, doubling from Min up to Max.
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Delay returns the delay before the attempt following attempt, counted
+// from 0. A random jitter of up to half the delay spreads the attempts of
+// several clients.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Min
+	for i := 0; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, b.Max)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Wait sleeps for the delay of attempt, it returns the error of ctx when
+// ctx is done first.
+func (b Backoff) Wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(b.Delay(attempt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Transport is a http.RoundTripper retrying the idempotent requests failing
+// with a network error, a timeout or a 429, 502, 503 or 504 status.
+type Transport struct {
+	Base http.RoundTripper
+	// Timeout is how long an attempt waits for the response headers, the
+	// body can be read for longer. 0 disables it.
+	Timeout time.Duration
+	// Retries is the number of times a request is retried.
+	Retries int
+	Backoff Backoff
+	// Out receives a line for each attempt retried, when set.
+	Out io.Writer
+}
+
+// ErrTimeout is returned, wrapped, by the attempts timing out.
+var ErrTimeout = errors.New("request timed out")
+
+// RoundTrip sends req, retrying it when it is idempotent and fails with a
+// transient error. A request with a body is only retried when it can be
+// sent again, see http.Request.GetBody.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := t.Retries
+	if !idempotent(req) || req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		retries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		res, err := t.roundTrip(req)
+		reason := retryReason(res, err)
+		if reason == "" || attempt >= retries || req.Context().Err() != nil {
+			return res, err
+		}
+
+		wait := t.Backoff.Delay(attempt)
+		if after := retryAfter(res); after > 0 {
+			wait = min(after, max(t.Backoff.Max, wait))
+		}
+		if res != nil {
+			io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+			res.Body.Close()
+		}
+		if t.Out != nil {
+			fmt.Fprintf(t.Out, "%s %s: %s, retrying in %s (%d/%d)\n",
+				req.Method, req.URL.Path, reason, wait.Round(100*time.Millisecond), attempt+1, retries)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTrip sends one attempt of req, cancelled when no response comes in
+// time.
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Timeout <= 0 {
+		return base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(t.Timeout, func() {
+		cancel(fmt.Errorf("%w after %s", ErrTimeout, t.Timeout))
+	})
+	res, err := base.RoundTrip(req.WithContext(ctx))
+	if !timer.Stop() && err != nil {
+		err = context.Cause(ctx)
+	}
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: func() { cancel(nil) }}
+	return res, nil
+}
+
+// cancelBody releases the context of a response when its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, "PROPFIND":
+		return true
+	}
+	return false
+}
+
+// retryReason describes why an attempt can be retried, it is empty when the
+// attempt succeeded or failed for good.
+func retryReason(res *http.Response, err error) string {
+	if err != nil {
+		if errors.Is(err, context.Canceled) && !errors.Is(err, ErrTimeout) {
+			return ""
+		}
+		return err.Error()
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+	return ""
+}
+
+// retryAfter returns the delay asked by the Retry-After header of res.
+func retryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+	value := res.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testBackoff = Backoff{Min: time.Millisecond, Max: 4 * time.Millisecond}
+
+func TestBackoffDelay(t *testing.T) {
+	t.Parallel()
+	b := Backoff{Min: 100 * time.Millisecond, Max: time.Second}
+	for attempt, expected := range []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second,
+	} {
+		delay := b.Delay(attempt)
+		assert.True(t, delay >= expected/2 && delay <= expected, "attempt %d: %s", attempt, delay)
+	}
+	assert.Equal(t, time.Duration(0), Backoff{}.Delay(3))
+}
+
+func newTestServer(t *testing.T, codes ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		body, _ := io.ReadAll(r.Body)
+		if n <= len(codes) {
+			w.WriteHeader(codes[n-1])
+			return
+		}
+		w.Write(append([]byte("ok"), body...))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestTransportRetry(t *testing.T) {
+	t.Parallel()
+	server, calls := newTestServer(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	var out bytes.Buffer
+	client := &http.Client{Transport: &Transport{Retries: 3, Backoff: testBackoff, Out: &out}}
+
+	res, err := client.Get(server.URL + "/v2/apps/")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(3), calls.Load())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], "GET /v2/apps/: 503 Service Unavailable, retrying in "), lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], " (2/3)"), lines[1])
+}
+
+func TestTransportRetryBody(t *testing.T) {
+	t.Parallel()
+	server, calls := newTestServer(t, http.StatusServiceUnavailable)
+	client := &http.Client{Transport: &Transport{Retries: 3, Backoff: testBackoff}}
+
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(" again"))
+	assert.NoError(t, err)
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "ok again", string(body))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestTransportRetryExhausted(t *testing.T) {
+	t.Parallel()
+	server, calls := newTestServer(t, 503, 503, 503, 503)
+	client := &http.Client{Transport: &Transport{Retries: 2, Backoff: testBackoff}}
+
+	res, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTransportNotIdempotent(t *testing.T) {
+	t.Parallel()
+	server, calls := newTestServer(t, http.StatusServiceUnavailable)
+	client := &http.Client{Transport: &Transport{Retries: 3, Backoff: testBackoff}}
+
+	res, err := client.Post(server.URL, "text/plain", strings.NewReader("body"))
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTransportNotTransient(t *testing.T) {
+	t.Parallel()
+	server, calls := newTestServer(t, http.StatusNotFound)
+	client := &http.Client{Transport: &Transport{Retries: 3, Backoff: testBackoff}}
+
+	res, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTransportRetryAfter(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+	var out bytes.Buffer
+	client := &http.Client{Transport: &Transport{Retries: 1, Backoff: Backoff{Min: time.Millisecond, Max: 2 * time.Second}, Out: &out}}
+
+	start := time.Now()
+	res, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.True(t, time.Since(start) >= time.Second, "retried before Retry-After")
+	assert.True(t, strings.Contains(out.String(), "429 Too Many Requests, retrying in 1s (1/1)"), out.String())
+}
+
+func TestTransportTimeout(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &Transport{Timeout: 50 * time.Millisecond, Retries: 1, Backoff: testBackoff}}
+
+	res, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(2), calls.Load())
+
+	calls.Store(0)
+	client.Transport.(*Transport).Retries = 0
+	_, err = client.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrTimeout), "%v", err)
+}
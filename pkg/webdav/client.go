@@ -0,0 +1,286 @@
+// Package webdav is a WebDAV client for the file access service of volumes.
+package webdav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileInfo describes a file or a directory of a WebDAV server.
+type FileInfo struct {
+	// Path is the slash separated path of the file, relative to the endpoint.
+	Path    string
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
+}
+
+// Name returns the last element of the path.
+func (f FileInfo) Name() string {
+	return path.Base(f.Path)
+}
+
+// StatusError is returned for the requests failing with an unexpected status.
+type StatusError struct {
+	Method string
+	Path   string
+	Code   int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.Code, http.StatusText(e.Code))
+}
+
+// Unwrap returns fs.ErrNotExist for 404 and fs.ErrPermission for 401 and 403,
+// so that the errors can be checked with errors.Is.
+func (e *StatusError) Unwrap() error {
+	switch e.Code {
+	case http.StatusNotFound:
+		return fs.ErrNotExist
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fs.ErrPermission
+	}
+	return nil
+}
+
+// Client sends requests to a WebDAV endpoint with basic authentication.
+type Client struct {
+	Endpoint   *url.URL
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client of the endpoint, an URL of a directory.
+func NewClient(endpoint, username, password string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &Client{Endpoint: u, Username: username, Password: password, HTTPClient: http.DefaultClient}, nil
+}
+
+// Stat returns the description of a file or a directory.
+func (c *Client) Stat(name string) (FileInfo, error) {
+	files, err := c.propfind(name, "0")
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if len(files) == 0 {
+		return FileInfo{}, &StatusError{Method: "PROPFIND", Path: clean(name), Code: http.StatusNotFound}
+	}
+	return files[0], nil
+}
+
+// ReadDir returns the files of a directory sorted by name.
+func (c *Client) ReadDir(name string) ([]FileInfo, error) {
+	dir := clean(name)
+	files, err := c.propfind(name, "1")
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		if file.Path != dir {
+			entries = append(entries, file)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// Walk calls fn for a file or for a directory and all the files in it,
+// parents before their children and sorted by name.
+func (c *Client) Walk(name string, fn func(FileInfo) error) error {
+	info, err := c.Stat(name)
+	if err != nil {
+		return err
+	}
+	return c.walk(info, fn)
+}
+
+func (c *Client) walk(info FileInfo, fn func(FileInfo) error) error {
+	if err := fn(info); err != nil || !info.IsDir {
+		return err
+	}
+	entries, err := c.ReadDir(info.Path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := c.walk(entry, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open returns the content of a file, which must be closed.
+func (c *Client) Open(name string) (io.ReadCloser, error) {
+	res, err := c.do("GET", name, nil, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Write creates or replaces a file with size bytes read from r.
+func (c *Client) Write(name string, r io.Reader, size int64) error {
+	if size == 0 {
+		r = http.NoBody
+	}
+	res, err := c.do("PUT", name, r, func(req *http.Request) { req.ContentLength = size },
+		http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// Remove removes a file or a directory with all its files.
+func (c *Client) Remove(name string) error {
+	res, err := c.do("DELETE", name, nil, nil, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// Mkdir creates a directory, whose parent must exist.
+func (c *Client) Mkdir(name string) error {
+	res, err := c.do("MKCOL", name, nil, nil, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// MkdirAll creates a directory and its missing parents.
+func (c *Client) MkdirAll(name string) error {
+	dir := clean(name)
+	if dir == "/" {
+		return nil
+	}
+	info, err := c.Stat(dir)
+	if err == nil {
+		if !info.IsDir {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := c.MkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+	return c.Mkdir(dir)
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:resourcetype/><D:getcontentlength/><D:getlastmodified/></D:prop></D:propfind>`
+
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Propstats []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+				ContentLength string `xml:"DAV: getcontentlength"`
+				LastModified  string `xml:"DAV: getlastmodified"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+func (c *Client) propfind(name, depth string) ([]FileInfo, error) {
+	res, err := c.do("PROPFIND", name, strings.NewReader(propfindBody), func(req *http.Request) {
+		req.Header.Set("Depth", depth)
+		req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	}, http.StatusMultiStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var status multistatus
+	if err := xml.NewDecoder(res.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("PROPFIND %s: %w", clean(name), err)
+	}
+	files := make([]FileInfo, 0, len(status.Responses))
+	for _, response := range status.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			return nil, err
+		}
+		file := FileInfo{Path: c.relative(href.Path)}
+		for _, propstat := range response.Propstats {
+			if !strings.Contains(propstat.Status, " 200 ") {
+				continue
+			}
+			prop := propstat.Prop
+			file.IsDir = file.IsDir || prop.ResourceType.Collection != nil
+			if prop.ContentLength != "" {
+				file.Size, _ = strconv.ParseInt(prop.ContentLength, 10, 64)
+			}
+			if prop.LastModified != "" {
+				file.ModTime, _ = http.ParseTime(prop.LastModified)
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// relative returns the path of an href relative to the endpoint.
+func (c *Client) relative(href string) string {
+	return clean(strings.TrimPrefix(href, strings.TrimSuffix(c.Endpoint.Path, "/")))
+}
+
+func (c *Client) do(method, name string, body io.Reader, prepare func(*http.Request), codes ...int) (*http.Response, error) {
+	u := c.Endpoint.JoinPath(clean(name))
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	if prepare != nil {
+		prepare(req)
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if res.StatusCode == code {
+			return res, nil
+		}
+	}
+	// drain the body so that the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	res.Body.Close()
+	return nil, &StatusError{Method: method, Path: clean(name), Code: res.StatusCode}
+}
+
+// clean returns the absolute slash separated form of a path.
+func clean(name string) string {
+	return path.Clean("/" + name)
+}
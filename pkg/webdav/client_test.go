@@ -0,0 +1,86 @@
+package webdav
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/webdav"
+)
+
+func newTestClient(t *testing.T) *Client {
+	handler := &webdav.Handler{Prefix: "/dav", FileSystem: webdav.NewMemFS(), LockSystem: webdav.NewMemLS()}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL+"/dav", "user", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+
+	assert.NoError(t, client.MkdirAll("/a/b"))
+	assert.NoError(t, client.MkdirAll("a/b"))
+	assert.NoError(t, client.Write("/a/hello world.txt", strings.NewReader("hello"), 5))
+	assert.NoError(t, client.Write("/a/b/empty", strings.NewReader(""), 0))
+	assert.EqualError(t, client.MkdirAll("/a/hello world.txt"), "/a/hello world.txt is not a directory")
+
+	info, err := client.Stat("/a/hello world.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "/a/hello world.txt", info.Path)
+	assert.Equal(t, "hello world.txt", info.Name())
+	assert.Equal(t, int64(5), info.Size)
+	assert.False(t, info.IsDir)
+	assert.False(t, info.ModTime.IsZero())
+
+	entries, err := client.ReadDir("/a")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "/a/b", entries[0].Path)
+	assert.True(t, entries[0].IsDir)
+	assert.Equal(t, "/a/hello world.txt", entries[1].Path)
+
+	var paths []string
+	assert.NoError(t, client.Walk("/", func(file FileInfo) error {
+		paths = append(paths, file.Path)
+		return nil
+	}))
+	assert.Equal(t, []string{"/", "/a", "/a/b", "/a/b/empty", "/a/hello world.txt"}, paths)
+
+	body, err := client.Open("/a/hello world.txt")
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "hello", string(data))
+
+	assert.NoError(t, client.Remove("/a"))
+	_, err = client.Stat("/a/b")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = client.Open("/a/hello world.txt")
+	assert.EqualError(t, err, "GET /a/hello world.txt: 404 Not Found")
+	assert.Error(t, client.Mkdir("/x/y"))
+}
+
+func TestClientUnauthorized(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(t)
+	client.Password = "wrong"
+
+	_, err := client.Stat("/")
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+}